@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/option"
+	"github.com/arxdsilva/bravo/internal/service"
+	"github.com/arxdsilva/bravo/internal/storage/postgres"
+)
+
+var errKeysUsage = errors.New(`usage: bravo keys <create|list|rotate|revoke> [flags]
+  create -name <name> -scopes <scope,scope>
+  list
+  rotate -id <key id>
+  revoke -id <key id>`)
+
+// keys manages api keys from the command line, it is how the
+// first admin key is created when authentication is enabled
+func keys(ctx context.Context, cfg *option.Config, args []string) error {
+	if len(args) == 0 {
+		return errKeysUsage
+	}
+	fs := flag.NewFlagSet("keys "+args[0], flag.ContinueOnError)
+	name := fs.String("name", "", "name of the key")
+	scopes := fs.String("scopes", "", fmt.Sprintf("comma separated scopes %v", core.Scopes))
+	id := fs.String("id", "", "id of the key")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	db, err := postgres.New(ctx, cfg.DB)
+	if err != nil {
+		return fmt.Errorf(`could not connect to db %w`, err)
+	}
+	if err = migrate(ctx, cfg.DB); err != nil {
+		return fmt.Errorf(`could not migrate db %w`, err)
+	}
+	svc := service.NewService(db, nil, cfg.Service)
+
+	var out interface{}
+	switch args[0] {
+	case "create":
+		sc, err := core.ParseScopes(*scopes)
+		if err != nil {
+			return err
+		}
+		key, secret, err := svc.CreateAPIKey(ctx, *name, sc)
+		if err != nil {
+			return err
+		}
+		out = map[string]interface{}{"key": key, "secret": secret}
+	case "list":
+		out, err = svc.GetAPIKeys(ctx)
+	case "rotate":
+		key, secret, err := svc.RotateAPIKey(ctx, *id)
+		if err != nil {
+			return err
+		}
+		out = map[string]interface{}{"key": key, "secret": secret}
+	case "revoke":
+		err = svc.RevokeAPIKey(ctx, *id)
+		out = map[string]string{"revoked": *id}
+	default:
+		return errKeysUsage
+	}
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
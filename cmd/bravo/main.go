@@ -5,14 +5,24 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/arxdsilva/bravo/internal/clients/exchange"
+	"github.com/arxdsilva/bravo/internal/clients/oidc"
+	"github.com/arxdsilva/bravo/internal/clients/webhook"
+	"github.com/arxdsilva/bravo/internal/grpc"
+	"github.com/arxdsilva/bravo/internal/health"
 	"github.com/arxdsilva/bravo/internal/http"
 	"github.com/arxdsilva/bravo/internal/logger"
 	"github.com/arxdsilva/bravo/internal/option"
+	"github.com/arxdsilva/bravo/internal/outbox"
 	"github.com/arxdsilva/bravo/internal/service"
 	"github.com/arxdsilva/bravo/internal/storage/postgres"
+	"github.com/arxdsilva/bravo/internal/tracing"
 	migration "github.com/gosidekick/migration/v3"
 	"golang.org/x/sync/errgroup"
 
@@ -37,6 +47,9 @@ func run() error {
 	if err != nil {
 		return err
 	}
+	if len(os.Args) > 1 && os.Args[1] == "keys" {
+		return keys(ctx, cfg, os.Args[2:])
+	}
 	return startup(ctx, cfg)
 }
 
@@ -55,9 +68,15 @@ func startup(ctx context.Context, cfg *option.Config) error {
 
 	lg.Info("starting the service")
 
-	// maybe add some tracer
-
-	excg := exchange.New(cfg.Exchange)
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf(`could not setup tracing %w`, err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			lg.WithError(err).Error("tracing shutdown")
+		}
+	}()
 
 	db, err := postgres.New(ctx, cfg.DB)
 	if err != nil {
@@ -69,11 +88,36 @@ func startup(ctx context.Context, cfg *option.Config) error {
 		return fmt.Errorf(`could not migrate db %w`, err)
 	}
 
-	svc := service.NewService(db, excg)
+	excg := exchange.New(cfg.Exchange, db)
+
+	svc := service.NewService(db, excg, cfg.Service)
 
 	go svc.Seed(ctx)
+	go purgeIdempotencyKeys(ctx, svc, idempotencyPurgeInterval)
+	go svc.RunScheduledRates(ctx)
+	go svc.RunAlerts(ctx, webhook.New(cfg.Webhook))
 
-	srv := http.NewServer(svc, cfg.HTTP)
+	sink, err := outbox.New(cfg.Outbox)
+	if err != nil {
+		return fmt.Errorf(`could not setup the outbox sink %w`, err)
+	}
+	if sink != nil {
+		go svc.RelayOutbox(ctx, sink, cfg.Outbox.Sink)
+	}
+
+	var verifier http.TokenVerifier
+	if cfg.OIDC.Enabled() {
+		if verifier, err = oidc.New(cfg.OIDC); err != nil {
+			return fmt.Errorf(`could not setup oidc %w`, err)
+		}
+	}
+
+	checker, err := probes(cfg, db, excg, svc)
+	if err != nil {
+		return fmt.Errorf(`could not setup health probes %w`, err)
+	}
+
+	srv := http.NewServer(svc, verifier, checker, cfg.HTTP)
 
 	// run seed svc
 
@@ -81,6 +125,12 @@ func startup(ctx context.Context, cfg *option.Config) error {
 	errg.Go(func() error {
 		return srv.Run(ctx)
 	})
+	if cfg.GRPC.Port > 0 {
+		grpcSrv := grpc.NewServer(svc, verifier, cfg.GRPC)
+		errg.Go(func() error {
+			return grpcSrv.Run(ctx)
+		})
+	}
 
 	lg.Info("service started")
 
@@ -116,3 +166,96 @@ func migrate(ctx context.Context, cfg postgres.Config) error {
 	log.WithFields(log.Fields{"number": n, "executed": executed}).Info("migration")
 	return nil
 }
+
+// idempotencyPurgeInterval is how often expired idempotency keys are removed
+const idempotencyPurgeInterval = time.Hour
+
+func purgeIdempotencyKeys(ctx context.Context, svc service.Service, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			n, err := svc.PurgeIdempotentRequests(ctx)
+			if err != nil {
+				log.WithError(err).Error("svc.PurgeIdempotentRequests")
+				continue
+			}
+			log.WithField("purged", n).Info("idempotency keys purged")
+		}
+	}
+}
+
+// migrationsDir holds the migrations applied on startup
+const migrationsDir = "migrations"
+
+// probes registers the dependencies checked by the readiness endpoint
+func probes(cfg *option.Config, db postgres.DB, excg exchange.Exchange, svc service.Service) (*health.Checker, error) {
+	expected, err := latestMigration(migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+	checker := health.NewChecker()
+	checker.Register(health.Probe{
+		Name:     "postgres",
+		Critical: true,
+		Timeout:  cfg.Health.Timeout,
+		Check:    db.Ping,
+	})
+	checker.Register(health.Probe{
+		Name:     "migrations",
+		Critical: true,
+		Timeout:  cfg.Health.Timeout,
+		Check: func(ctx context.Context) error {
+			version, err := db.MigrationVersion(ctx)
+			if err != nil {
+				return err
+			}
+			if version < expected {
+				return fmt.Errorf("database is at version %v, expected %v", version, expected)
+			}
+			return nil
+		},
+	})
+	checker.Register(health.Probe{
+		Name:     "provider",
+		Critical: cfg.Health.ProviderCritical,
+		Timeout:  cfg.Health.Timeout,
+		Check:    excg.Ping,
+	})
+	checker.Register(health.Probe{
+		Name:     "seed",
+		Critical: cfg.Health.SeedCritical,
+		Timeout:  time.Second,
+		Check: func(ctx context.Context) error {
+			if !svc.Seeded() {
+				return fmt.Errorf("currencies were not seeded yet")
+			}
+			return nil
+		},
+	})
+	return checker, nil
+}
+
+// latestMigration is the highest version in dir, migration files
+// are named NNN_description.up.sql
+func latestMigration(dir string) (int64, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return 0, err
+	}
+	var latest int64
+	for _, f := range files {
+		prefix, _, _ := strings.Cut(filepath.Base(f), "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration file %v: %w", f, err)
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,194 @@
+package exchange
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/metrics"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// provider identifies the exchange provider in the budget store
+const provider = "exchangerate.host"
+
+// BudgetStore persists the calls made to a provider per month so
+// the budget is shared between instances and restarts
+type BudgetStore interface {
+	IncrementProviderCalls(ctx context.Context, provider string, period time.Time) (int64, error)
+	GetProviderCalls(ctx context.Context, provider string, period time.Time) (int64, error)
+}
+
+// budget throttles the calls per second and enforces the monthly budget
+type budget struct {
+	store   BudgetStore
+	limit   int64
+	limiter *rate.Limiter
+
+	mu     sync.Mutex
+	period time.Time
+	used   int64
+	loaded bool
+}
+
+func newBudget(store BudgetStore, cfg Config) *budget {
+	limiter := rate.NewLimiter(rate.Inf, 0)
+	if cfg.CallsPerSecond > 0 {
+		limiter = rate.NewLimiter(rate.Limit(cfg.CallsPerSecond), 1)
+	}
+	return &budget{
+		store:   store,
+		limit:   cfg.MonthlyBudget,
+		limiter: limiter,
+	}
+}
+
+// take waits for the per second throttle and books a call in the
+// monthly budget, it returns false when the budget is exhausted. The
+// store is called without the lock, so a slow database does not
+// serialize the provider calls
+func (b *budget) take(ctx context.Context) (bool, error) {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return false, err
+	}
+	if b.store == nil {
+		return true, nil
+	}
+
+	period := core.UsagePeriod(time.Now())
+	used, err := b.load(ctx, period)
+	if err != nil {
+		return false, err
+	}
+	if b.limit > 0 && used >= b.limit {
+		return false, nil
+	}
+	used, err = b.store.IncrementProviderCalls(ctx, provider, period)
+	if err != nil {
+		return false, err
+	}
+	b.set(period, used)
+	// other instances may have used the last calls concurrently
+	return b.limit == 0 || used <= b.limit, nil
+}
+
+// load retrieves the calls used in period, counters are read from
+// the store on startup and when the month changes
+func (b *budget) load(ctx context.Context, period time.Time) (int64, error) {
+	b.mu.Lock()
+	loaded, used := b.loaded && period.Equal(b.period), b.used
+	b.mu.Unlock()
+	if loaded {
+		return used, nil
+	}
+	used, err := b.store.GetProviderCalls(ctx, provider, period)
+	if err != nil {
+		return 0, err
+	}
+	return b.set(period, used), nil
+}
+
+// set records the calls used in period and returns the counter, the
+// answers of concurrent calls to the store may arrive out of order so
+// the highest count of the latest period is kept
+func (b *budget) set(period time.Time, used int64) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case !b.loaded || period.After(b.period):
+		b.period, b.used, b.loaded = period, used, true
+	case period.Equal(b.period) && used > b.used:
+		b.used = used
+	}
+	b.observe()
+	return b.used
+}
+
+// observe assumes the lock is held and exports the counters
+func (b *budget) observe() {
+	metrics.ProviderBudgetUsed.WithLabelValues(provider).Set(float64(b.used))
+	if b.limit > 0 {
+		remaining := b.limit - b.used
+		if remaining < 0 {
+			remaining = 0
+		}
+		metrics.ProviderBudgetRemaining.WithLabelValues(provider).Set(float64(remaining))
+	}
+}
+
+func (b *budget) status(ctx context.Context) (core.ProviderBudget, error) {
+	period := core.UsagePeriod(time.Now())
+	pb := core.ProviderBudget{
+		Provider: provider,
+		Period:   period.Format(core.UsagePeriodLayout),
+		Limit:    b.limit,
+	}
+	if b.store == nil {
+		return pb, nil
+	}
+	used, err := b.load(ctx, period)
+	if err != nil {
+		return pb, err
+	}
+	pb.Used = used
+	if b.limit > 0 {
+		pb.Remaining = b.limit - used
+		if pb.Remaining < 0 {
+			pb.Remaining = 0
+		}
+		pb.Exhausted = pb.Remaining == 0
+	}
+	return pb, nil
+}
+
+// staleCache keeps the last provider answers to serve them when
+// the budget is exhausted
+type staleCache struct {
+	mu         sync.RWMutex
+	rates      map[string]staleRate
+	currencies core.Currencies
+}
+
+type staleRate struct {
+	rate float64
+	at   time.Time
+}
+
+func newStaleCache() *staleCache {
+	return &staleCache{rates: map[string]staleRate{}}
+}
+
+func (c *staleCache) setRate(from, to string, rate float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rates[from+"/"+to] = staleRate{rate: rate, at: time.Now()}
+}
+
+func (c *staleCache) rate(from, to string) (staleRate, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	r, ok := c.rates[from+"/"+to]
+	return r, ok
+}
+
+func (c *staleCache) setCurrencies(l core.Currencies) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.currencies = l
+}
+
+func (c *staleCache) getCurrencies() (core.Currencies, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.currencies, c.currencies != nil
+}
+
+// exhausted logs why the provider was not called
+func exhausted(fn string) {
+	log.WithFields(log.Fields{
+		"pkg":      "exchange",
+		"provider": provider,
+	}).Warn("[" + fn + "] monthly budget exhausted, serving cached data")
+}
@@ -0,0 +1,134 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore is a BudgetStore kept in memory, block holds the
+// increments until it is closed
+type fakeStore struct {
+	mu    sync.Mutex
+	calls map[time.Time]int64
+	err   error
+	block chan struct{}
+}
+
+func newFakeStore(used int64) *fakeStore {
+	return &fakeStore{calls: map[time.Time]int64{core.UsagePeriod(time.Now()): used}}
+}
+
+func (s *fakeStore) IncrementProviderCalls(ctx context.Context, provider string, period time.Time) (int64, error) {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	s.calls[period]++
+	return s.calls[period], nil
+}
+
+func (s *fakeStore) GetProviderCalls(ctx context.Context, provider string, period time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[period], s.err
+}
+
+func (s *fakeStore) used() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[core.UsagePeriod(time.Now())]
+}
+
+func Test_budget_take(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		limit    int64
+		used     int64
+		storeErr error
+		want     []bool
+		wantUsed int64
+		wantErr  bool
+	}{
+		{name: "unlimited", used: 100, want: []bool{true, true}, wantUsed: 102},
+		{name: "within budget", limit: 3, want: []bool{true, true, true}, wantUsed: 3},
+		{name: "exhausted", limit: 2, want: []bool{true, true, false, false}, wantUsed: 2},
+		{name: "used before the restart", limit: 5, used: 4, want: []bool{true, false}, wantUsed: 5},
+		{name: "store failure", limit: 5, storeErr: errors.New("db"), want: []bool{false}, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newFakeStore(tt.used)
+			store.err = tt.storeErr
+			b := newBudget(store, Config{MonthlyBudget: tt.limit})
+			for i, want := range tt.want {
+				ok, err := b.take(context.Background())
+				require.Equal(t, tt.wantErr, err != nil)
+				require.Equal(t, want, ok, "call %d", i)
+			}
+			if tt.storeErr == nil {
+				require.Equal(t, tt.wantUsed, store.used())
+			}
+		})
+	}
+}
+
+func Test_budget_takeRace(t *testing.T) {
+	t.Parallel()
+	// another instance used a call after this one loaded the counter
+	store := newFakeStore(0)
+	b := newBudget(store, Config{MonthlyBudget: 2})
+	ok, err := b.take(context.Background())
+	require.NoError(t, err)
+	require.True(t, ok)
+	store.calls[core.UsagePeriod(time.Now())]++
+
+	ok, err = b.take(context.Background())
+	require.NoError(t, err)
+	require.False(t, ok)
+	pb, err := b.status(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(3), pb.Used)
+	require.True(t, pb.Exhausted)
+}
+
+func Test_budget_statusDoesNotWaitForTheStore(t *testing.T) {
+	t.Parallel()
+	store := newFakeStore(1)
+	b := newBudget(store, Config{MonthlyBudget: 10})
+	_, err := b.status(context.Background())
+	require.NoError(t, err)
+
+	store.block = make(chan struct{})
+	taken := make(chan bool)
+	go func() {
+		ok, _ := b.take(context.Background())
+		taken <- ok
+	}()
+
+	done := make(chan core.ProviderBudget)
+	go func() {
+		pb, _ := b.status(context.Background())
+		done <- pb
+	}()
+	select {
+	case pb := <-done:
+		require.Equal(t, int64(1), pb.Used)
+	case <-time.After(time.Second):
+		t.Fatal("status waited for the increment of take")
+	}
+	close(store.block)
+	require.True(t, <-taken)
+}
@@ -1,6 +1,17 @@
 package exchange
 
+import "time"
+
 type Config struct {
 	APIKey     string `envconfig:"APP_LAYER_KEY" default:""`
 	APIBaseURL string `envconfig:"APP_API_BASE_URL" default:"https://api.exchangerate.host"`
+	// CallsPerSecond throttles the calls made to the provider
+	CallsPerSecond float64 `envconfig:"APP_API_CALLS_PER_SECOND" default:"5"`
+	// MonthlyBudget is the amount of calls the plan of APP_LAYER_KEY
+	// allows per month, 0 means unlimited
+	MonthlyBudget int64 `envconfig:"APP_API_MONTHLY_BUDGET" default:"0"`
+	// Retries is how many times a failed call is retried
+	Retries int `envconfig:"APP_API_RETRIES" default:"2"`
+	// RetryBackoff is the wait before the first retry, doubled on each attempt
+	RetryBackoff time.Duration `envconfig:"APP_API_RETRY_BACKOFF" default:"200ms"`
 }
@@ -3,136 +3,282 @@ package exchange
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"sort"
 	"time"
 
 	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/metrics"
+	"github.com/arxdsilva/bravo/internal/tracing"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// exchangeSource identifies data that came from the provider
+const exchangeSource = "exchange"
+
+// staleSource is the conversion source when the budget is exhausted
+// and the last known provider rate is used instead
+const staleSource = "exchange-stale"
+
+// staleCacheName labels the cache of provider answers in the metrics
+const staleCacheName = "provider_stale"
+
 type Exchange struct {
 	APIKey  string
 	BaseURL string
 	client  http.Client
+	budget  *budget
+	cache   *staleCache
+	retries int
+	backoff time.Duration
 }
 
-func New(cfg Config) Exchange {
+// New creates the provider client, store persists the monthly
+// call budget and may be nil when there is no budget to enforce
+func New(cfg Config, store BudgetStore) Exchange {
 	return Exchange{
 		APIKey:  cfg.APIKey,
 		BaseURL: cfg.APIBaseURL,
 		client: http.Client{
 			Timeout: time.Duration(time.Second * 10),
 		},
+		budget:  newBudget(store, cfg),
+		cache:   newStaleCache(),
+		retries: cfg.Retries,
+		backoff: cfg.RetryBackoff,
 	}
 }
 
-// GetCurrencies tries to get all currencies from our currency provider
-//
-// receives a ctx so the request can be cancelled if the original request is also cancelled
-func (e Exchange) GetCurrencies(ctx context.Context) (l map[string]string, err error) {
-	lg := log.WithField("pkg", "exchange")
-	url := fmt.Sprintf("%v/symbols", e.BaseURL)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+// Budget retrieves the monthly call budget of the provider
+func (e Exchange) Budget(ctx context.Context) (core.ProviderBudget, error) {
+	return e.budget.status(ctx)
+}
+
+// Ping checks the provider is reachable, it does not call an
+// endpoint so it is not booked in the budget
+func (e Exchange) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, e.BaseURL, nil)
 	if err != nil {
-		lg.WithError(err).Error("[GetCurrencies] NewRequest")
-		return
+		return err
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("provider responded %v", resp.StatusCode)
+	}
+	return nil
+}
+
+// take books a provider call, returning false when it should
+// not be made because the monthly budget is exhausted. When the
+// budget store fails the call is not booked nor made, the cached
+// data is served as if the budget was exhausted
+func (e Exchange) take(ctx context.Context, fn string) (bool, error) {
+	ok, err := e.budget.take(ctx)
+	if err != nil && ctx.Err() != nil {
+		return false, providerError(ctx.Err())
+	}
+	if err != nil {
+		log.WithField("pkg", "exchange").WithError(err).Error("[" + fn + "] budget.take")
+		return false, nil
+	}
+	if !ok {
+		exhausted(fn)
 	}
+	return ok, nil
+}
+
+// get calls an endpoint of the provider, retrying on network errors
+// and server failures, every attempt is booked in the budget
+func (e Exchange) get(ctx context.Context, fn, endpoint string, query url.Values) ([]byte, error) {
+	lg := log.WithField("pkg", "exchange")
+	backoff := e.backoff
+	var err error
+	for attempt := 0; attempt <= e.retries; attempt++ {
+		if attempt > 0 {
+			metrics.ProviderRetries.WithLabelValues(provider, endpoint).Inc()
+			select {
+			case <-ctx.Done():
+				return nil, providerError(ctx.Err())
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		ok, terr := e.take(ctx, fn)
+		if terr != nil {
+			return nil, terr
+		}
+		if !ok {
+			return nil, core.ErrBudgetExhausted
+		}
+		var b []byte
+		var retry bool
+		b, retry, err = e.do(ctx, endpoint, query)
+		if err == nil {
+			return b, nil
+		}
+		lg.WithError(err).WithField("attempt", attempt).Error("[" + fn + "] " + endpoint)
+		if !retry {
+			break
+		}
+	}
+	return nil, providerError(err)
+}
 
-	req = req.WithContext(ctx)
+// providerError classifies a failed call, so it is reported as an
+// upstream failure instead of an internal one
+func providerError(err error) error {
+	var ne net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &ne) && ne.Timeout()) {
+		return fmt.Errorf("%w: %v", core.ErrProviderTimeout, err)
+	}
+	return fmt.Errorf("%w: %v", core.ErrProviderUnavailable, err)
+}
+
+// do makes a single call, retry reports whether the failure is transient
+func (e Exchange) do(ctx context.Context, endpoint string, query url.Values) (b []byte, retry bool, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "GET /"+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("provider", provider),
+			semconv.HTTPMethodKey.String(http.MethodGet),
+		))
+	start := time.Now()
+	defer func() {
+		metrics.ProviderRequestDuration.WithLabelValues(provider, endpoint).Observe(time.Since(start).Seconds())
+		if err != nil {
+			metrics.ProviderErrors.WithLabelValues(provider, endpoint).Inc()
+		}
+		tracing.End(span, err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%v/%v", e.BaseURL, endpoint), nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.URL.RawQuery = query.Encode()
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 	resp, err := e.client.Do(req)
 	if err != nil {
-		lg.WithError(err).Error("[GetCurrencies] client.Do")
-		return
+		return nil, ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		return nil, true, fmt.Errorf("provider responded %v", resp.StatusCode)
+	}
+	b, err = io.ReadAll(resp.Body)
+	return b, false, err
+}
+
+// GetCurrencies tries to get all currencies from our currency provider
+//
+// receives a ctx so the request can be cancelled if the original request is also cancelled
+func (e Exchange) GetCurrencies(ctx context.Context) (l core.Currencies, err error) {
+	lg := log.WithField("pkg", "exchange")
+	b, err := e.get(ctx, "GetCurrencies", "symbols", nil)
+	if errors.Is(err, core.ErrBudgetExhausted) {
+		return e.cachedCurrencies(nil)
 	}
-	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		lg.WithError(err).Error("[GetCurrencies] ReadAll")
 		return
 	}
 	symbols := &core.SymbolsClientResp{}
 	err = json.Unmarshal(b, symbols)
 	if err != nil {
 		lg.WithError(err).Error("[GetCurrencies] Unmarshal")
-		return
+		return nil, providerError(err)
 	}
 	if !symbols.Success {
 		lg.WithField("success", symbols.Success).Warn("[GetCurrencies] success")
-		return
+		return nil, fmt.Errorf("%w: symbols were not successful", core.ErrProviderUnavailable)
 	}
 
-	l = map[string]string{}
+	found := map[string]core.Currency{}
 	for k, v := range symbols.Symbols {
-		l[k] = v.Description
-	}
-
-	url = fmt.Sprintf("%v/cryptocurrencies", e.BaseURL)
-	req, err = http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		lg.WithError(err).Error("[GetCurrencies] NewRequest")
-		return
+		found[k] = core.Currency{Symbol: k, Description: v.Description, Source: exchangeSource, Kind: core.CurrencyFiat}
 	}
 
-	req = req.WithContext(ctx)
-	resp, err = e.client.Do(req)
-	if err != nil {
-		lg.WithError(err).Error("[GetCurrencies] client.Do")
-		return
+	b, err = e.get(ctx, "GetCurrencies", "cryptocurrencies", nil)
+	if errors.Is(err, core.ErrBudgetExhausted) {
+		return e.cachedCurrencies(sortCurrencies(found))
 	}
-	b, err = io.ReadAll(resp.Body)
 	if err != nil {
-		lg.WithError(err).Error("[GetCurrencies] ReadAll")
 		return
 	}
 	crypto := &core.CryptoClientResp{}
 	err = json.Unmarshal(b, crypto)
 	if err != nil {
 		lg.WithError(err).Error("[GetCurrencies] Unmarshal")
-		return
+		return nil, providerError(err)
 	}
 	if !crypto.Success {
-		lg.WithField("success", symbols.Success).Warn("[GetCurrencies] success")
-		return
+		lg.WithField("success", crypto.Success).Warn("[GetCurrencies] success")
+		return nil, fmt.Errorf("%w: cryptocurrencies were not successful", core.ErrProviderUnavailable)
 	}
 
-	l = map[string]string{}
+	// some cryptocurrencies are also listed as symbols
 	for _, v := range crypto.Cryptocurrencies {
-		l[v.Symbol] = v.Name
+		found[v.Symbol] = core.Currency{Symbol: v.Symbol, Description: v.Name, Source: exchangeSource, Kind: core.CurrencyCrypto}
 	}
 
+	l = sortCurrencies(found)
+	e.cache.setCurrencies(l)
 	lg.Info("[GetCurrencies] ok")
 	return l, err
 }
 
+// cachedCurrencies serves the last complete currency list, partial
+// is used when nothing was cached yet
+func (e Exchange) cachedCurrencies(partial core.Currencies) (core.Currencies, error) {
+	cached, found := e.cache.getCurrencies()
+	metrics.CacheLookup(staleCacheName, found)
+	if found {
+		return cached, nil
+	}
+	if partial != nil {
+		return partial, nil
+	}
+	return nil, core.ErrBudgetExhausted
+}
+
+func sortCurrencies(m map[string]core.Currency) core.Currencies {
+	l := make(core.Currencies, 0, len(m))
+	for _, c := range m {
+		l = append(l, c)
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].Symbol < l[j].Symbol })
+	return l
+}
+
 // Exchange tries to get the exchange rate for the given currencies
 //
 // receives a ctx so the request can be cancelled if the original request is also cancelled
 func (e Exchange) Exchange(ctx context.Context, from, to string, amount float64) (core.ConversionResp, error) {
 	lg := log.WithField("pkg", "exchange")
-	url := fmt.Sprintf("%v/convert", e.BaseURL)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		lg.WithError(err).Error("[Exchange] NewRequest")
-		return core.ConversionResp{}, err
-	}
-
-	q := req.URL.Query()
+	q := url.Values{}
 	q.Add("from", from)
 	q.Add("to", to)
 	q.Add("amount", fmt.Sprintf("%f", amount))
-	req.URL.RawQuery = q.Encode()
 
-	req = req.WithContext(ctx)
-	resp, err := e.client.Do(req)
-	if err != nil {
-		lg.WithError(err).Error("[Exchange] client.Do")
-		return core.ConversionResp{}, err
+	b, err := e.get(ctx, "Exchange", "convert", q)
+	if errors.Is(err, core.ErrBudgetExhausted) {
+		return e.stale(from, to, amount)
 	}
-
-	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		lg.WithError(err).Error("[Exchange] ReadAll")
 		return core.ConversionResp{}, err
 	}
 
@@ -140,20 +286,48 @@ func (e Exchange) Exchange(ctx context.Context, from, to string, amount float64)
 
 	if err = json.Unmarshal(b, conv); err != nil {
 		lg.WithError(err).Error("[Exchange] Unmarshal")
-		return core.ConversionResp{}, err
+		return core.ConversionResp{}, providerError(err)
 	}
 
 	if !conv.Success {
 		lg.WithField("success", conv.Success).Warn("[Exchange] success")
-		return core.ConversionResp{}, err
+		return core.ConversionResp{}, fmt.Errorf("%w: conversion was not successful", core.ErrProviderUnavailable)
 	}
 
+	rate := conv.Info.Rate
+	if rate == 0 && amount != 0 {
+		rate = conv.Result / amount
+	}
+	e.cache.setRate(from, to, rate)
+
 	lg.Info("[Exchange] ok")
 	return core.ConversionResp{
 		From:             from,
 		To:               to,
 		OriginalAmount:   amount,
 		ConvertedAmount:  conv.Result,
-		ConversionSource: "exchange",
+		ConversionSource: exchangeSource,
 	}, err
 }
+
+// stale converts with the last rate the provider returned for the pair
+func (e Exchange) stale(from, to string, amount float64) (core.ConversionResp, error) {
+	r, ok := e.cache.rate(from, to)
+	metrics.CacheLookup(staleCacheName, ok)
+	if !ok {
+		return core.ConversionResp{}, core.ErrBudgetExhausted
+	}
+	log.WithFields(log.Fields{
+		"pkg":       "exchange",
+		"from":      from,
+		"to":        to,
+		"cached_at": r.at,
+	}).Warn("[Exchange] serving stale rate")
+	return core.ConversionResp{
+		From:             from,
+		To:               to,
+		OriginalAmount:   amount,
+		ConvertedAmount:  amount * r.rate,
+		ConversionSource: staleSource,
+	}, nil
+}
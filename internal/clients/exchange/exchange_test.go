@@ -0,0 +1,197 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/stretchr/testify/require"
+)
+
+// provider answers like exchangerate.host, failures is the amount of
+// calls answered with 503 before the first success
+func newProvider(t *testing.T, failures int32) (*httptest.Server, *int32) {
+	calls := new(int32)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(calls, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/convert":
+			require.Equal(t, "USD", r.URL.Query().Get("from"))
+			fmt.Fprint(w, `{"success":true,"info":{"rate":5},"result":50}`)
+		case "/symbols":
+			fmt.Fprint(w, `{"success":true,"symbols":{"USD":{"description":"United States Dollar","code":"USD"}}}`)
+		case "/cryptocurrencies":
+			fmt.Fprint(w, `{"success":true,"cryptocurrencies":{"BTC":{"symbol":"BTC","name":"Bitcoin"}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, calls
+}
+
+func Test_Exchange(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		limit      int64
+		used       int64
+		failures   int32
+		retries    int
+		warm       bool
+		storeErr   error
+		wantSource string
+		wantAmount float64
+		wantCalls  int32
+		wantUsed   int64
+		wantErr    error
+	}{
+		{
+			name:       "provider rate",
+			limit:      10,
+			wantSource: exchangeSource,
+			wantAmount: 50,
+			wantCalls:  1,
+			wantUsed:   1,
+		},
+		{
+			name:       "retries are booked",
+			limit:      10,
+			failures:   1,
+			retries:    1,
+			wantSource: exchangeSource,
+			wantAmount: 50,
+			wantCalls:  2,
+			wantUsed:   2,
+		},
+		{
+			name:      "budget exhausted mid retries",
+			limit:     1,
+			failures:  1,
+			retries:   2,
+			wantCalls: 1,
+			wantUsed:  1,
+			wantErr:   core.ErrBudgetExhausted,
+		},
+		{
+			name:      "exhausted before the restart without cache",
+			limit:     3,
+			used:      3,
+			wantCalls: 0,
+			wantUsed:  3,
+			wantErr:   core.ErrBudgetExhausted,
+		},
+		{
+			name:       "exhausted serves the stale rate",
+			limit:      1,
+			warm:       true,
+			wantSource: staleSource,
+			wantAmount: 10,
+			wantCalls:  1,
+			wantUsed:   1,
+		},
+		{
+			name:       "store failure serves the stale rate",
+			limit:      10,
+			warm:       true,
+			storeErr:   errors.New("db"),
+			wantSource: staleSource,
+			wantAmount: 10,
+			wantCalls:  1,
+			wantUsed:   1,
+		},
+		{
+			name:      "store failure without cache",
+			limit:     10,
+			storeErr:  errors.New("db"),
+			wantCalls: 0,
+			wantUsed:  0,
+			wantErr:   core.ErrBudgetExhausted,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv, calls := newProvider(t, tt.failures)
+			store := newFakeStore(tt.used)
+			e := New(Config{APIBaseURL: srv.URL, MonthlyBudget: tt.limit, Retries: tt.retries}, store)
+			ctx := context.Background()
+			if tt.warm {
+				_, err := e.Exchange(ctx, "USD", "BRL", 10)
+				require.NoError(t, err)
+			}
+			store.mu.Lock()
+			store.err = tt.storeErr
+			store.mu.Unlock()
+
+			got, err := e.Exchange(ctx, "USD", "BRL", 2)
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.wantCalls, atomic.LoadInt32(calls))
+			require.Equal(t, tt.wantUsed, store.used())
+			if tt.wantErr != nil {
+				return
+			}
+			require.Equal(t, tt.wantSource, got.ConversionSource)
+			require.InDelta(t, tt.wantAmount, got.ConvertedAmount, 1e-9)
+		})
+	}
+}
+
+func Test_GetCurrencies_stale(t *testing.T) {
+	t.Parallel()
+	srv, calls := newProvider(t, 0)
+	store := newFakeStore(0)
+	e := New(Config{APIBaseURL: srv.URL, MonthlyBudget: 2}, store)
+	ctx := context.Background()
+
+	want := core.Currencies{
+		{Symbol: "BTC", Description: "Bitcoin", Source: exchangeSource, Kind: core.CurrencyCrypto},
+		{Symbol: "USD", Description: "United States Dollar", Source: exchangeSource, Kind: core.CurrencyFiat},
+	}
+	got, err := e.GetCurrencies(ctx)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+
+	// the budget is spent, the list comes from the cache
+	got, err = e.GetCurrencies(ctx)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+	require.Equal(t, int32(2), atomic.LoadInt32(calls))
+
+	pb, err := e.Budget(ctx)
+	require.NoError(t, err)
+	require.Equal(t, core.ProviderBudget{
+		Provider: provider, Period: pb.Period, Limit: 2, Used: 2, Exhausted: true,
+	}, pb)
+}
+
+func Test_Exchange_countersAcrossRestart(t *testing.T) {
+	t.Parallel()
+	srv, calls := newProvider(t, 0)
+	store := newFakeStore(0)
+	ctx := context.Background()
+
+	first := New(Config{APIBaseURL: srv.URL, MonthlyBudget: 2}, store)
+	_, err := first.Exchange(ctx, "USD", "BRL", 1)
+	require.NoError(t, err)
+
+	// a new instance shares the counter of the store
+	second := New(Config{APIBaseURL: srv.URL, MonthlyBudget: 2}, store)
+	pb, err := second.Budget(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), pb.Used)
+	_, err = second.Exchange(ctx, "USD", "BRL", 1)
+	require.NoError(t, err)
+	_, err = second.Exchange(ctx, "USD", "EUR", 1)
+	require.ErrorIs(t, err, core.ErrBudgetExhausted)
+	require.Equal(t, int32(2), atomic.LoadInt32(calls))
+}
@@ -0,0 +1,24 @@
+package oidc
+
+import "time"
+
+type Config struct {
+	// Issuer enables bearer token authentication when set
+	Issuer string `envconfig:"APP_OIDC_ISSUER" default:""`
+	// Audience is required with Issuer, it is the client id of bravo
+	// at the issuer
+	Audience string `envconfig:"APP_OIDC_AUDIENCE" default:""`
+	// JWKSURL or JWKSFile provide the keys that sign the tokens,
+	// the file takes precedence so tests and air-gapped setups
+	// do not need network
+	JWKSURL     string        `envconfig:"APP_OIDC_JWKS_URL" default:""`
+	JWKSFile    string        `envconfig:"APP_OIDC_JWKS_FILE" default:""`
+	JWKSRefresh time.Duration `envconfig:"APP_OIDC_JWKS_REFRESH" default:"1h"`
+	// RolesClaim is the claim holding the roles, nested claims
+	// use dots, ex: realm_access.roles
+	RolesClaim string `envconfig:"APP_OIDC_ROLES_CLAIM" default:"roles"`
+}
+
+func (c Config) Enabled() bool {
+	return c.Issuer != ""
+}
@@ -0,0 +1,200 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/golang-jwt/jwt/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// minRefetch avoids hammering the JWKS endpoint with tokens
+// signed by unknown keys
+const minRefetch = time.Minute
+
+var (
+	ErrNoJWKS     = errors.New("oidc needs APP_OIDC_JWKS_URL or APP_OIDC_JWKS_FILE")
+	ErrNoAudience = errors.New("oidc needs APP_OIDC_AUDIENCE, else tokens of other clients of the issuer are accepted")
+	errUnknownKid = errors.New("token signed by an unknown key")
+
+	validMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+)
+
+// Verifier validates bearer tokens issued by the configured issuer
+type Verifier struct {
+	cfg    Config
+	client http.Client
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+func New(cfg Config) (*Verifier, error) {
+	if cfg.Audience == "" {
+		return nil, ErrNoAudience
+	}
+	v := &Verifier{
+		cfg: cfg,
+		client: http.Client{
+			Timeout: time.Duration(time.Second * 10),
+		},
+	}
+	switch {
+	case cfg.JWKSFile != "":
+		b, err := os.ReadFile(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		if v.keys, err = parseJWKS(b); err != nil {
+			return nil, err
+		}
+	case cfg.JWKSURL == "":
+		return nil, ErrNoJWKS
+	}
+	return v, nil
+}
+
+// Verify validates the signature, issuer, audience and time claims
+// of the token, exp is required so no token is valid forever. The
+// roles of the token are mapped into scopes
+func (v *Verifier) Verify(ctx context.Context, token string) (core.Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.key(ctx, kid)
+	}, jwt.WithValidMethods(validMethods))
+	if err != nil {
+		return core.Principal{}, fmt.Errorf("%w: %v", core.ErrInvalidToken, err)
+	}
+	if !claims.VerifyIssuer(v.cfg.Issuer, true) {
+		return core.Principal{}, fmt.Errorf("%w: unexpected issuer", core.ErrInvalidToken)
+	}
+	if !claims.VerifyAudience(v.cfg.Audience, true) {
+		return core.Principal{}, fmt.Errorf("%w: unexpected audience", core.ErrInvalidToken)
+	}
+	// ParseWithClaims only checks exp when the token has it
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return core.Principal{}, fmt.Errorf("%w: missing exp", core.ErrInvalidToken)
+	}
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return core.Principal{}, fmt.Errorf("%w: missing sub", core.ErrInvalidToken)
+	}
+	roles := rolesFrom(claims, v.cfg.RolesClaim)
+	return core.Principal{
+		ID:     sub,
+		Kind:   core.PrincipalJWT,
+		Roles:  roles,
+		Scopes: core.ScopesForRoles(roles),
+	}, nil
+}
+
+// key retrieves the public key of kid, keys from a JWKS url are
+// refreshed periodically and when an unknown kid shows up
+func (v *Verifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.RLock()
+	k, ok := v.lookup(kid)
+	stale := v.cfg.JWKSFile == "" && time.Since(v.fetchedAt) > v.cfg.JWKSRefresh
+	canRefetch := v.cfg.JWKSFile == "" && time.Since(v.fetchedAt) > minRefetch
+	v.mu.RUnlock()
+	if ok && !stale {
+		return k, nil
+	}
+	if !stale && !canRefetch {
+		return nil, errUnknownKid
+	}
+	if err := v.fetch(ctx); err != nil {
+		if ok {
+			log.WithField("pkg", "oidc").WithError(err).Warn("[key] using stale jwks")
+			return k, nil
+		}
+		return nil, err
+	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if k, ok = v.lookup(kid); ok {
+		return k, nil
+	}
+	return nil, errUnknownKid
+}
+
+// lookup assumes the lock is held, tokens without kid are
+// accepted only when there is a single key
+func (v *Verifier) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, k := range v.keys {
+			return k, true
+		}
+	}
+	k, ok := v.keys[kid]
+	return k, ok
+}
+
+func (v *Verifier) fetch(ctx context.Context) error {
+	lg := log.WithField("pkg", "oidc")
+	req, err := http.NewRequest(http.MethodGet, v.cfg.JWKSURL, nil)
+	if err != nil {
+		lg.WithError(err).Error("[fetch] NewRequest")
+		return err
+	}
+	req = req.WithContext(ctx)
+	resp, err := v.client.Do(req)
+	if err != nil {
+		lg.WithError(err).Error("[fetch] client.Do")
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		lg.WithField("status", resp.StatusCode).Error("[fetch] status")
+		return fmt.Errorf("jwks: unexpected status %d", resp.StatusCode)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		lg.WithError(err).Error("[fetch] ReadAll")
+		return err
+	}
+	keys, err := parseJWKS(b)
+	if err != nil {
+		lg.WithError(err).Error("[fetch] parseJWKS")
+		return err
+	}
+	v.mu.Lock()
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	v.mu.Unlock()
+	lg.WithField("keys", len(keys)).Info("[fetch] ok")
+	return nil
+}
+
+// rolesFrom reads the roles claim, following dots into nested claims
+func rolesFrom(claims jwt.MapClaims, claim string) (roles []string) {
+	var value interface{} = map[string]interface{}(claims)
+	for _, part := range strings.Split(claim, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[part]
+	}
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		for _, r := range v {
+			if s, ok := r.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+	}
+	return roles
+}
@@ -0,0 +1,191 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestVerifier writes the public part of key into a local JWKS
+// file, so tokens are validated without network
+func newTestVerifier(t *testing.T, key *rsa.PrivateKey, rolesClaim string) *Verifier {
+	t.Helper()
+	set := jwks{Keys: []jwk{{
+		Kid: "test",
+		Kty: "RSA",
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}}
+	b, err := json.Marshal(set)
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(file, b, 0o600))
+
+	v, err := New(Config{
+		Issuer:     "https://issuer.test",
+		Audience:   "bravo",
+		JWKSFile:   file,
+		RolesClaim: rolesClaim,
+	})
+	require.NoError(t, err)
+	return v
+}
+
+func sign(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	s, err := token.SignedString(key)
+	require.NoError(t, err)
+	return s
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	t.Parallel()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   "https://issuer.test",
+			"aud":   []string{"bravo"},
+			"sub":   "user-1",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"roles": []string{"rate-editor", "unknown"},
+		}
+	}
+	with := func(k string, v interface{}) jwt.MapClaims {
+		c := valid()
+		c[k] = v
+		return c
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		rolesClaim string
+		wantErr    error
+		wantRoles  []string
+		wantScopes []core.Scope
+	}{
+		{
+			name:       "valid token",
+			token:      sign(t, key, "test", valid()),
+			rolesClaim: "roles",
+			wantRoles:  []string{"rate-editor", "unknown"},
+			wantScopes: []core.Scope{core.ScopeRatesRead, core.ScopeRatesWrite},
+		},
+		{
+			name: "nested roles claim",
+			token: sign(t, key, "test", with("realm_access", map[string]interface{}{
+				"roles": []string{"reader"},
+			})),
+			rolesClaim: "realm_access.roles",
+			wantRoles:  []string{"reader"},
+			wantScopes: []core.Scope{core.ScopeConvertRead, core.ScopeCurrenciesRead, core.ScopeRatesRead},
+		},
+		{
+			name:       "expired",
+			token:      sign(t, key, "test", with("exp", time.Now().Add(-time.Minute).Unix())),
+			rolesClaim: "roles",
+			wantErr:    core.ErrInvalidToken,
+		},
+		{
+			name:       "wrong issuer",
+			token:      sign(t, key, "test", with("iss", "https://evil.test")),
+			rolesClaim: "roles",
+			wantErr:    core.ErrInvalidToken,
+		},
+		{
+			name:       "wrong audience",
+			token:      sign(t, key, "test", with("aud", "other")),
+			rolesClaim: "roles",
+			wantErr:    core.ErrInvalidToken,
+		},
+		{
+			name: "missing audience",
+			token: sign(t, key, "test", func() jwt.MapClaims {
+				c := valid()
+				delete(c, "aud")
+				return c
+			}()),
+			rolesClaim: "roles",
+			wantErr:    core.ErrInvalidToken,
+		},
+		{
+			name: "missing exp",
+			token: sign(t, key, "test", func() jwt.MapClaims {
+				c := valid()
+				delete(c, "exp")
+				return c
+			}()),
+			rolesClaim: "roles",
+			wantErr:    core.ErrInvalidToken,
+		},
+		{
+			name:       "unknown kid",
+			token:      sign(t, key, "rotated", valid()),
+			rolesClaim: "roles",
+			wantErr:    core.ErrInvalidToken,
+		},
+		{
+			name:       "signed by another key",
+			token:      sign(t, other, "test", valid()),
+			rolesClaim: "roles",
+			wantErr:    core.ErrInvalidToken,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			v := newTestVerifier(t, key, tt.rolesClaim)
+			p, err := v.Verify(context.Background(), tt.token)
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+			require.Equal(t, "user-1", p.ID)
+			require.Equal(t, core.PrincipalJWT, p.Kind)
+			require.Equal(t, tt.wantRoles, p.Roles)
+			require.Equal(t, tt.wantScopes, p.Scopes)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"keys":[]}`), 0o600))
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr error
+	}{
+		{name: "audience is required", cfg: Config{Issuer: "https://issuer.test", JWKSFile: file}, wantErr: ErrNoAudience},
+		{name: "keys are required", cfg: Config{Issuer: "https://issuer.test", Audience: "bravo"}, wantErr: ErrNoJWKS},
+		{name: "ok", cfg: Config{Issuer: "https://issuer.test", Audience: "bravo", JWKSFile: file}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := New(tt.cfg)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package webhook
+
+import "time"
+
+type Config struct {
+	// Timeout bounds each delivery attempt
+	Timeout time.Duration `envconfig:"APP_WEBHOOK_TIMEOUT" default:"5s" validate:"gt=0"`
+}
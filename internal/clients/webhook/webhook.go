@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Headers sent with every delivery, receivers verify the signature
+// by computing the HMAC-SHA256 of "<timestamp>.<body>" with the
+// secret of the subscription
+const (
+	HeaderEvent     = "X-Bravo-Event"
+	HeaderDelivery  = "X-Bravo-Delivery"
+	HeaderTimestamp = "X-Bravo-Timestamp"
+	HeaderSignature = "X-Bravo-Signature"
+)
+
+// signaturePrefix names the algorithm so it can change later
+const signaturePrefix = "sha256="
+
+// errNotPublic is returned when a receiver resolves to an address of
+// the internal network
+var errNotPublic = errors.New("receiver address is not public")
+
+type Client struct {
+	client http.Client
+}
+
+func New(cfg Config) Client {
+	return newClient(cfg, publicOnly)
+}
+
+// newClient dials the receivers through control, which may refuse
+// an address before connecting
+func newClient(cfg Config, control func(network, address string, c syscall.RawConn) error) Client {
+	dialer := &net.Dialer{Timeout: cfg.Timeout, Control: control}
+	// no proxy from the environment, it would dial in place of the
+	// receiver and skip the address check
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: cfg.Timeout,
+	}
+	return Client{client: http.Client{Timeout: cfg.Timeout, Transport: transport}}
+}
+
+// publicOnly refuses the addresses that are not public, it sees the
+// resolved address so a name pointing to the internal network, or
+// rebound to it after the subscription, is refused as well
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !core.PublicIP(ip) {
+		return fmt.Errorf("%w: %v", errNotPublic, host)
+	}
+	return nil
+}
+
+// Deliver posts the payload of d to the subscription url once, code
+// is the status answered by the receiver and any answer outside of
+// 2xx is an error
+func (c Client) Deliver(ctx context.Context, sub core.AlertSubscription, d core.WebhookDelivery) (code int, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "POST webhook", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() { tracing.End(span, err) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bravo-webhooks")
+	req.Header.Set(HeaderEvent, core.AlertEventName)
+	req.Header.Set(HeaderDelivery, d.ID)
+	req.Header.Set(HeaderTimestamp, ts)
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, ts, d.Payload))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// drain so the connection is reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %v", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign is the value of the signature header of a delivery
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Deliver(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		status   int
+		wantCode int
+		wantErr  bool
+	}{
+		{name: "delivered", status: http.StatusNoContent, wantCode: http.StatusNoContent},
+		{name: "receiver failure", status: http.StatusBadGateway, wantCode: http.StatusBadGateway, wantErr: true},
+		{name: "redirects are not followed as success", status: http.StatusNotModified, wantCode: http.StatusNotModified, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sub := core.AlertSubscription{Secret: "whsec_test"}
+			d := core.WebhookDelivery{ID: "d1", Payload: []byte(`{"event":"rate.alert"}`)}
+
+			received := make(chan *http.Request, 1)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.Equal(t, string(d.Payload), string(body))
+				// what a receiver does to authenticate the delivery
+				require.Equal(t, Sign(sub.Secret, r.Header.Get(HeaderTimestamp), body), r.Header.Get(HeaderSignature))
+				received <- r
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+			sub.URL = srv.URL
+
+			// the receiver listens on loopback, which New refuses
+			code, err := newClient(Config{Timeout: time.Second}, nil).Deliver(context.Background(), sub, d)
+			require.Equal(t, tt.wantCode, code)
+			require.Equal(t, tt.wantErr, err != nil, err)
+
+			r := <-received
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Equal(t, core.AlertEventName, r.Header.Get(HeaderEvent))
+			require.Equal(t, "d1", r.Header.Get(HeaderDelivery))
+			require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		})
+	}
+}
+
+func Test_Deliver_notPublic(t *testing.T) {
+	t.Parallel()
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+	// a name resolving to loopback is refused the same way
+	u := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+
+	for _, url := range []string{srv.URL, u} {
+		sub := core.AlertSubscription{Secret: "whsec_test", URL: url}
+		code, err := New(Config{Timeout: time.Second}).Deliver(context.Background(), sub, core.WebhookDelivery{ID: "d1"})
+		require.Zero(t, code)
+		require.ErrorIs(t, err, errNotPublic)
+	}
+	require.False(t, called)
+}
+
+func Test_Sign(t *testing.T) {
+	t.Parallel()
+	body := []byte(`{"rate":5.5}`)
+	sig := Sign("secret", "1700000000", body)
+	require.Equal(t, "sha256=", sig[:7])
+	require.Len(t, sig, 7+64)
+	require.NotEqual(t, sig, Sign("other", "1700000000", body))
+	require.NotEqual(t, sig, Sign("secret", "1700000001", body))
+}
@@ -0,0 +1,189 @@
+package core
+
+import (
+	"encoding/json"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type AlertCondition string
+
+const (
+	// AlertAbove fires when the rate crosses the threshold upwards
+	AlertAbove AlertCondition = "above"
+	// AlertBelow fires when the rate crosses the threshold downwards
+	AlertBelow AlertCondition = "below"
+	// AlertChange fires when the rate moves more than threshold
+	// percent, in either direction, within the window
+	AlertChange AlertCondition = "change"
+)
+
+// AlertEventName identifies alert deliveries in the webhook headers
+const AlertEventName = "rate.alert"
+
+// Duration is a time.Duration written as "1h30m" in JSON
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return ErrInvalidAlertWindow
+	}
+	*d = Duration(v)
+	return nil
+}
+
+type AlertSubscriptions []AlertSubscription
+
+// AlertSubscription notifies URL when the rate of the pair meets
+// the condition, the secret signs the deliveries and is only shown
+// when the subscription is created
+type AlertSubscription struct {
+	ID              string         `json:"id"`
+	From            string         `json:"from"`
+	To              string         `json:"to"`
+	Condition       AlertCondition `json:"condition"`
+	Threshold       float64        `json:"threshold"`
+	Window          Duration       `json:"window,omitempty"`
+	URL             string         `json:"url"`
+	Secret          string         `json:"-"`
+	Active          bool           `json:"active"`
+	CreatedAt       time.Time      `json:"created_at"`
+	LastTriggeredAt *time.Time     `json:"last_triggered_at,omitempty"`
+}
+
+func (a AlertSubscription) Check() error {
+	if len(a.From) < 3 || len(a.To) < 3 {
+		return ErrInvalidPair
+	}
+	switch a.Condition {
+	case AlertAbove, AlertBelow:
+		if a.Window != 0 {
+			return ErrInvalidAlertWindow
+		}
+	case AlertChange:
+		if a.Window <= 0 {
+			return ErrInvalidAlertWindow
+		}
+	default:
+		return ErrInvalidAlertCondition
+	}
+	if a.Threshold <= 0 {
+		return ErrInvalidAlertThreshold
+	}
+	u, err := url.Parse(a.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	// names are resolved when delivering, the client refuses the
+	// addresses that are not public then
+	host := u.Hostname()
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrWebhookURLNotPublic
+	}
+	if ip := net.ParseIP(host); ip != nil && !PublicIP(ip) {
+		return ErrWebhookURLNotPublic
+	}
+	return nil
+}
+
+// sharedNetworks are not public although net.IP does not flag them,
+// 100.64.0.0/10 also hosts the metadata service of some clouds
+var sharedNetworks = []*net.IPNet{
+	mustCIDR("0.0.0.0/8"),
+	mustCIDR("100.64.0.0/10"),
+}
+
+func mustCIDR(s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// PublicIP reports whether webhooks may be delivered to ip, loopback,
+// link-local, private and multicast addresses are refused so alerts
+// cannot reach the internal network
+func PublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsPrivate() || ip.IsUnspecified() {
+		return false
+	}
+	for _, n := range sharedNetworks {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Pair is the FROM/TO pair watched by the subscription
+func (a AlertSubscription) Pair() string {
+	return Pair(a.From, a.To)
+}
+
+// Triggered reports whether moving from previous to rate meets the
+// condition, previous is the rate before the update for thresholds
+// and the oldest rate of the window for changes. It also returns the
+// change in percent
+func (a AlertSubscription) Triggered(previous, rate float64) (bool, float64) {
+	if previous <= 0 {
+		return false, 0
+	}
+	change := (rate - previous) / previous * 100
+	switch a.Condition {
+	case AlertAbove:
+		return previous < a.Threshold && rate >= a.Threshold, change
+	case AlertBelow:
+		return previous > a.Threshold && rate <= a.Threshold, change
+	case AlertChange:
+		return change >= a.Threshold || -change >= a.Threshold, change
+	}
+	return false, change
+}
+
+// AlertEvent is the body of a webhook delivery
+type AlertEvent struct {
+	Event        string         `json:"event"`
+	Subscription string         `json:"subscription_id"`
+	Pair         string         `json:"pair"`
+	Condition    AlertCondition `json:"condition"`
+	Threshold    float64        `json:"threshold"`
+	Window       Duration       `json:"window,omitempty"`
+	Previous     float64        `json:"previous"`
+	Rate         float64        `json:"rate"`
+	Change       float64        `json:"change"`
+	Source       string         `json:"source"`
+	At           time.Time      `json:"at"`
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+type WebhookDeliveries []WebhookDelivery
+
+// WebhookDelivery is an entry of the delivery log, it keeps the
+// outcome of the last attempt to post Payload to the subscriber
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseCode   int             `json:"response_code,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertSubscription_Check(t *testing.T) {
+	t.Parallel()
+	valid := AlertSubscription{From: "USD", To: "BRL", Condition: AlertAbove, Threshold: 5.5, URL: "https://example.com/hook"}
+	tests := []struct {
+		name    string
+		change  func(a *AlertSubscription)
+		wantErr error
+	}{
+		{name: "valid", change: func(a *AlertSubscription) {}},
+		{name: "invalid pair", change: func(a *AlertSubscription) { a.To = "BR" }, wantErr: ErrInvalidPair},
+		{name: "unknown condition", change: func(a *AlertSubscription) { a.Condition = "equal" }, wantErr: ErrInvalidAlertCondition},
+		{name: "threshold with window", change: func(a *AlertSubscription) { a.Window = Duration(time.Hour) }, wantErr: ErrInvalidAlertWindow},
+		{name: "change without window", change: func(a *AlertSubscription) { a.Condition = AlertChange }, wantErr: ErrInvalidAlertWindow},
+		{name: "change with window", change: func(a *AlertSubscription) { a.Condition, a.Window = AlertChange, Duration(time.Hour) }},
+		{name: "zero threshold", change: func(a *AlertSubscription) { a.Threshold = 0 }, wantErr: ErrInvalidAlertThreshold},
+		{name: "relative url", change: func(a *AlertSubscription) { a.URL = "/hook" }, wantErr: ErrInvalidWebhookURL},
+		{name: "not http", change: func(a *AlertSubscription) { a.URL = "ftp://example.com/hook" }, wantErr: ErrInvalidWebhookURL},
+		{name: "public ip", change: func(a *AlertSubscription) { a.URL = "https://93.184.216.34/hook" }},
+		{name: "localhost", change: func(a *AlertSubscription) { a.URL = "http://localhost:8080/hook" }, wantErr: ErrWebhookURLNotPublic},
+		{name: "loopback", change: func(a *AlertSubscription) { a.URL = "http://127.0.0.1/hook" }, wantErr: ErrWebhookURLNotPublic},
+		{name: "metadata service", change: func(a *AlertSubscription) { a.URL = "http://169.254.169.254/latest" }, wantErr: ErrWebhookURLNotPublic},
+		{name: "private", change: func(a *AlertSubscription) { a.URL = "https://10.0.0.5/hook" }, wantErr: ErrWebhookURLNotPublic},
+		{name: "ipv6 loopback", change: func(a *AlertSubscription) { a.URL = "http://[::1]:8080/hook" }, wantErr: ErrWebhookURLNotPublic},
+		{name: "ipv4 mapped", change: func(a *AlertSubscription) { a.URL = "http://[::ffff:192.168.0.1]/hook" }, wantErr: ErrWebhookURLNotPublic},
+		{name: "shared network", change: func(a *AlertSubscription) { a.URL = "http://100.100.100.200/hook" }, wantErr: ErrWebhookURLNotPublic},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			a := valid
+			tt.change(&a)
+			require.Equal(t, tt.wantErr, a.Check())
+		})
+	}
+}
+
+func TestAlertSubscription_Triggered(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		condition  AlertCondition
+		threshold  float64
+		previous   float64
+		rate       float64
+		want       bool
+		wantChange float64
+	}{
+		{name: "crosses above", condition: AlertAbove, threshold: 5.5, previous: 5.4, rate: 5.5, want: true, wantChange: 1.85},
+		{name: "stays above", condition: AlertAbove, threshold: 5.5, previous: 5.6, rate: 5.7, wantChange: 1.79},
+		{name: "unknown previous", condition: AlertAbove, threshold: 5.5, rate: 5.7},
+		{name: "crosses below", condition: AlertBelow, threshold: 5.5, previous: 5.6, rate: 5.4, want: true, wantChange: -3.57},
+		{name: "rises through below", condition: AlertBelow, threshold: 5.5, previous: 5.4, rate: 5.6, wantChange: 3.7},
+		{name: "moves up", condition: AlertChange, threshold: 2, previous: 5, rate: 5.11, want: true, wantChange: 2.2},
+		{name: "moves down", condition: AlertChange, threshold: 2, previous: 5, rate: 4.8, want: true, wantChange: -4},
+		{name: "moves too little", condition: AlertChange, threshold: 2, previous: 5, rate: 5.05, wantChange: 1},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			a := AlertSubscription{Condition: tt.condition, Threshold: tt.threshold}
+			got, change := a.Triggered(tt.previous, tt.rate)
+			require.Equal(t, tt.want, got)
+			require.InDelta(t, tt.wantChange, change, 0.01)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Scope string
+
+const (
+	ScopeConvertRead     Scope = "convert:read"
+	ScopeCurrenciesRead  Scope = "currencies:read"
+	ScopeCurrenciesWrite Scope = "currencies:write"
+	ScopeRatesRead       Scope = "rates:read"
+	ScopeRatesWrite      Scope = "rates:write"
+	ScopeRatesApprove    Scope = "rates:approve"
+	ScopeAlertsRead      Scope = "alerts:read"
+	ScopeAlertsWrite     Scope = "alerts:write"
+	ScopeKeysAdmin       Scope = "keys:admin"
+)
+
+// Scopes lists every scope that can be granted to a key
+var Scopes = []Scope{
+	ScopeConvertRead,
+	ScopeCurrenciesRead,
+	ScopeCurrenciesWrite,
+	ScopeRatesRead,
+	ScopeRatesWrite,
+	ScopeRatesApprove,
+	ScopeAlertsRead,
+	ScopeAlertsWrite,
+	ScopeKeysAdmin,
+}
+
+// ParseScopes parses a comma separated list of scopes
+func ParseScopes(s string) (scopes []Scope, err error) {
+	for _, sc := range strings.Split(s, ",") {
+		sc = strings.TrimSpace(sc)
+		if sc == "" {
+			continue
+		}
+		scopes = append(scopes, Scope(sc))
+	}
+	return scopes, CheckScopes(scopes)
+}
+
+func CheckScopes(scopes []Scope) error {
+	if len(scopes) == 0 {
+		return ErrScopesRequired
+	}
+	for _, sc := range scopes {
+		if !sc.valid() {
+			return ErrInvalidScope
+		}
+	}
+	return nil
+}
+
+func (s Scope) valid() bool {
+	for _, sc := range Scopes {
+		if s == sc {
+			return true
+		}
+	}
+	return false
+}
+
+type APIKeys []APIKey
+
+// APIKey is the stored representation of a key, the secret
+// itself is only known by the client, we keep its hash
+type APIKey struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Scopes    []Scope    `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (k APIKey) Check() error {
+	if k.Name == "" {
+		return ErrKeyNameRequired
+	}
+	return CheckScopes(k.Scopes)
+}
+
+// ValidKeyID reports whether id can be the id of a key, ids are
+// uuids so anything else can't be found
+func ValidKeyID(id string) bool {
+	_, err := uuid.Parse(id)
+	return err == nil
+}
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	ID     string   `json:"id"`
+	Kind   string   `json:"kind"`
+	Roles  []string `json:"roles,omitempty"`
+	Scopes []Scope  `json:"scopes"`
+}
+
+const (
+	PrincipalAPIKey = "api_key"
+	PrincipalJWT    = "jwt"
+)
+
+// RoleScopes maps the roles of bearer tokens into scopes
+var RoleScopes = map[string][]Scope{
+	"reader":          {ScopeConvertRead, ScopeCurrenciesRead, ScopeRatesRead},
+	"currency-editor": {ScopeCurrenciesRead, ScopeCurrenciesWrite},
+	"rate-editor":     {ScopeRatesRead, ScopeRatesWrite},
+	"rate-approver":   {ScopeRatesRead, ScopeRatesApprove},
+	"alert-manager":   {ScopeRatesRead, ScopeAlertsRead, ScopeAlertsWrite},
+	"admin":           Scopes,
+}
+
+// ScopesForRoles merges the scopes granted by each role,
+// unknown roles grant nothing
+func ScopesForRoles(roles []string) (scopes []Scope) {
+	seen := map[Scope]bool{}
+	for _, r := range roles {
+		for _, s := range RoleScopes[r] {
+			if !seen[s] {
+				seen[s] = true
+				scopes = append(scopes, s)
+			}
+		}
+	}
+	return scopes
+}
+
+// HasScope reports whether the principal was granted the scope
+func (p Principal) HasScope(scope Scope) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
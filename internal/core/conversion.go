@@ -1,6 +1,8 @@
 package core
 
-import "strconv"
+import (
+	"time"
+)
 
 var allowedCurrencies = map[string]bool{
 	"USD": true,
@@ -14,12 +16,20 @@ type ConversionAPI struct {
 	From   string
 	To     string
 	Amount string
+	// At is an optional RFC3339 timestamp used to pick the effective rate
+	At string
+	// Locale is an optional BCP 47 tag the amount is written in, without
+	// it the amount is plain and AcceptLanguage only formats the response
+	Locale         string
+	AcceptLanguage string
 }
 
 type ConversionSVC struct {
 	From   string
 	To     string
 	Amount float64
+	// At is the instant the conversion refers to, zero means now
+	At time.Time
 }
 
 type ConversionResp struct {
@@ -28,6 +38,22 @@ type ConversionResp struct {
 	OriginalAmount   float64 `json:"original_amount"`
 	ConvertedAmount  float64 `json:"converted_amount"`
 	ConversionSource string  `json:"conversion_source"`
+	// Formatted is only set when the conversion was asked in a locale
+	Formatted *FormattedConversion `json:"formatted,omitempty"`
+}
+
+// Conversion is a completed conversion of the history
+type Conversion struct {
+	ID string `json:"id"`
+	ConversionEvent
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ConversionResult is an item of a batch of conversions, Err is set
+// when that item could not be converted
+type ConversionResult struct {
+	ConversionResp
+	Err error
 }
 
 func (c ConversionAPI) Check() (err error) {
@@ -37,33 +63,66 @@ func (c ConversionAPI) Check() (err error) {
 	if len(c.To) < 3 {
 		return ErrSymbolMinLen
 	}
-	_, err = strconv.ParseFloat(c.Amount, 64)
-	if err != nil {
-		return ErrAmountIsNotANumber
+	if _, err = c.amount(); err != nil {
+		return err
+	}
+	if c.At != "" {
+		if _, err = time.Parse(time.RFC3339, c.At); err != nil {
+			return ErrInvalidTimestamp
+		}
 	}
 	return err
 }
 
+// NumberFormat is the format of the response, nil when no locale was
+// asked for
+func (c ConversionAPI) NumberFormat() (*NumberFormat, error) {
+	return ResolveNumberFormat(c.Locale, c.AcceptLanguage)
+}
+
+// amount is only localized by the locale param. Accept-Language is
+// sent by browsers without the client choosing it, so it can't turn
+// 1.234 into 1234, such amounts are refused as ambiguous instead
+func (c ConversionAPI) amount() (float64, error) {
+	nf, err := c.NumberFormat()
+	if err != nil {
+		return 0, err
+	}
+	if c.Locale != "" {
+		return nf.ParseAmount(c.Amount)
+	}
+	return nf.ParsePlainAmount(c.Amount)
+}
+
 // ConvertToService assumes that Check has already been called
 // and everything is ok to proceed
 //
 // if currencies are equal, returns dont convert command and return same amount
 func ConvertToService(c ConversionAPI) (cs ConversionSVC, should bool, err error) {
-	amount, err := strconv.ParseFloat(c.Amount, 64)
+	amount, err := c.amount()
 	if err != nil {
-		return cs, false, ErrAmountIsNotANumber
+		return cs, false, err
+	}
+	var at time.Time
+	if c.At != "" {
+		at, err = time.Parse(time.RFC3339, c.At)
+		if err != nil {
+			return cs, false, ErrInvalidTimestamp
+		}
 	}
 	if c.From == c.To {
 		return ConversionSVC{
 			From:   c.From,
 			To:     c.To,
 			Amount: amount,
+			At:     at,
 		}, false, nil
 	}
 	return ConversionSVC{
 		From:   c.From,
 		To:     c.To,
 		Amount: amount,
+		At:     at,
 	}, true, err
 }
 
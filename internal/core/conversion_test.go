@@ -55,6 +55,61 @@ func TestConversionAPI_Check(t *testing.T) {
 			wantErrFn:     require.NoError,
 			wantErrEquals: nil,
 		},
+		{
+			name: "localized amount",
+			api: ConversionAPI{
+				From:   "USD",
+				To:     "BRL",
+				Amount: "1.234,56",
+				Locale: "pt-BR",
+			},
+			wantErrFn:     require.NoError,
+			wantErrEquals: nil,
+		},
+		{
+			name: "accept language does not localize the amount",
+			api: ConversionAPI{
+				From:           "USD",
+				To:             "BRL",
+				Amount:         "1.234,56",
+				AcceptLanguage: "pt-BR",
+			},
+			wantErrFn:     require.Error,
+			wantErrEquals: ErrAmountIsNotANumber,
+		},
+		{
+			name: "ambiguous in the accept language",
+			api: ConversionAPI{
+				From:           "USD",
+				To:             "BRL",
+				Amount:         "1.234",
+				AcceptLanguage: "pt-BR",
+			},
+			wantErrFn:     require.Error,
+			wantErrEquals: ErrAmbiguousAmount,
+		},
+		{
+			name: "plain in the accept language",
+			api: ConversionAPI{
+				From:           "USD",
+				To:             "BRL",
+				Amount:         "1.5",
+				AcceptLanguage: "pt-BR",
+			},
+			wantErrFn:     require.NoError,
+			wantErrEquals: nil,
+		},
+		{
+			name: "invalid locale",
+			api: ConversionAPI{
+				From:   "USD",
+				To:     "BRL",
+				Amount: "100",
+				Locale: "not a locale",
+			},
+			wantErrFn:     require.Error,
+			wantErrEquals: ErrInvalidLocale,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
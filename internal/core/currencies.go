@@ -1,11 +1,33 @@
 package core
 
+import "time"
+
 type Currencies []Currency
 
 type Currency struct {
 	Symbol      string `json:"symbol"`
 	Description string `json:"description"`
 	Source      string `json:"source"`
+	Kind        string `json:"kind,omitempty"`
+	Deleted     bool   `json:"deleted,omitempty"`
+	// UpdatedAt is the version of the stored currency
+	UpdatedAt time.Time `json:"-"`
+}
+
+// Currency kinds
+const (
+	CurrencyFiat   = "fiat"
+	CurrencyCrypto = "crypto"
+)
+
+// LastModified is the most recent version among the currencies
+func (cs Currencies) LastModified() (t time.Time) {
+	for _, c := range cs {
+		if c.UpdatedAt.After(t) {
+			t = c.UpdatedAt
+		}
+	}
+	return
 }
 
 func (c Currency) Check() error {
@@ -18,10 +40,50 @@ func (c Currency) Check() error {
 	return nil
 }
 
+type CurrencyRates []CurrencyRate
+
+// LastModified is the most recent version among the windows
+func (rs CurrencyRates) LastModified() (t time.Time) {
+	for _, r := range rs {
+		if r.UpdatedAt.After(t) {
+			t = r.UpdatedAt
+		}
+	}
+	return
+}
+
+// Version is the ETag of the schedule, it changes with any window
+func (rs CurrencyRates) Version() (string, error) {
+	return ETag(rs, rs.LastModified())
+}
+
+// RateOf finds the rate of the pair among windows effective at the
+// same instant, the inverse of the opposite pair is used when the
+// pair itself has no window
+func (rs CurrencyRates) RateOf(from, to string) (float64, bool) {
+	inverse := 0.0
+	for _, r := range rs {
+		if r.From == from && r.To == to {
+			return r.Rate, true
+		}
+		if r.From == to && r.To == from && r.Rate != 0 {
+			inverse = 1 / r.Rate
+		}
+	}
+	return inverse, inverse != 0
+}
+
+// CurrencyRate is the rate used to convert From into To during
+// the window [ValidFrom, ValidTo). A nil ValidTo means the rate
+// is valid until a newer window is scheduled.
 type CurrencyRate struct {
-	From string  `json:"from"`
-	To   string  `json:"to"`
-	Rate float64 `json:"rate"`
+	From      string     `json:"from"`
+	To        string     `json:"to"`
+	Rate      float64    `json:"rate"`
+	ValidFrom time.Time  `json:"valid_from"`
+	ValidTo   *time.Time `json:"valid_to,omitempty"`
+	// UpdatedAt is the version of the stored window
+	UpdatedAt time.Time `json:"-"`
 }
 
 func (c CurrencyRate) Check() (err error) {
@@ -34,5 +96,39 @@ func (c CurrencyRate) Check() (err error) {
 	if c.Rate == 0 {
 		return ErrRateIsZero
 	}
+	if c.ValidTo != nil && !c.ValidTo.After(c.ValidFrom) {
+		return ErrRateInvalidWindow
+	}
 	return err
 }
+
+// EffectiveAt reports whether the rate window contains t
+func (c CurrencyRate) EffectiveAt(t time.Time) bool {
+	if t.Before(c.ValidFrom) {
+		return false
+	}
+	return c.ValidTo == nil || t.Before(*c.ValidTo)
+}
+
+// Supersedes reports whether c is the successor of the open-ended
+// window o, which is closed when c starts instead of overlapping it.
+// c can only be applied when it does not start in the past, else the
+// rate of conversions already made would change
+func (c CurrencyRate) Supersedes(o CurrencyRate) bool {
+	return c.From == o.From && c.To == o.To && o.ValidTo == nil && o.ValidFrom.Before(c.ValidFrom)
+}
+
+// Overlaps reports whether both rates are for the same pair
+// and their validity windows intersect
+func (c CurrencyRate) Overlaps(o CurrencyRate) bool {
+	if c.From != o.From || c.To != o.To {
+		return false
+	}
+	if c.ValidTo != nil && !c.ValidTo.After(o.ValidFrom) {
+		return false
+	}
+	if o.ValidTo != nil && !o.ValidTo.After(c.ValidFrom) {
+		return false
+	}
+	return true
+}
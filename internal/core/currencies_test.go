@@ -0,0 +1,175 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyRate_Check(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	before := now.Add(-time.Hour)
+	tests := []struct {
+		name          string
+		rate          CurrencyRate
+		wantErrEquals error
+	}{
+		{
+			name:          "empty should return min len err",
+			rate:          CurrencyRate{},
+			wantErrEquals: ErrSymbolMinLen,
+		},
+		{
+			name:          "zero rate",
+			rate:          CurrencyRate{From: "USD", To: "BRL"},
+			wantErrEquals: ErrRateIsZero,
+		},
+		{
+			name: "valid_to before valid_from",
+			rate: CurrencyRate{
+				From: "USD", To: "BRL", Rate: 5.2,
+				ValidFrom: now, ValidTo: &before,
+			},
+			wantErrEquals: ErrRateInvalidWindow,
+		},
+		{
+			name: "valid_to equal to valid_from",
+			rate: CurrencyRate{
+				From: "USD", To: "BRL", Rate: 5.2,
+				ValidFrom: now, ValidTo: &now,
+			},
+			wantErrEquals: ErrRateInvalidWindow,
+		},
+		{
+			name:          "open window",
+			rate:          CurrencyRate{From: "USD", To: "BRL", Rate: 5.2, ValidFrom: now},
+			wantErrEquals: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.wantErrEquals, tt.rate.Check())
+		})
+	}
+}
+
+func TestCurrencyRate_EffectiveAt(t *testing.T) {
+	t.Parallel()
+	from := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	closed := CurrencyRate{ValidFrom: from, ValidTo: &to}
+	open := CurrencyRate{ValidFrom: from}
+
+	require.False(t, closed.EffectiveAt(from.Add(-time.Second)))
+	require.True(t, closed.EffectiveAt(from))
+	require.True(t, closed.EffectiveAt(to.Add(-time.Second)))
+	require.False(t, closed.EffectiveAt(to))
+	require.True(t, open.EffectiveAt(to.Add(time.Hour*24*365)))
+}
+
+func TestCurrencyRate_Overlaps(t *testing.T) {
+	t.Parallel()
+	day := func(d int) *time.Time {
+		t := time.Date(2022, 11, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	tests := []struct {
+		name string
+		a, b CurrencyRate
+		want bool
+	}{
+		{
+			name: "different pairs never overlap",
+			a:    CurrencyRate{From: "USD", To: "BRL", ValidFrom: *day(1)},
+			b:    CurrencyRate{From: "BRL", To: "USD", ValidFrom: *day(1)},
+			want: false,
+		},
+		{
+			name: "adjacent windows",
+			a:    CurrencyRate{From: "USD", To: "BRL", ValidFrom: *day(1), ValidTo: day(2)},
+			b:    CurrencyRate{From: "USD", To: "BRL", ValidFrom: *day(2), ValidTo: day(3)},
+			want: false,
+		},
+		{
+			name: "intersecting windows",
+			a:    CurrencyRate{From: "USD", To: "BRL", ValidFrom: *day(1), ValidTo: day(3)},
+			b:    CurrencyRate{From: "USD", To: "BRL", ValidFrom: *day(2), ValidTo: day(4)},
+			want: true,
+		},
+		{
+			name: "open window overlaps future window",
+			a:    CurrencyRate{From: "USD", To: "BRL", ValidFrom: *day(1)},
+			b:    CurrencyRate{From: "USD", To: "BRL", ValidFrom: *day(10), ValidTo: day(11)},
+			want: true,
+		},
+		{
+			name: "future open window after closed window",
+			a:    CurrencyRate{From: "USD", To: "BRL", ValidFrom: *day(1), ValidTo: day(10)},
+			b:    CurrencyRate{From: "USD", To: "BRL", ValidFrom: *day(10)},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.a.Overlaps(tt.b))
+			require.Equal(t, tt.want, tt.b.Overlaps(tt.a))
+		})
+	}
+}
+
+func TestCurrencyRate_Supersedes(t *testing.T) {
+	t.Parallel()
+	day := func(d int) *time.Time {
+		t := time.Date(2022, 11, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	open := CurrencyRate{From: "USD", To: "BRL", ValidFrom: *day(1)}
+	tests := []struct {
+		name string
+		c, o CurrencyRate
+		want bool
+	}{
+		{name: "later window", c: CurrencyRate{From: "USD", To: "BRL", ValidFrom: *day(10)}, o: open, want: true},
+		{name: "later bounded window", c: CurrencyRate{From: "USD", To: "BRL", ValidFrom: *day(10), ValidTo: day(11)}, o: open, want: true},
+		{name: "same start", c: CurrencyRate{From: "USD", To: "BRL", ValidFrom: *day(1)}, o: open},
+		{name: "earlier window", c: CurrencyRate{From: "USD", To: "BRL", ValidFrom: *day(1)}, o: CurrencyRate{From: "USD", To: "BRL", ValidFrom: *day(10)}},
+		{name: "closed window", c: CurrencyRate{From: "USD", To: "BRL", ValidFrom: *day(2)}, o: CurrencyRate{From: "USD", To: "BRL", ValidFrom: *day(1), ValidTo: day(3)}},
+		{name: "other pair", c: CurrencyRate{From: "BRL", To: "USD", ValidFrom: *day(10)}, o: open},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, tt.c.Supersedes(tt.o))
+		})
+	}
+}
+
+func TestCurrencyRates_RateOf(t *testing.T) {
+	t.Parallel()
+	rates := CurrencyRates{
+		{From: "USD", To: "BRL", Rate: 5},
+		{From: "EUR", To: "USD", Rate: 1.25},
+	}
+	tests := []struct {
+		name      string
+		from, to  string
+		wantRate  float64
+		wantFound bool
+	}{
+		{name: "direct", from: "USD", to: "BRL", wantRate: 5, wantFound: true},
+		{name: "inverse", from: "USD", to: "EUR", wantRate: 0.8, wantFound: true},
+		{name: "missing", from: "BTC", to: "USD"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rate, found := rates.RateOf(tt.from, tt.to)
+			require.Equal(t, tt.wantFound, found)
+			require.InDelta(t, tt.wantRate, rate, 1e-9)
+		})
+	}
+}
@@ -1,16 +1,130 @@
 package core
 
-import "errors"
+// Kind classifies errors so each transport can map them to its own
+// status codes
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindUnauthenticated
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindUnprocessable
+	KindRateLimited
+	KindUpstream
+	KindUnavailable
+	KindTimeout
+	KindPrecondition
+)
+
+// ServerFault reports whether errors of the kind are failures of the
+// server or its dependencies, the equivalent of a 5xx status. Their
+// details are not exposed since they may come from the provider or
+// the db
+func (k Kind) ServerFault() bool {
+	switch k {
+	case KindInternal, KindUpstream, KindUnavailable, KindTimeout:
+		return true
+	}
+	return false
+}
+
+// Error is an expected failure with a stable machine readable code,
+// clients may rely on codes while messages can change
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
 
 var (
-	ErrInvalidFromCurrency = errors.New("invalid From currency")
-	ErrInvalidToCurrency   = errors.New("invalid To currency")
-	ErrAmountIsNotANumber  = errors.New("amount is not a number")
+	ErrInvalidFromCurrency = newError(KindInvalid, "invalid_from_currency", "invalid From currency")
+	ErrInvalidToCurrency   = newError(KindInvalid, "invalid_to_currency", "invalid To currency")
+	ErrAmountIsNotANumber  = newError(KindInvalid, "amount_not_a_number", "amount is not a number")
+	ErrAmbiguousAmount     = newError(KindInvalid, "ambiguous_amount", "amount reads differently in the Accept-Language locale, send the locale param or write it without grouping")
+	ErrTooManyConversions  = newError(KindInvalid, "too_many_conversions", "too many conversions requested at once")
 	// currency errors
-	ErrEmptySymbol      = errors.New("currency needs a symbol")
-	ErrSymbolMinLen     = errors.New("currency symbol has to have 3 or more characters")
-	ErrRateIsZero       = errors.New("currency convertion rate cannot be zero")
-	ErrCurrencyNotFound = errors.New("currency not found")
+	ErrEmptySymbol      = newError(KindInvalid, "symbol_required", "currency needs a symbol")
+	ErrSymbolMinLen     = newError(KindInvalid, "symbol_too_short", "currency symbol has to have 3 or more characters")
+	ErrRateIsZero       = newError(KindInvalid, "rate_zero", "currency convertion rate cannot be zero")
+	ErrCurrencyNotFound = newError(KindNotFound, "currency_not_found", "currency not found")
+	ErrUnknownCurrency  = newError(KindUnprocessable, "unknown_currency", "rate refers to a currency that is not registered")
+	// rate errors
+	ErrRateInvalidWindow     = newError(KindInvalid, "rate_invalid_window", "rate valid_to has to be after valid_from")
+	ErrRateOverlap           = newError(KindConflict, "rate_overlap", "rate validity window overlaps an existing rate for the same pair")
+	ErrRateRewritesPast      = newError(KindConflict, "rate_rewrites_past", "rate would close the current window in the past, valid_from cannot be before now")
+	ErrRateNotFound          = newError(KindNotFound, "rate_not_found", "currency rate not found")
+	ErrInvalidTimestamp      = newError(KindInvalid, "invalid_timestamp", "timestamp has to be RFC3339")
+	ErrRateDeviation         = newError(KindUnprocessable, "rate_deviation", "rate deviates too much from the market rate, send force with a force_reason to override")
+	ErrMarketRateUnavailable = newError(KindUnavailable, "market_rate_unavailable", "market rate is unavailable to validate the rate, send force with a force_reason to override")
+	ErrForceReasonRequired   = newError(KindInvalid, "force_reason_required", "force_reason is required when forcing a rate")
+	// proposal errors
+	ErrActorRequired     = newError(KindUnauthenticated, "actor_required", "identity of the requester is required")
+	ErrProposalNotFound  = newError(KindNotFound, "proposal_not_found", "rate proposal not found")
+	ErrProposalDecided   = newError(KindConflict, "proposal_decided", "rate proposal was already decided")
+	ErrSelfApproval      = newError(KindForbidden, "self_approval", "rate proposal has to be decided by a different identity than the proposer")
+	ErrInvalidRateAction = newError(KindInvalid, "invalid_rate_action", "rate proposal action is invalid")
+	// auth errors
+	ErrUnauthenticated = newError(KindUnauthenticated, "unauthenticated", "missing or invalid credentials")
+	ErrInvalidToken    = newError(KindUnauthenticated, "invalid_token", "invalid bearer token")
+	ErrForbidden       = newError(KindForbidden, "insufficient_scope", "credentials do not grant access to this resource")
+	ErrScopesRequired  = newError(KindInvalid, "scopes_required", "at least one scope is required")
+	ErrInvalidScope    = newError(KindInvalid, "invalid_scope", "scope is invalid")
+	ErrKeyNameRequired = newError(KindInvalid, "key_name_required", "api key needs a name")
+	ErrKeyNotFound     = newError(KindNotFound, "key_not_found", "api key not found")
+	// usage errors
+	ErrRateLimited   = newError(KindRateLimited, "rate_limited", "too many requests")
+	ErrQuotaExceeded = newError(KindRateLimited, "quota_exceeded", "monthly quota exceeded")
+	ErrInvalidPeriod = newError(KindInvalid, "invalid_period", "period has to be formatted as YYYY-MM")
+	// provider errors
+	ErrBudgetExhausted     = newError(KindUnavailable, "provider_budget_exhausted", "exchange provider monthly budget exhausted and no cached rate is available")
+	ErrProviderUnavailable = newError(KindUpstream, "provider_error", "exchange provider failed to answer")
+	ErrProviderTimeout     = newError(KindTimeout, "provider_timeout", "exchange provider did not answer in time")
+	// listing errors
+	ErrInvalidSort           = newError(KindInvalid, "invalid_sort", "sort has to be one of symbol, -symbol, description, -description")
+	ErrInvalidKind           = newError(KindInvalid, "invalid_kind", "kind has to be fiat or crypto")
+	ErrInvalidLimit          = newError(KindInvalid, "invalid_limit", "limit has to be between 1 and 200")
+	ErrInvalidCursor         = newError(KindInvalid, "invalid_cursor", "cursor is invalid or was issued for another sort")
+	ErrInvalidIncludeDeleted = newError(KindInvalid, "invalid_include_deleted", "include_deleted has to be true or false")
+	// conditional requests
+	ErrPreconditionFailed = newError(KindPrecondition, "precondition_failed", "resource changed since it was read, fetch it again before writing")
+	// streaming
+	ErrInvalidEventID = newError(KindInvalid, "invalid_event_id", "Last-Event-ID has to be the id of a received event")
+	ErrInvalidPair    = newError(KindInvalid, "invalid_pair", "pairs have to be a comma separated list of FROM/TO symbols, ex: USD/BRL,BTC/USD")
+	// alerts
+	ErrInvalidAlertCondition = newError(KindInvalid, "invalid_alert_condition", "condition has to be one of above, below, change")
+	ErrInvalidAlertThreshold = newError(KindInvalid, "invalid_alert_threshold", "threshold has to be greater than zero")
+	ErrInvalidAlertWindow    = newError(KindInvalid, "invalid_alert_window", "window is required by change alerts only and has to be a duration, ex: 1h")
+	ErrInvalidWebhookURL     = newError(KindInvalid, "invalid_webhook_url", "url has to be an absolute http or https url")
+	ErrWebhookURLNotPublic   = newError(KindInvalid, "webhook_url_not_public", "url cannot point to a loopback, link-local or private address")
+	ErrAlertNotFound         = newError(KindNotFound, "alert_not_found", "alert subscription not found")
+	// idempotency
+	ErrInvalidIdempotencyKey  = newError(KindInvalid, "invalid_idempotency_key", "Idempotency-Key has to have between 1 and 255 characters")
+	ErrIdempotencyKeyReused   = newError(KindUnprocessable, "idempotency_key_reused", "Idempotency-Key was already used with a different request")
+	ErrIdempotencyKeyInFlight = newError(KindConflict, "idempotency_key_in_flight", "a request with this Idempotency-Key is still being processed")
+	// exports
+	ErrInvalidExportFormat = newError(KindInvalid, "invalid_export_format", "format has to be csv or xlsx")
+	ErrInvalidLocale       = newError(KindInvalid, "invalid_locale", "locale has to be a BCP 47 language tag, ex: pt-BR")
+	ErrInvalidDecimals     = newError(KindInvalid, "invalid_decimals", "decimals has to be between 0 and 12")
+	ErrInvalidHistoryRange = newError(KindInvalid, "invalid_history_range", "since and until have to be RFC3339 and until has to be after since")
+	// imports
+	ErrInvalidImportFile  = newError(KindInvalid, "invalid_import_file", "import has to be a CSV file with a header of from, to, rate and optionally valid_from, valid_to, force, force_reason")
+	ErrEmptyImport        = newError(KindInvalid, "empty_import", "import has no rows")
+	ErrImportTooLarge     = newError(KindInvalid, "import_too_large", "import has more rows than allowed")
+	ErrInvalidImportFlags = newError(KindInvalid, "invalid_import_flags", "dry_run and strict have to be true or false")
+	ErrRateNotANumber     = newError(KindInvalid, "rate_not_a_number", "rate is not a number")
+	ErrInvalidForce       = newError(KindInvalid, "invalid_force", "force has to be true or false")
 	// general
-	ErrNotFound = errors.New("not found")
+	ErrNotFound    = newError(KindNotFound, "not_found", "not found")
+	ErrInvalidBody = newError(KindInvalid, "invalid_body", "request body is invalid")
 )
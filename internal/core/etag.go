@@ -0,0 +1,24 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// ETag is a strong validator of the JSON representation of v, the
+// modified time is hashed as well so a rewrite of the same content
+// is still seen as a new version
+func ETag(v interface{}, modified time.Time) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write(b)
+	if !modified.IsZero() {
+		h.Write([]byte(modified.UTC().Format(time.RFC3339Nano)))
+	}
+	return `"` + base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:16]) + `"`, nil
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"strings"
+	"time"
+)
+
+// RateEvent announces a rate change to the stream subscribers,
+// provider rates are always announced as updates
+type RateEvent struct {
+	ID     uint64       `json:"id"`
+	Action RateAction   `json:"action"`
+	Source string       `json:"source"`
+	Rate   CurrencyRate `json:"rate"`
+	At     time.Time    `json:"at"`
+}
+
+// Pair is the FROM/TO pair of the event
+func (e RateEvent) Pair() string {
+	return Pair(e.Rate.From, e.Rate.To)
+}
+
+func Pair(from, to string) string {
+	return from + "/" + to
+}
+
+// ParsePairs reads a comma separated list of FROM/TO pairs
+func ParsePairs(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var pairs []string
+	for _, p := range strings.Split(s, ",") {
+		from, to, ok := strings.Cut(strings.TrimSpace(p), "/")
+		if !ok || len(from) < 3 || len(to) < 3 {
+			return nil, ErrInvalidPair
+		}
+		pairs = append(pairs, Pair(strings.ToUpper(from), strings.ToUpper(to)))
+	}
+	return pairs, nil
+}
@@ -0,0 +1,36 @@
+package core
+
+import (
+	"net/http"
+	"time"
+)
+
+// MaxIdempotencyKeyLen is the longest Idempotency-Key accepted
+const MaxIdempotencyKeyLen = 255
+
+// IdempotentRequest is a write sent with an Idempotency-Key, Status
+// is zero while the first attempt is running and afterwards the
+// response is kept to be replayed to retries until ExpiresAt
+type IdempotentRequest struct {
+	Client      string
+	Key         string
+	Method      string
+	Path        string
+	Fingerprint string
+	Status      int
+	Header      http.Header
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+func (r IdempotentRequest) Check() error {
+	if r.Key == "" || len(r.Key) > MaxIdempotencyKeyLen {
+		return ErrInvalidIdempotencyKey
+	}
+	return nil
+}
+
+// Completed reports whether the response is stored
+func (r IdempotentRequest) Completed() bool {
+	return r.Status != 0
+}
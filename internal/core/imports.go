@@ -0,0 +1,174 @@
+package core
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Columns of rate imports, the layout of rate exports is accepted as is
+const (
+	ImportColumnFrom        = "from"
+	ImportColumnTo          = "to"
+	ImportColumnRate        = "rate"
+	ImportColumnValidFrom   = "valid_from"
+	ImportColumnValidTo     = "valid_to"
+	ImportColumnForce       = "force"
+	ImportColumnForceReason = "force_reason"
+)
+
+var importColumns = map[string]bool{
+	ImportColumnFrom:        true,
+	ImportColumnTo:          true,
+	ImportColumnRate:        true,
+	ImportColumnValidFrom:   true,
+	ImportColumnValidTo:     true,
+	ImportColumnForce:       true,
+	ImportColumnForceReason: true,
+}
+
+type ImportStatus string
+
+const (
+	ImportValid    ImportStatus = "valid"
+	ImportInvalid  ImportStatus = "invalid"
+	ImportProposed ImportStatus = "proposed"
+)
+
+// ImportOptions of a rate import, DryRun only validates the rows and
+// Strict proposes nothing when a row is invalid
+type ImportOptions struct {
+	DryRun bool
+	Strict bool
+}
+
+// RateImportRow is a row of a rate import, Err is set when the row
+// could not be parsed. Line is the line of the row in the file
+type RateImportRow struct {
+	Line     int
+	Rate     CurrencyRate
+	Override Override
+	Err      error
+}
+
+// ImportReport is the outcome of every row of a rate import
+type ImportReport struct {
+	DryRun   bool              `json:"dry_run"`
+	Strict   bool              `json:"strict"`
+	Valid    int               `json:"valid"`
+	Invalid  int               `json:"invalid"`
+	Proposed int               `json:"proposed"`
+	Rows     []ImportRowReport `json:"rows"`
+}
+
+type ImportRowReport struct {
+	Line       int          `json:"line"`
+	From       string       `json:"from"`
+	To         string       `json:"to"`
+	Status     ImportStatus `json:"status"`
+	Code       string       `json:"code,omitempty"`
+	Error      string       `json:"error,omitempty"`
+	ProposalID string       `json:"proposal_id,omitempty"`
+}
+
+// ParseRateImport reads the rows of a CSV rate import, the header
+// names the columns in any order. Rows with invalid fields are
+// returned with their Err set so they can be reported, while an
+// invalid header or a malformed file fails the whole import
+func ParseRateImport(r io.Reader) ([]RateImportRow, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrEmptyImport
+	}
+	if err != nil {
+		return nil, importError(err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		// spreadsheets may start the file with a byte order mark
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, dup := columns[name]; dup || !importColumns[name] {
+			return nil, ErrInvalidImportFile
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{ImportColumnFrom, ImportColumnTo, ImportColumnRate} {
+		if _, ok := columns[required]; !ok {
+			return nil, ErrInvalidImportFile
+		}
+	}
+
+	rows := []RateImportRow{}
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, importError(err)
+		}
+		line, _ := cr.FieldPos(0)
+		if err != nil {
+			rows = append(rows, RateImportRow{Line: line, Err: ErrInvalidImportFile})
+			continue
+		}
+		rows = append(rows, parseImportRow(line, columns, record))
+	}
+	if len(rows) == 0 {
+		return nil, ErrEmptyImport
+	}
+	return rows, nil
+}
+
+func parseImportRow(line int, columns map[string]int, record []string) (row RateImportRow) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	row.Line = line
+	row.Rate.From = field(ImportColumnFrom)
+	row.Rate.To = field(ImportColumnTo)
+	row.Override.Reason = field(ImportColumnForceReason)
+
+	var err error
+	if row.Rate.Rate, err = strconv.ParseFloat(field(ImportColumnRate), 64); err != nil {
+		row.Err = ErrRateNotANumber
+		return
+	}
+	if v := field(ImportColumnValidFrom); v != "" {
+		if row.Rate.ValidFrom, err = time.Parse(time.RFC3339, v); err != nil {
+			row.Err = ErrInvalidTimestamp
+			return
+		}
+	}
+	if v := field(ImportColumnValidTo); v != "" {
+		validTo, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			row.Err = ErrInvalidTimestamp
+			return
+		}
+		row.Rate.ValidTo = &validTo
+	}
+	if v := field(ImportColumnForce); v != "" {
+		if row.Override.Force, err = strconv.ParseBool(v); err != nil {
+			row.Err = ErrInvalidForce
+		}
+	}
+	return
+}
+
+// importError keeps the errors of the reader, as a body over the size
+// limit, and reports malformed CSV as an invalid file
+func importError(err error) error {
+	var pe *csv.ParseError
+	if errors.As(err, &pe) {
+		return ErrInvalidImportFile
+	}
+	return err
+}
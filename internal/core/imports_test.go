@@ -0,0 +1,67 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateImport(t *testing.T) {
+	t.Parallel()
+	validFrom := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	validTo := validFrom.AddDate(0, 1, 0)
+	tests := []struct {
+		name    string
+		file    string
+		want    []RateImportRow
+		wantErr error
+	}{
+		{
+			name: "export layout",
+			file: "\ufefffrom,to,rate,valid_from,valid_to\n" +
+				"USD,BRL,5.25,2026-01-01T00:00:00Z,2026-02-01T00:00:00Z\n" +
+				"EUR,BRL,6,,\n",
+			want: []RateImportRow{
+				{Line: 2, Rate: CurrencyRate{From: "USD", To: "BRL", Rate: 5.25, ValidFrom: validFrom, ValidTo: &validTo}},
+				{Line: 3, Rate: CurrencyRate{From: "EUR", To: "BRL", Rate: 6}},
+			},
+		},
+		{
+			name: "columns in any order",
+			file: "Rate, force , force_reason,To,From\n52,true,devaluation,BRL,USD\n",
+			want: []RateImportRow{
+				{Line: 2, Rate: CurrencyRate{From: "USD", To: "BRL", Rate: 52}, Override: Override{Force: true, Reason: "devaluation"}},
+			},
+		},
+		{
+			name: "invalid rows are kept",
+			file: "from,to,rate,valid_from,force\n" +
+				"USD,BRL,five,,\n" +
+				"USD,BRL,5,yesterday,\n" +
+				"USD,BRL,5,,maybe\n" +
+				"USD,BRL\n",
+			want: []RateImportRow{
+				{Line: 2, Rate: CurrencyRate{From: "USD", To: "BRL"}, Err: ErrRateNotANumber},
+				{Line: 3, Rate: CurrencyRate{From: "USD", To: "BRL", Rate: 5}, Err: ErrInvalidTimestamp},
+				{Line: 4, Rate: CurrencyRate{From: "USD", To: "BRL", Rate: 5}, Err: ErrInvalidForce},
+				{Line: 5, Err: ErrInvalidImportFile},
+			},
+		},
+		{name: "empty file", file: "", wantErr: ErrEmptyImport},
+		{name: "header only", file: "from,to,rate\n", wantErr: ErrEmptyImport},
+		{name: "missing column", file: "from,to\nUSD,BRL\n", wantErr: ErrInvalidImportFile},
+		{name: "unknown column", file: "from,to,rate,valid_form\nUSD,BRL,5,\n", wantErr: ErrInvalidImportFile},
+		{name: "malformed file", file: "from,to,rate\n\"USD,BRL,5\n", wantErr: ErrInvalidImportFile},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rows, err := ParseRateImport(strings.NewReader(tt.file))
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.want, rows)
+		})
+	}
+}
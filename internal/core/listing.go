@@ -0,0 +1,93 @@
+package core
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// Listing limits
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// CurrencySorts are the accepted sort keys, a leading - sorts descending
+var CurrencySorts = map[string]bool{
+	"symbol":       true,
+	"-symbol":      true,
+	"description":  true,
+	"-description": true,
+}
+
+// CurrencyFilter selects a page of currencies, Cursor is the opaque
+// value of the previous page Next
+type CurrencyFilter struct {
+	Q              string
+	Source         string
+	Kind           string
+	IncludeDeleted bool
+	Sort           string
+	Limit          int
+	Cursor         string
+}
+
+// Check validates the filter and fills its defaults
+func (f *CurrencyFilter) Check() error {
+	if f.Sort == "" {
+		f.Sort = "symbol"
+	}
+	if !CurrencySorts[f.Sort] {
+		return ErrInvalidSort
+	}
+	if f.Kind != "" && f.Kind != CurrencyFiat && f.Kind != CurrencyCrypto {
+		return ErrInvalidKind
+	}
+	if f.Limit == 0 {
+		f.Limit = DefaultPageSize
+	}
+	if f.Limit < 0 || f.Limit > MaxPageSize {
+		return ErrInvalidLimit
+	}
+	if f.Cursor != "" {
+		c, err := DecodeCursor(f.Cursor)
+		if err != nil || c.Sort != f.Sort {
+			return ErrInvalidCursor
+		}
+	}
+	return nil
+}
+
+// SortField is the column sorted by and whether it is descending
+func (f CurrencyFilter) SortField() (string, bool) {
+	return strings.TrimPrefix(f.Sort, "-"), strings.HasPrefix(f.Sort, "-")
+}
+
+// Cursor points after the last item of a page, Value is the sort
+// key of the item and Symbol breaks ties
+type Cursor struct {
+	Sort   string `json:"o"`
+	Value  string `json:"v"`
+	Symbol string `json:"s"`
+}
+
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	c := Cursor{}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
+
+// CurrencyPage is a page of currencies, Next is empty on the last one
+type CurrencyPage struct {
+	Currencies Currencies
+	Next       string
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyFilter_Check(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		filter        CurrencyFilter
+		want          CurrencyFilter
+		wantErrFn     require.ErrorAssertionFunc
+		wantErrEquals error
+	}{
+		{
+			name:      "defaults",
+			filter:    CurrencyFilter{},
+			want:      CurrencyFilter{Sort: "symbol", Limit: DefaultPageSize},
+			wantErrFn: require.NoError,
+		},
+		{
+			name:          "unknown sort",
+			filter:        CurrencyFilter{Sort: "source"},
+			wantErrFn:     require.Error,
+			wantErrEquals: ErrInvalidSort,
+		},
+		{
+			name:          "unknown kind",
+			filter:        CurrencyFilter{Kind: "metal"},
+			wantErrFn:     require.Error,
+			wantErrEquals: ErrInvalidKind,
+		},
+		{
+			name:          "limit too big",
+			filter:        CurrencyFilter{Limit: MaxPageSize + 1},
+			wantErrFn:     require.Error,
+			wantErrEquals: ErrInvalidLimit,
+		},
+		{
+			name:          "malformed cursor",
+			filter:        CurrencyFilter{Cursor: "not a cursor"},
+			wantErrFn:     require.Error,
+			wantErrEquals: ErrInvalidCursor,
+		},
+		{
+			name:          "cursor of another sort",
+			filter:        CurrencyFilter{Sort: "-symbol", Cursor: Cursor{Sort: "symbol", Value: "BRL", Symbol: "BRL"}.Encode()},
+			wantErrFn:     require.Error,
+			wantErrEquals: ErrInvalidCursor,
+		},
+		{
+			name:   "cursor of the same sort",
+			filter: CurrencyFilter{Sort: "-description", Kind: CurrencyCrypto, Limit: 10, Cursor: Cursor{Sort: "-description", Value: "Bitcoin", Symbol: "BTC"}.Encode()},
+			want: CurrencyFilter{Sort: "-description", Kind: CurrencyCrypto, Limit: 10,
+				Cursor: Cursor{Sort: "-description", Value: "Bitcoin", Symbol: "BTC"}.Encode()},
+			wantErrFn: require.NoError,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.filter.Check()
+			tt.wantErrFn(t, err)
+			if err != nil {
+				require.Equal(t, tt.wantErrEquals, err)
+				return
+			}
+			require.Equal(t, tt.want, tt.filter)
+		})
+	}
+}
+
+func TestCursor_roundTrip(t *testing.T) {
+	t.Parallel()
+	c := Cursor{Sort: "description", Value: "Euro", Symbol: "EUR"}
+	got, err := DecodeCursor(c.Encode())
+	require.NoError(t, err)
+	require.Equal(t, c, got)
+}
@@ -0,0 +1,250 @@
+package core
+
+import (
+	_ "embed"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// cldrNumbers is a subset of the CLDR number symbols, currency
+// patterns and currency data of common locales
+//
+//go:embed cldr_numbers.json
+var cldrNumbers []byte
+
+var numberData = mustNumberData()
+
+type cldrData struct {
+	Currencies map[string]cldrCurrency `json:"currencies"`
+	Locales    map[string]cldrLocale   `json:"locales"`
+}
+
+type cldrCurrency struct {
+	Symbol string `json:"symbol"`
+	Digits int    `json:"digits"`
+}
+
+// cldrLocale symbols override the currency symbols of its parents, a
+// pattern such as "#,##0.00 ¤" places the symbol ¤ around the number
+type cldrLocale struct {
+	Decimal string            `json:"decimal"`
+	Group   string            `json:"group"`
+	Pattern string            `json:"pattern"`
+	Symbols map[string]string `json:"symbols"`
+}
+
+func mustNumberData() (d cldrData) {
+	if err := json.Unmarshal(cldrNumbers, &d); err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// groupAliases are the group separators people type for the ones of
+// CLDR, which are hard to reach on a keyboard
+var groupAliases = map[string][]string{
+	"\u00a0": {" ", "\u202f"},
+	"\u202f": {" ", "\u00a0"},
+	"’":      {"'"},
+}
+
+// NumberFormat reads and writes amounts the way people of a locale do
+type NumberFormat struct {
+	locale language.Tag
+	// chain goes from the locale to the root locale
+	chain          []cldrLocale
+	decimal, group string
+	prefix, suffix string
+}
+
+// ResolveNumberFormat picks the format of the locale param, or of the
+// first language of Accept-Language with bundled data when there is
+// no param. It is nil when neither asks for a supported locale.
+// Locales without data of their own use the root locale
+func ResolveNumberFormat(locale, acceptLanguage string) (*NumberFormat, error) {
+	if locale != "" {
+		tag, err := language.Parse(locale)
+		if err != nil {
+			return nil, ErrInvalidLocale
+		}
+		f, _ := newNumberFormat(tag)
+		return f, nil
+	}
+	if acceptLanguage == "" {
+		return nil, nil
+	}
+	// a malformed header is ignored as any other optional header
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return nil, nil
+	}
+	for _, tag := range tags {
+		if f, ok := newNumberFormat(tag); ok {
+			return f, nil
+		}
+	}
+	return nil, nil
+}
+
+// newNumberFormat reports whether there is data for tag besides the
+// root locale
+func newNumberFormat(tag language.Tag) (*NumberFormat, bool) {
+	// extensions such as -u-nu- have no bundled data
+	base, script, region := tag.Raw()
+	tag, _ = language.Compose(base, script, region)
+	f := &NumberFormat{locale: tag}
+	for t := tag; ; t = t.Parent() {
+		if d, ok := numberData.Locales[t.String()]; ok {
+			f.chain = append(f.chain, d)
+		}
+		if t == language.Und {
+			break
+		}
+	}
+	d := f.chain[0]
+	f.decimal, f.group = d.Decimal, d.Group
+	start := strings.IndexAny(d.Pattern, "#0")
+	end := strings.LastIndexAny(d.Pattern, "#0") + 1
+	f.prefix, f.suffix = d.Pattern[:start], d.Pattern[end:]
+	return f, len(f.chain) > 1
+}
+
+// Locale is the tag the format was resolved for
+func (f *NumberFormat) Locale() language.Tag {
+	return f.locale
+}
+
+// ParseAmount reads an amount with the separators of the locale, the
+// grouping has to be of thousands. The plain 1234.56 is read as well
+// when it is not a valid localized amount, and it is the only format
+// of a nil NumberFormat
+func (f *NumberFormat) ParseAmount(s string) (float64, error) {
+	if f != nil {
+		if v, ok := f.parse(strings.TrimSpace(s)); ok {
+			return v, nil
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, ErrAmountIsNotANumber
+	}
+	return v, nil
+}
+
+// ParsePlainAmount reads the plain 1234.56, f is only a guess of the
+// locale of the client such as its Accept-Language. Amounts that f
+// reads as another number, as 1.234 in pt-BR, are ambiguous
+func (f *NumberFormat) ParsePlainAmount(s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, ErrAmountIsNotANumber
+	}
+	if f != nil {
+		if l, ok := f.parse(strings.TrimSpace(s)); ok && l != v {
+			return 0, ErrAmbiguousAmount
+		}
+	}
+	return v, nil
+}
+
+func (f *NumberFormat) parse(s string) (float64, bool) {
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+	for _, alias := range groupAliases[f.group] {
+		s = strings.ReplaceAll(s, alias, f.group)
+	}
+	integer, fraction, hasFraction := strings.Cut(s, f.decimal)
+	if hasFraction && (fraction == "" || !isDigits(fraction)) {
+		return 0, false
+	}
+	if integer == "" && hasFraction {
+		integer = "0"
+	}
+	groups := strings.Split(integer, f.group)
+	for i, g := range groups {
+		if !isDigits(g) || (i > 0 && len(g) != 3) || (len(groups) > 1 && len(g) > 3) {
+			return 0, false
+		}
+	}
+	plain := sign + strings.Join(groups, "")
+	if hasFraction {
+		plain += "." + fraction
+	}
+	v, err := strconv.ParseFloat(plain, 64)
+	return v, err == nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatAmount writes v rounded to the digits of the currency, grouped
+// by thousands and with the symbol where the locale places it.
+// Currencies without data get 2 digits and their code as the symbol
+func (f *NumberFormat) FormatAmount(v float64, currency string) string {
+	digits, symbol := 2, currency
+	if c, ok := numberData.Currencies[currency]; ok {
+		digits, symbol = c.Digits, c.Symbol
+	}
+	for _, d := range f.chain {
+		if s, ok := d.Symbols[currency]; ok {
+			symbol = s
+			break
+		}
+	}
+	plain := strconv.FormatFloat(math.Abs(v), 'f', digits, 64)
+	integer, fraction, _ := strings.Cut(plain, ".")
+
+	b := strings.Builder{}
+	// a negative amount rounded to zero has no sign
+	if v < 0 && strings.Trim(plain, "0.") != "" {
+		b.WriteByte('-')
+	}
+	b.WriteString(strings.ReplaceAll(f.prefix, "¤", symbol))
+	for i, r := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(f.group)
+		}
+		b.WriteRune(r)
+	}
+	if fraction != "" {
+		b.WriteString(f.decimal + fraction)
+	}
+	b.WriteString(strings.ReplaceAll(f.suffix, "¤", symbol))
+	return b.String()
+}
+
+// FormattedConversion are the amounts of a conversion written for the
+// people of a locale
+type FormattedConversion struct {
+	Locale          string `json:"locale"`
+	OriginalAmount  string `json:"original_amount"`
+	ConvertedAmount string `json:"converted_amount"`
+}
+
+// FormatConversion is nil for a nil NumberFormat, so conversions only
+// have a formatted block when a locale was asked for
+func (f *NumberFormat) FormatConversion(c ConversionResp) *FormattedConversion {
+	if f == nil {
+		return nil
+	}
+	return &FormattedConversion{
+		Locale:          f.locale.String(),
+		OriginalAmount:  f.FormatAmount(c.OriginalAmount, c.From),
+		ConvertedAmount: f.FormatAmount(c.ConvertedAmount, c.To),
+	}
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ResolveNumberFormat(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		locale         string
+		acceptLanguage string
+		wantLocale     string
+		wantNil        bool
+		wantErr        error
+	}{
+		{name: "nothing asked", wantNil: true},
+		{name: "param", locale: "pt-BR", wantLocale: "pt-BR"},
+		{name: "param wins over the header", locale: "fr", acceptLanguage: "de", wantLocale: "fr"},
+		{name: "param extensions are dropped", locale: "de-CH-u-nu-latn", wantLocale: "de-CH"},
+		{name: "param without data uses root", locale: "sw", wantLocale: "sw"},
+		{name: "invalid param", locale: "not a locale", wantErr: ErrInvalidLocale},
+		{name: "header", acceptLanguage: "es-AR,es;q=0.9", wantLocale: "es-AR"},
+		{name: "header skips languages without data", acceptLanguage: "sw, ja;q=0.5", wantLocale: "ja"},
+		{name: "header without data", acceptLanguage: "sw", wantNil: true},
+		{name: "malformed header", acceptLanguage: "en;q=x;;", wantNil: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f, err := ResolveNumberFormat(tt.locale, tt.acceptLanguage)
+			require.Equal(t, tt.wantErr, err)
+			if tt.wantErr != nil || tt.wantNil {
+				require.Nil(t, f)
+				return
+			}
+			require.Equal(t, tt.wantLocale, f.Locale().String())
+		})
+	}
+}
+
+func TestNumberFormat_ParseAmount(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		locale  string
+		amount  string
+		want    float64
+		wantErr error
+	}{
+		{name: "no locale", amount: "1234.56", want: 1234.56},
+		{name: "no locale grouped", amount: "1,234.56", wantErr: ErrAmountIsNotANumber},
+		{name: "pt-BR", locale: "pt-BR", amount: "1.234,56", want: 1234.56},
+		{name: "pt-BR millions", locale: "pt-BR", amount: "-1.234.567,5", want: -1234567.5},
+		{name: "pt-BR ungrouped", locale: "pt-BR", amount: "1234,56", want: 1234.56},
+		{name: "pt-BR plain", locale: "pt-BR", amount: "1234.56", want: 1234.56},
+		{name: "pt-BR grouped thousands", locale: "pt-BR", amount: "1.234", want: 1234},
+		{name: "pt-BR plain fraction", locale: "pt-BR", amount: "1.5", want: 1.5},
+		{name: "pt-BR bad grouping", locale: "pt-BR", amount: "1.23,4", wantErr: ErrAmountIsNotANumber},
+		{name: "en", locale: "en", amount: "1,234.56", want: 1234.56},
+		{name: "en decimal comma", locale: "en", amount: "1,5", wantErr: ErrAmountIsNotANumber},
+		{name: "fr typed space", locale: "fr", amount: "1 234,5", want: 1234.5},
+		{name: "fr narrow no-break space", locale: "fr", amount: "1\u202f234,5", want: 1234.5},
+		{name: "de-CH apostrophe", locale: "de-CH", amount: "1'234.5", want: 1234.5},
+		{name: "leading decimal", locale: "de", amount: ",5", want: 0.5},
+		{name: "not a number", locale: "de", amount: "abc", wantErr: ErrAmountIsNotANumber},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f, err := ResolveNumberFormat(tt.locale, "")
+			require.NoError(t, err)
+			got, err := f.ParseAmount(tt.amount)
+			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNumberFormat_ParsePlainAmount(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		locale  string
+		amount  string
+		want    float64
+		wantErr error
+	}{
+		{name: "no locale", amount: "1.234", want: 1.234},
+		{name: "pt-BR fraction", locale: "pt-BR", amount: "1.5", want: 1.5},
+		{name: "pt-BR integer", locale: "pt-BR", amount: "1234", want: 1234},
+		{name: "pt-BR thousands", locale: "pt-BR", amount: "1.234", wantErr: ErrAmbiguousAmount},
+		{name: "de negative thousands", locale: "de", amount: "-12.500", wantErr: ErrAmbiguousAmount},
+		{name: "en thousands", locale: "en", amount: "1.234", want: 1.234},
+		{name: "pt-BR decimal comma", locale: "pt-BR", amount: "1,5", wantErr: ErrAmountIsNotANumber},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f, err := ResolveNumberFormat("", tt.locale)
+			require.NoError(t, err)
+			got, err := f.ParsePlainAmount(tt.amount)
+			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNumberFormat_FormatAmount(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		locale   string
+		amount   float64
+		currency string
+		want     string
+	}{
+		{name: "en", locale: "en", amount: 1234567.891, currency: "USD", want: "$1,234,567.89"},
+		{name: "en-GB other symbol", locale: "en-GB", amount: 10, currency: "USD", want: "US$10.00"},
+		{name: "pt-BR prefix", locale: "pt-BR", amount: 1234.5, currency: "BRL", want: "R$\u00a01.234,50"},
+		{name: "de suffix", locale: "de", amount: 1234.5, currency: "EUR", want: "1.234,50\u00a0€"},
+		{name: "fr narrow group", locale: "fr", amount: 1234.5, currency: "USD", want: "1\u202f234,50\u00a0$US"},
+		{name: "ja no digits", locale: "ja", amount: 1234.5, currency: "JPY", want: "￥1,234"},
+		{name: "crypto digits", locale: "en", amount: 0.000123456789, currency: "BTC", want: "BTC0.00012346"},
+		{name: "unknown currency", locale: "pt-BR", amount: 5, currency: "XYZ", want: "XYZ\u00a05,00"},
+		{name: "root", locale: "sw", amount: 1234, currency: "EUR", want: "€\u00a01,234.00"},
+		{name: "negative", locale: "pt-BR", amount: -2.5, currency: "BRL", want: "-R$\u00a02,50"},
+		{name: "negative rounded to zero", locale: "en", amount: -0.001, currency: "USD", want: "$0.00"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f, err := ResolveNumberFormat(tt.locale, "")
+			require.NoError(t, err)
+			require.Equal(t, tt.want, f.FormatAmount(tt.amount, tt.currency))
+		})
+	}
+}
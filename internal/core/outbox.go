@@ -0,0 +1,54 @@
+package core
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+// Domain events published through the outbox
+const (
+	EventCurrencyCreated     EventType = "currency.created"
+	EventCurrencyUpdated     EventType = "currency.updated"
+	EventCurrencyDeleted     EventType = "currency.deleted"
+	EventRateCreated         EventType = "rate.created"
+	EventRateUpdated         EventType = "rate.updated"
+	EventRateDeleted         EventType = "rate.deleted"
+	EventConversionCompleted EventType = "conversion.completed"
+)
+
+// RateEventTypes maps rate actions into their domain event
+var RateEventTypes = map[RateAction]EventType{
+	RateActionCreate: EventRateCreated,
+	RateActionUpdate: EventRateUpdated,
+	RateActionDelete: EventRateDeleted,
+}
+
+type OutboxEvents []OutboxEvent
+
+// OutboxEvent is a domain event stored with the change it announces,
+// it is delivered at least once so consumers deduplicate by ID. Key
+// identifies the entity, ex: the currency symbol or the FROM/TO pair
+type OutboxEvent struct {
+	ID         string          `json:"id"`
+	Type       EventType       `json:"type"`
+	Key        string          `json:"key"`
+	Data       json.RawMessage `json:"data"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// NewOutboxEvent encodes data as the payload of the event
+func NewOutboxEvent(typ EventType, key string, data interface{}) (OutboxEvent, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+	return OutboxEvent{Type: typ, Key: key, Data: b}, nil
+}
+
+// ConversionEvent is the payload of conversion.completed
+type ConversionEvent struct {
+	ConversionResp
+	At time.Time `json:"at"`
+}
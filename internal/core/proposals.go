@@ -0,0 +1,101 @@
+package core
+
+import "time"
+
+type RateAction string
+
+const (
+	RateActionCreate RateAction = "create"
+	RateActionUpdate RateAction = "update"
+	RateActionDelete RateAction = "delete"
+)
+
+type ProposalStatus string
+
+const (
+	ProposalPending  ProposalStatus = "pending"
+	ProposalApproved ProposalStatus = "approved"
+	ProposalRejected ProposalStatus = "rejected"
+)
+
+// Override bypasses the market deviation guard of manual rates,
+// the reason is recorded with the change
+type Override struct {
+	Force  bool   `json:"force"`
+	Reason string `json:"force_reason,omitempty"`
+}
+
+func (o Override) Check() error {
+	if o.Force && o.Reason == "" {
+		return ErrForceReasonRequired
+	}
+	return nil
+}
+
+type RateProposals []RateProposal
+
+// RateProposal is a manual rate change waiting for a second
+// person (the checker) to approve it before it is applied
+type RateProposal struct {
+	ID         string         `json:"id"`
+	Action     RateAction     `json:"action"`
+	Rate       CurrencyRate   `json:"rate"`
+	Override   Override       `json:"override"`
+	Status     ProposalStatus `json:"status"`
+	ProposedBy string         `json:"proposed_by"`
+	DecidedBy  string         `json:"decided_by,omitempty"`
+	Reason     string         `json:"reason,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	DecidedAt  *time.Time     `json:"decided_at,omitempty"`
+	// RatesVersion is the schedule the change was proposed over, it
+	// is only approved while the schedule is the same. Empty when the
+	// proposal was made without If-Match
+	RatesVersion string `json:"-"`
+}
+
+// CanBeDecidedBy ensures the proposal is still pending and that
+// the checker is not the same identity as the maker
+func (p RateProposal) CanBeDecidedBy(actor string) error {
+	if actor == "" {
+		return ErrActorRequired
+	}
+	if p.Status != ProposalPending {
+		return ErrProposalDecided
+	}
+	if p.ProposedBy == actor {
+		return ErrSelfApproval
+	}
+	return nil
+}
+
+type AuditEvent string
+
+const (
+	AuditProposed AuditEvent = "proposed"
+	AuditApproved AuditEvent = "approved"
+	AuditRejected AuditEvent = "rejected"
+)
+
+// RateAudit records every step of a rate proposal
+type RateAudit struct {
+	ProposalID string       `json:"proposal_id"`
+	Event      AuditEvent   `json:"event"`
+	Actor      string       `json:"actor"`
+	Action     RateAction   `json:"action"`
+	Rate       CurrencyRate `json:"rate"`
+	Override   Override     `json:"override"`
+	Reason     string       `json:"reason,omitempty"`
+}
+
+// Audit builds the audit record of an event on the proposal
+func (p RateProposal) Audit(event AuditEvent, actor, reason string) RateAudit {
+	return RateAudit{
+		ProposalID: p.ID,
+		Event:      event,
+		Actor:      actor,
+		Action:     p.Action,
+		Rate:       p.Rate,
+		Override:   p.Override,
+		Reason:     reason,
+	}
+}
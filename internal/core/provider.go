@@ -0,0 +1,11 @@
+package core
+
+// ProviderBudget is the monthly call budget of an exchange provider
+type ProviderBudget struct {
+	Provider  string `json:"provider"`
+	Period    string `json:"period"`
+	Used      int64  `json:"used"`
+	Limit     int64  `json:"limit"`
+	Remaining int64  `json:"remaining"`
+	Exhausted bool   `json:"exhausted"`
+}
@@ -0,0 +1,21 @@
+package core
+
+import "time"
+
+// UsagePeriodLayout is the layout of monthly usage periods
+const UsagePeriodLayout = "2006-01"
+
+type Usages []Usage
+
+// Usage is the amount of requests a client made in a month
+type Usage struct {
+	Client string `json:"client"`
+	Period string `json:"period"`
+	Count  int64  `json:"count"`
+}
+
+// UsagePeriod is the month t belongs to, in UTC
+func UsagePeriod(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
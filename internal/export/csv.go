@@ -0,0 +1,94 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+type csvWriter struct {
+	w       *csv.Writer
+	printer *message.Printer
+	opts    Options
+	record  []string
+}
+
+// newCSV separates fields with semicolons when the locale uses a
+// decimal comma, as spreadsheets of those locales expect
+func newCSV(w io.Writer, columns []string, opts Options) (*csvWriter, error) {
+	c := &csvWriter{w: csv.NewWriter(w), opts: opts, record: make([]string, len(columns))}
+	if opts.Locale != language.Und {
+		c.printer = message.NewPrinter(opts.Locale)
+		if strings.Contains(c.number(1.5), ",") {
+			c.w.Comma = ';'
+		}
+	}
+	return c, c.w.Write(columns)
+}
+
+func (c *csvWriter) Write(row []interface{}) error {
+	c.record = c.record[:0]
+	for _, v := range row {
+		c.record = append(c.record, c.field(v))
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) field(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return c.number(v)
+	case time.Time, *time.Time:
+		if t, ok := timeOf(v); ok {
+			return t.Format(time.RFC3339)
+		}
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func (c *csvWriter) number(v float64) string {
+	decimals := c.opts.Decimals
+	if decimals < 0 {
+		// the shortest representation avoids printing float noise
+		plain := strconv.FormatFloat(v, 'f', -1, 64)
+		if c.printer == nil {
+			return plain
+		}
+		decimals = 0
+		if i := strings.IndexByte(plain, '.'); i >= 0 {
+			decimals = len(plain) - i - 1
+		}
+		if decimals > MaxDecimals {
+			decimals = MaxDecimals
+		}
+	}
+	if c.printer == nil {
+		return strconv.FormatFloat(v, 'f', decimals, 64)
+	}
+	return c.printer.Sprint(number.Decimal(v,
+		number.MinFractionDigits(decimals), number.MaxFractionDigits(decimals)))
+}
+
+func parseDecimals(s string) (int, error) {
+	d, err := strconv.Atoi(s)
+	if err != nil || d < 0 || d > MaxDecimals {
+		return 0, core.ErrInvalidDecimals
+	}
+	return d, nil
+}
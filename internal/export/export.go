@@ -0,0 +1,105 @@
+// Package export writes tables as CSV or XLSX, rows are written as
+// they are produced so large exports are not held in memory
+package export
+
+import (
+	"io"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"golang.org/x/text/language"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// Media types of the formats
+const (
+	MIMECSV  = "text/csv"
+	MIMEXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// MaxDecimals is the most fraction digits that can be requested
+const MaxDecimals = 12
+
+// ParseFormat parses the format of the format query param
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatCSV, FormatXLSX:
+		return f, nil
+	}
+	return "", core.ErrInvalidExportFormat
+}
+
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return MIMEXLSX
+	}
+	return MIMECSV + "; charset=utf-8"
+}
+
+// Options control how numbers are written. Locale uses the grouping
+// and decimal separators of the language in CSV files, numbers are
+// plain when it is undefined. XLSX cells are numeric so spreadsheets
+// apply the locale of the reader. Decimals fixes the fraction digits,
+// numbers keep their shortest exact representation when negative
+type Options struct {
+	Locale   language.Tag
+	Decimals int
+}
+
+// ParseOptions parses the locale and decimals query params, both
+// are optional
+func ParseOptions(locale, decimals string) (Options, error) {
+	opts := Options{Locale: language.Und, Decimals: -1}
+	if locale != "" {
+		tag, err := language.Parse(locale)
+		if err != nil {
+			return Options{}, core.ErrInvalidLocale
+		}
+		opts.Locale = tag
+	}
+	if decimals != "" {
+		d, err := parseDecimals(decimals)
+		if err != nil {
+			return Options{}, err
+		}
+		opts.Decimals = d
+	}
+	return opts, nil
+}
+
+// Writer writes the rows of a table, values can be strings, float64,
+// time.Time or *time.Time where nil is an empty cell. Close has to be
+// called to complete the file
+type Writer interface {
+	Write(row []interface{}) error
+	Close() error
+}
+
+// New writes the header with the columns and returns the writer of
+// the rows
+func New(w io.Writer, f Format, columns []string, opts Options) (Writer, error) {
+	if f == FormatXLSX {
+		return newXLSX(w, columns, opts)
+	}
+	return newCSV(w, columns, opts)
+}
+
+// timeOf normalizes the time values of a row, ok is false for nil
+func timeOf(v interface{}) (t time.Time, ok bool) {
+	switch v := v.(type) {
+	case time.Time:
+		return v.UTC(), true
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false
+		}
+		return v.UTC(), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,125 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	testFrom = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	testTo   = testFrom.AddDate(0, 1, 0)
+	testRows = [][]interface{}{
+		{"BTC", "BRL", 312456.789, testFrom, &testTo},
+		{"USD", "BRL", 5.25, testTo, (*time.Time)(nil)},
+	}
+)
+
+func TestCSV(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		locale  string
+		decimal string
+		want    string
+	}{
+		{
+			name: "plain numbers",
+			want: "from,to,rate,valid_from,valid_to\n" +
+				"BTC,BRL,312456.789,2026-01-01T00:00:00Z,2026-02-01T00:00:00Z\n" +
+				"USD,BRL,5.25,2026-02-01T00:00:00Z,\n",
+		},
+		{
+			name:    "fixed decimals",
+			decimal: "2",
+			want: "from,to,rate,valid_from,valid_to\n" +
+				"BTC,BRL,312456.79,2026-01-01T00:00:00Z,2026-02-01T00:00:00Z\n" +
+				"USD,BRL,5.25,2026-02-01T00:00:00Z,\n",
+		},
+		{
+			name:   "locale with decimal point",
+			locale: "en-US",
+			want: "from,to,rate,valid_from,valid_to\n" +
+				"BTC,BRL,\"312,456.789\",2026-01-01T00:00:00Z,2026-02-01T00:00:00Z\n" +
+				"USD,BRL,5.25,2026-02-01T00:00:00Z,\n",
+		},
+		{
+			name:    "locale with decimal comma",
+			locale:  "pt-BR",
+			decimal: "4",
+			want: "from;to;rate;valid_from;valid_to\n" +
+				"BTC;BRL;312.456,7890;2026-01-01T00:00:00Z;2026-02-01T00:00:00Z\n" +
+				"USD;BRL;5,2500;2026-02-01T00:00:00Z;\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			opts, err := ParseOptions(tt.locale, tt.decimal)
+			require.NoError(t, err)
+			buf := &bytes.Buffer{}
+			w, err := New(buf, FormatCSV, []string{"from", "to", "rate", "valid_from", "valid_to"}, opts)
+			require.NoError(t, err)
+			for _, row := range testRows {
+				require.NoError(t, w.Write(row))
+			}
+			require.NoError(t, w.Close())
+			require.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestXLSX(t *testing.T) {
+	t.Parallel()
+	opts, err := ParseOptions("", "2")
+	require.NoError(t, err)
+	buf := &bytes.Buffer{}
+	w, err := New(buf, FormatXLSX, []string{"from", "to", "rate", "valid_from", "valid_to"}, opts)
+	require.NoError(t, err)
+	for _, row := range testRows {
+		require.NoError(t, w.Write(row))
+	}
+	require.NoError(t, w.Close())
+
+	f, err := excelize.OpenReader(buf)
+	require.NoError(t, err)
+	defer f.Close()
+	rows, err := f.GetRows(sheet, excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	require.Equal(t, []string{"from", "to", "rate", "valid_from", "valid_to"}, rows[0])
+	require.Equal(t, []string{"USD", "BRL", "5.25"}, rows[2][:3])
+	require.Len(t, rows[2], 4, "nil times are empty cells")
+	formatted, err := f.GetCellValue(sheet, "C2")
+	require.NoError(t, err)
+	require.Equal(t, "312,456.79", formatted)
+}
+
+func TestParseOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		locale   string
+		decimals string
+		wantErr  error
+	}{
+		{name: "defaults"},
+		{name: "valid", locale: "pt-BR", decimals: "12"},
+		{name: "invalid locale", locale: "not a locale", wantErr: core.ErrInvalidLocale},
+		{name: "negative decimals", decimals: "-1", wantErr: core.ErrInvalidDecimals},
+		{name: "too many decimals", decimals: "13", wantErr: core.ErrInvalidDecimals},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParseOptions(tt.locale, tt.decimals)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// sheet is the name of the single sheet of the workbooks
+const sheet = "export"
+
+// xlsxWriter streams the rows to a temporary file once they exceed
+// the memory buffer of excelize, the workbook is written on Close
+type xlsxWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	number int
+	rows   int
+	cells  []interface{}
+}
+
+func newXLSX(w io.Writer, columns []string, opts Options) (*xlsxWriter, error) {
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		f.Close()
+		return nil, err
+	}
+	x := &xlsxWriter{w: w, file: f, cells: make([]interface{}, 0, len(columns))}
+	err := x.init(columns, opts)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return x, nil
+}
+
+func (x *xlsxWriter) init(columns []string, opts Options) (err error) {
+	if x.stream, err = x.file.NewStreamWriter(sheet); err != nil {
+		return err
+	}
+	if opts.Decimals >= 0 {
+		format := "#,##0"
+		if opts.Decimals > 0 {
+			format += "." + strings.Repeat("0", opts.Decimals)
+		}
+		if x.number, err = x.file.NewStyle(&excelize.Style{CustomNumFmt: &format}); err != nil {
+			return err
+		}
+	}
+	bold, err := x.file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	header := make([]interface{}, 0, len(columns))
+	for _, c := range columns {
+		header = append(header, excelize.Cell{StyleID: bold, Value: c})
+	}
+	return x.Write(header)
+}
+
+func (x *xlsxWriter) Write(row []interface{}) error {
+	x.cells = x.cells[:0]
+	for _, v := range row {
+		switch val := v.(type) {
+		case float64:
+			v = excelize.Cell{StyleID: x.number, Value: val}
+		case time.Time, *time.Time:
+			v = nil
+			if t, ok := timeOf(val); ok {
+				v = t
+			}
+		}
+		x.cells = append(x.cells, v)
+	}
+	x.rows++
+	cell, err := excelize.CoordinatesToCellName(1, x.rows)
+	if err != nil {
+		return err
+	}
+	return x.stream.SetRow(cell, x.cells)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	return x.file.Write(x.w)
+}
@@ -0,0 +1,13 @@
+package graphql
+
+import "time"
+
+type Config struct {
+	// MaxDepth rejects queries nested deeper than it
+	MaxDepth int `envconfig:"APP_GRAPHQL_MAX_DEPTH" default:"8"`
+	// BatchWait is how long a loader collects keys before fetching
+	// them, BatchMax fetches earlier when that many keys are waiting.
+	// BatchMax is also the most conversions a query can request
+	BatchWait time.Duration `envconfig:"APP_GRAPHQL_BATCH_WAIT" default:"2ms"`
+	BatchMax  int           `envconfig:"APP_GRAPHQL_BATCH_MAX" default:"100"`
+}
@@ -0,0 +1,35 @@
+package graphql
+
+import (
+	"errors"
+
+	"github.com/arxdsilva/bravo/internal/core"
+)
+
+// Error carries the code the http API answers in its problem details
+// as the code extension of the GraphQL error
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.Code}
+}
+
+// wrap maps err to its GraphQL error
+func wrap(err error) error {
+	var ce *core.Error
+	if errors.As(err, &ce) {
+		msg := err.Error()
+		if ce.Kind.ServerFault() {
+			msg = ce.Message
+		}
+		return &Error{Code: ce.Code, Message: msg}
+	}
+	return &Error{Code: "internal_error", Message: "internal error"}
+}
@@ -0,0 +1,131 @@
+// Package graphql serves read queries over currencies, rates and
+// conversions, nested lookups are batched per query by loaders so
+// they do not hit the repository or the provider once per item
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/service"
+	"github.com/arxdsilva/bravo/internal/tracing"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/trace/otel"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// Request is the body of a query
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Executor runs the queries, the zero value is not usable
+type Executor struct {
+	schema  *graphql.Schema
+	service service.Resolver
+	config  Config
+}
+
+func New(svc service.Resolver, cfg Config) Executor {
+	if cfg.BatchMax < 1 {
+		cfg.BatchMax = 1
+	}
+	return Executor{
+		// lists resolve up to BatchMax items at once so a batch is
+		// not cut short by items still waiting to be resolved
+		schema: graphql.MustParseSchema(schema, &resolver{},
+			graphql.MaxDepth(cfg.MaxDepth),
+			graphql.MaxParallelism(cfg.BatchMax),
+			graphql.Tracer(&otel.Tracer{Tracer: tracing.Tracer()}),
+		),
+		service: svc,
+		config:  cfg,
+	}
+}
+
+// Exec runs the query, errors of the fields are in the response
+func (e Executor) Exec(ctx context.Context, req Request) *graphql.Response {
+	ctx = context.WithValue(ctx, loadersKey{}, e.loaders(ctx))
+	return e.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+}
+
+type principalKey struct{}
+
+// WithPrincipal sets the caller whose scopes are checked by the
+// fields, without one every field is allowed
+func WithPrincipal(ctx context.Context, p core.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// authorize ensures the caller was granted the scope of a field
+func authorize(ctx context.Context, scope core.Scope) error {
+	p, ok := ctx.Value(principalKey{}).(core.Principal)
+	if ok && !p.HasScope(scope) {
+		return core.ErrForbidden
+	}
+	return nil
+}
+
+type chargeKey struct{}
+
+// Charge takes n conversions from the allowance of the caller. The
+// transport limits a query as a single request, while each of its
+// conversions has to cost as much as a convert request
+type Charge func(ctx context.Context, n int) error
+
+// WithCharge sets how the conversions of a query are charged, without
+// it they are free
+func WithCharge(ctx context.Context, c Charge) context.Context {
+	return context.WithValue(ctx, chargeKey{}, c)
+}
+
+func charge(ctx context.Context, n int) error {
+	c, ok := ctx.Value(chargeKey{}).(Charge)
+	if !ok || n == 0 {
+		return nil
+	}
+	return c(ctx, n)
+}
+
+type loadersKey struct{}
+
+// loaders are created per query, now is shared by the fields that
+// default to the current instant so they use the same batch key
+type loaders struct {
+	now         time.Time
+	service     service.Resolver
+	max         int
+	rates       *loader[time.Time, core.CurrencyRates]
+	conversions *loader[core.ConversionSVC, core.ConversionResult]
+}
+
+func (e Executor) loaders(ctx context.Context) *loaders {
+	return &loaders{
+		now:     time.Now(),
+		service: e.service,
+		max:     e.config.BatchMax,
+		rates: newLoader(ctx, e.config.BatchWait, e.config.BatchMax,
+			func(ctx context.Context, ats []time.Time) ([]core.CurrencyRates, error) {
+				schedules := make([]core.CurrencyRates, 0, len(ats))
+				for _, at := range ats {
+					rates, err := e.service.GetRates(ctx, at)
+					if err != nil {
+						return nil, err
+					}
+					schedules = append(schedules, rates)
+				}
+				return schedules, nil
+			}),
+		conversions: newLoader(ctx, e.config.BatchWait, e.config.BatchMax, e.service.ConvertMany),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
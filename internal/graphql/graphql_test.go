@@ -0,0 +1,179 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	rsv "github.com/arxdsilva/bravo/internal/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = Config{MaxDepth: 8, BatchWait: 20 * time.Millisecond, BatchMax: 100}
+
+func Test_Exec(t *testing.T) {
+	t.Parallel()
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	usd := core.Currency{Symbol: "USD", Description: "dollar", Source: "manual", Kind: core.CurrencyFiat}
+	eur := core.Currency{Symbol: "EUR", Description: "euro", Source: "manual", Kind: core.CurrencyFiat}
+	rates := core.CurrencyRates{
+		{From: "USD", To: "BRL", Rate: 5, ValidFrom: at},
+		{From: "EUR", To: "BRL", Rate: 6, ValidFrom: at},
+		{From: "USD", To: "BRL", Rate: 4, ValidFrom: at.AddDate(0, -1, 0), ValidTo: &at},
+	}
+	tests := []struct {
+		name      string
+		query     string
+		principal *core.Principal
+		maxDepth  int
+		charge    Charge
+		mock      func(m *rsv.MockResolver)
+		want      string
+		wantCodes []string
+	}{
+		{
+			name:  "nested lookups are batched",
+			query: `{ currencies { currencies { symbol rates { to rate } convert(to: "BRL", amount: 2) { convertedAmount source } } next } }`,
+			mock: func(m *rsv.MockResolver) {
+				m.EXPECT().GetCurrencies(gomock.Any(), core.CurrencyFilter{Sort: "symbol", Limit: core.DefaultPageSize}).
+					Return(core.CurrencyPage{Currencies: core.Currencies{eur, usd}}, nil)
+				m.EXPECT().GetRates(gomock.Any(), gomock.Any()).Return(rates[:2], nil).Times(1)
+				m.EXPECT().ConvertMany(gomock.Any(), gomock.Len(2)).Times(1).
+					DoAndReturn(func(_ context.Context, convs []core.ConversionSVC) ([]core.ConversionResult, error) {
+						results := []core.ConversionResult{}
+						for _, c := range convs {
+							rate, _ := rates[:2].RateOf(c.From, c.To)
+							results = append(results, core.ConversionResult{
+								ConversionResp: core.TransformSVCToResp(c, c.Amount*rate, "manual"),
+							})
+						}
+						return results, nil
+					})
+			},
+			want: `{"currencies":{"currencies":[
+				{"symbol":"EUR","rates":[{"to":"BRL","rate":6}],"convert":{"convertedAmount":12,"source":"manual"}},
+				{"symbol":"USD","rates":[{"to":"BRL","rate":5}],"convert":{"convertedAmount":10,"source":"manual"}}
+			],"next":null}}`,
+		},
+		{
+			name:  "history is newest first",
+			query: `{ currency(symbol: "USD") { history(limit: 1) { rate validFrom validTo } } }`,
+			mock: func(m *rsv.MockResolver) {
+				m.EXPECT().GetCurrency(gomock.Any(), "USD").Return(usd, nil)
+				m.EXPECT().GetRates(gomock.Any(), time.Time{}).Return(rates, nil)
+			},
+			want: `{"currency":{"history":[{"rate":5,"validFrom":"2026-01-01T00:00:00Z","validTo":null}]}}`,
+		},
+		{
+			name:  "missing currency is null",
+			query: `{ currency(symbol: "XYZ") { symbol } }`,
+			mock: func(m *rsv.MockResolver) {
+				m.EXPECT().GetCurrency(gomock.Any(), "XYZ").Return(core.Currency{}, core.ErrNotFound)
+			},
+			want: `{"currency":null}`,
+		},
+		{
+			name:  "same currency is not converted",
+			query: `{ convert(from: "USD", to: "USD", amount: 3) { convertedAmount source } }`,
+			want:  `{"convert":{"convertedAmount":3,"source":"no-edit"}}`,
+		},
+		{
+			name:      "invalid symbol",
+			query:     `{ convert(from: "US", to: "BRL", amount: 3) { convertedAmount } }`,
+			want:      `null`,
+			wantCodes: []string{core.ErrSymbolMinLen.Code},
+		},
+		{
+			name:  "batch of conversions",
+			query: `{ conversions(inputs: [{from: "USD", to: "BRL", amount: 1, at: "2026-01-01T00:00:00Z"}, {from: "EUR", to: "BRL", amount: 2}]) { from convertedAmount } }`,
+			mock: func(m *rsv.MockResolver) {
+				m.EXPECT().ConvertMany(gomock.Any(), gomock.Len(2)).
+					DoAndReturn(func(_ context.Context, convs []core.ConversionSVC) ([]core.ConversionResult, error) {
+						results := []core.ConversionResult{}
+						for _, c := range convs {
+							results = append(results, core.ConversionResult{ConversionResp: core.TransformSVCToResp(c, c.Amount*5, "manual")})
+						}
+						return results, nil
+					})
+			},
+			want: `{"conversions":[{"from":"USD","convertedAmount":5},{"from":"EUR","convertedAmount":10}]}`,
+		},
+		{
+			name:  "provider failure is hidden",
+			query: `{ convert(from: "USD", to: "BRL", amount: 1) { convertedAmount } }`,
+			mock: func(m *rsv.MockResolver) {
+				m.EXPECT().ConvertMany(gomock.Any(), gomock.Any()).
+					Return([]core.ConversionResult{{Err: errors.New("dial tcp: refused")}}, nil)
+			},
+			want:      `null`,
+			wantCodes: []string{"internal_error"},
+		},
+		{
+			name:  "conversions are charged",
+			query: `{ conversions(inputs: [{from: "USD", to: "BRL", amount: 1}, {from: "EUR", to: "BRL", amount: 2}]) { from } }`,
+			charge: func(_ context.Context, n int) error {
+				if n != 2 {
+					return fmt.Errorf("charged %d", n)
+				}
+				return core.ErrRateLimited
+			},
+			want:      `null`,
+			wantCodes: []string{core.ErrRateLimited.Code},
+		},
+		{
+			name:      "scope is required",
+			query:     `{ rates { rate } }`,
+			principal: &core.Principal{ID: "k1", Scopes: []core.Scope{core.ScopeConvertRead}},
+			want:      `null`,
+			wantCodes: []string{core.ErrForbidden.Code},
+		},
+		{
+			name:      "depth is limited",
+			query:     `{ currency(symbol: "USD") { convert(to: "BRL", amount: 1) { from } } }`,
+			maxDepth:  2,
+			want:      `null`,
+			wantCodes: []string{""},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			m := rsv.NewMockResolver(ctrl)
+			if tt.mock != nil {
+				tt.mock(m)
+			}
+			cfg := testConfig
+			if tt.maxDepth > 0 {
+				cfg.MaxDepth = tt.maxDepth
+			}
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = WithPrincipal(ctx, *tt.principal)
+			}
+			if tt.charge != nil {
+				ctx = WithCharge(ctx, tt.charge)
+			}
+			resp := New(m, cfg).Exec(ctx, Request{Query: tt.query})
+			codes := []string{}
+			for _, e := range resp.Errors {
+				code, _ := e.Extensions["code"].(string)
+				codes = append(codes, code)
+			}
+			if tt.wantCodes == nil {
+				require.Empty(t, resp.Errors)
+			} else {
+				require.Equal(t, tt.wantCodes, codes)
+			}
+			data, err := json.Marshal(resp.Data)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.want, string(data))
+		})
+	}
+}
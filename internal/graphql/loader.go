@@ -0,0 +1,103 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// loader collects the keys requested by concurrent resolvers and
+// fetches them in a single call, like a dataloader. Results are kept
+// for the rest of the query so every key is fetched once
+type loader[K comparable, V any] struct {
+	ctx   context.Context
+	fetch func(ctx context.Context, keys []K) ([]V, error)
+	wait  time.Duration
+	max   int
+
+	mu      sync.Mutex
+	results map[K]*result[V]
+	batch   *batch[K, V]
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+type batch[K comparable, V any] struct {
+	keys    []K
+	results []*result[V]
+	full    chan struct{}
+}
+
+// newLoader creates a loader bound to ctx, the context of the query,
+// fetch has to return a value per key in the order of keys
+func newLoader[K comparable, V any](ctx context.Context, wait time.Duration, max int,
+	fetch func(ctx context.Context, keys []K) ([]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		ctx:     ctx,
+		fetch:   fetch,
+		wait:    wait,
+		max:     max,
+		results: map[K]*result[V]{},
+	}
+}
+
+func (l *loader[K, V]) load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	r, ok := l.results[key]
+	if !ok {
+		r = &result[V]{done: make(chan struct{})}
+		l.results[key] = r
+		if l.batch == nil {
+			l.batch = &batch[K, V]{full: make(chan struct{})}
+			go l.dispatch(l.batch)
+		}
+		b := l.batch
+		b.keys = append(b.keys, key)
+		b.results = append(b.results, r)
+		if l.max > 0 && len(b.keys) >= l.max {
+			l.batch = nil
+			close(b.full)
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// dispatch fetches the batch once it is full or after the wait
+func (l *loader[K, V]) dispatch(b *batch[K, V]) {
+	t := time.NewTimer(l.wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		l.mu.Lock()
+		if l.batch == b {
+			l.batch = nil
+		}
+		l.mu.Unlock()
+	case <-b.full:
+	}
+
+	values, err := l.fetch(l.ctx, b.keys)
+	if err == nil && len(values) != len(b.keys) {
+		err = fmt.Errorf("loader fetched %d values for %d keys", len(values), len(b.keys))
+	}
+	for i, r := range b.results {
+		if err == nil {
+			r.value = values[i]
+		}
+		r.err = err
+		close(r.done)
+	}
+}
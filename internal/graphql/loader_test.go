@@ -0,0 +1,65 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_loader(t *testing.T) {
+	t.Parallel()
+	errFetch := errors.New("fetch")
+	tests := []struct {
+		name        string
+		max         int
+		keys        []int
+		err         error
+		wantBatches int
+	}{
+		{name: "keys are fetched together", max: 10, keys: []int{1, 2, 3, 2, 1}, wantBatches: 1},
+		{name: "full batches are fetched early", max: 2, keys: []int{1, 2, 3, 4}, wantBatches: 2},
+		{name: "errors fail every key", max: 10, keys: []int{1, 2}, err: errFetch, wantBatches: 1},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var mu sync.Mutex
+			batches := 0
+			l := newLoader(context.Background(), time.Hour, tt.max, func(_ context.Context, keys []int) ([]int, error) {
+				mu.Lock()
+				batches++
+				mu.Unlock()
+				values := []int{}
+				for _, k := range keys {
+					values = append(values, k*10)
+				}
+				return values, tt.err
+			})
+			if tt.max > len(tt.keys) {
+				l.wait = 20 * time.Millisecond
+			}
+
+			var wg sync.WaitGroup
+			for _, k := range tt.keys {
+				wg.Add(1)
+				go func(k int) {
+					defer wg.Done()
+					v, err := l.load(context.Background(), k)
+					if tt.err != nil {
+						require.ErrorIs(t, err, tt.err)
+						return
+					}
+					require.NoError(t, err)
+					require.Equal(t, k*10, v)
+				}(k)
+			}
+			wg.Wait()
+			require.Equal(t, tt.wantBatches, batches)
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// maxHistory is the most rate windows a history field returns
+const maxHistory = 200
+
+// resolver is the root of the queries, the service and the loaders
+// are taken from the context of each query
+type resolver struct{}
+
+type currenciesArgs struct {
+	Q              *string
+	Source         *string
+	Kind           *string
+	IncludeDeleted *bool
+	Sort           *string
+	Limit          *int32
+	Cursor         *string
+}
+
+func (*resolver) Currencies(ctx context.Context, args currenciesArgs) (*pageResolver, error) {
+	if err := authorize(ctx, core.ScopeCurrenciesRead); err != nil {
+		return nil, wrap(err)
+	}
+	f := core.CurrencyFilter{
+		Q:              deref(args.Q),
+		Source:         deref(args.Source),
+		Kind:           deref(args.Kind),
+		IncludeDeleted: deref(args.IncludeDeleted),
+		Sort:           deref(args.Sort),
+		Limit:          int(deref(args.Limit)),
+		Cursor:         deref(args.Cursor),
+	}
+	if err := f.Check(); err != nil {
+		return nil, wrap(err)
+	}
+	p, err := loadersFrom(ctx).service.GetCurrencies(ctx, f)
+	if err != nil {
+		return nil, wrap(err)
+	}
+	return &pageResolver{p}, nil
+}
+
+func (*resolver) Currency(ctx context.Context, args struct{ Symbol string }) (*currencyResolver, error) {
+	if err := authorize(ctx, core.ScopeCurrenciesRead); err != nil {
+		return nil, wrap(err)
+	}
+	if len(args.Symbol) < 3 {
+		return nil, wrap(core.ErrSymbolMinLen)
+	}
+	c, err := loadersFrom(ctx).service.GetCurrency(ctx, args.Symbol)
+	if errors.Is(err, core.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrap(err)
+	}
+	return &currencyResolver{c}, nil
+}
+
+func (*resolver) Rates(ctx context.Context, args struct{ At *graphql.Time }) ([]*rateResolver, error) {
+	if err := authorize(ctx, core.ScopeRatesRead); err != nil {
+		return nil, wrap(err)
+	}
+	rates, err := loadersFrom(ctx).rates.load(ctx, timeOf(args.At))
+	if err != nil {
+		return nil, wrap(err)
+	}
+	return rateResolvers(rates, ""), nil
+}
+
+type convertArgs struct {
+	From   string
+	To     string
+	Amount float64
+	At     *graphql.Time
+}
+
+func (a convertArgs) core() core.ConversionSVC {
+	return core.ConversionSVC{From: a.From, To: a.To, Amount: a.Amount, At: timeOf(a.At)}
+}
+
+func (*resolver) Convert(ctx context.Context, args convertArgs) (*conversionResolver, error) {
+	return convert(ctx, args.core())
+}
+
+func (*resolver) Conversions(ctx context.Context, args struct{ Inputs []convertArgs }) ([]*conversionResolver, error) {
+	if err := authorize(ctx, core.ScopeConvertRead); err != nil {
+		return nil, wrap(err)
+	}
+	if l := loadersFrom(ctx); l.max > 0 && len(args.Inputs) > l.max {
+		return nil, wrap(core.ErrTooManyConversions)
+	}
+	if err := charge(ctx, len(args.Inputs)); err != nil {
+		return nil, wrap(err)
+	}
+	// every item is loaded before waiting so they share a batch
+	type pending struct {
+		conv *conversionResolver
+		err  error
+	}
+	results := make([]pending, len(args.Inputs))
+	done := make(chan struct{})
+	for i, in := range args.Inputs {
+		go func(i int, conv core.ConversionSVC) {
+			defer func() { done <- struct{}{} }()
+			results[i].conv, results[i].err = loadConversion(ctx, conv)
+		}(i, in.core())
+	}
+	for range args.Inputs {
+		<-done
+	}
+	convs := make([]*conversionResolver, 0, len(results))
+	for _, r := range results {
+		if r.err != nil {
+			return nil, r.err
+		}
+		convs = append(convs, r.conv)
+	}
+	return convs, nil
+}
+
+// convert charges a single conversion, see loadConversion
+func convert(ctx context.Context, conv core.ConversionSVC) (*conversionResolver, error) {
+	if err := authorize(ctx, core.ScopeConvertRead); err != nil {
+		return nil, wrap(err)
+	}
+	if err := charge(ctx, 1); err != nil {
+		return nil, wrap(err)
+	}
+	return loadConversion(ctx, conv)
+}
+
+// loadConversion checks the conversion like the http API and loads it
+// through the batch of the query, it has been authorized and charged
+func loadConversion(ctx context.Context, conv core.ConversionSVC) (*conversionResolver, error) {
+	if len(conv.From) < 3 || len(conv.To) < 3 {
+		return nil, wrap(core.ErrSymbolMinLen)
+	}
+	if conv.From == conv.To {
+		return &conversionResolver{core.TransformSVCToResp(conv, conv.Amount, "no-edit")}, nil
+	}
+	res, err := loadersFrom(ctx).conversions.load(ctx, conv)
+	if err == nil {
+		err = res.Err
+	}
+	if err != nil {
+		return nil, wrap(err)
+	}
+	return &conversionResolver{res.ConversionResp}, nil
+}
+
+type pageResolver struct {
+	page core.CurrencyPage
+}
+
+func (r *pageResolver) Currencies() []*currencyResolver {
+	cs := make([]*currencyResolver, 0, len(r.page.Currencies))
+	for _, c := range r.page.Currencies {
+		cs = append(cs, &currencyResolver{c})
+	}
+	return cs
+}
+
+func (r *pageResolver) Next() *string {
+	if r.page.Next == "" {
+		return nil
+	}
+	return &r.page.Next
+}
+
+type currencyResolver struct {
+	c core.Currency
+}
+
+func (r *currencyResolver) Symbol() string      { return r.c.Symbol }
+func (r *currencyResolver) Description() string { return r.c.Description }
+func (r *currencyResolver) Source() string      { return r.c.Source }
+func (r *currencyResolver) Deleted() bool       { return r.c.Deleted }
+
+func (r *currencyResolver) Kind() *string {
+	if r.c.Kind == "" {
+		return nil
+	}
+	return &r.c.Kind
+}
+
+func (r *currencyResolver) UpdatedAt() *graphql.Time {
+	if r.c.UpdatedAt.IsZero() {
+		return nil
+	}
+	return &graphql.Time{Time: r.c.UpdatedAt}
+}
+
+func (r *currencyResolver) Rates(ctx context.Context, args struct{ At *graphql.Time }) ([]*rateResolver, error) {
+	if err := authorize(ctx, core.ScopeRatesRead); err != nil {
+		return nil, wrap(err)
+	}
+	l := loadersFrom(ctx)
+	at := l.now
+	if args.At != nil {
+		at = args.At.Time
+	}
+	rates, err := l.rates.load(ctx, at)
+	if err != nil {
+		return nil, wrap(err)
+	}
+	return rateResolvers(rates, r.c.Symbol), nil
+}
+
+func (r *currencyResolver) History(ctx context.Context, args struct{ Limit int32 }) ([]*rateResolver, error) {
+	if err := authorize(ctx, core.ScopeRatesRead); err != nil {
+		return nil, wrap(err)
+	}
+	if args.Limit < 1 || args.Limit > maxHistory {
+		return nil, wrap(core.ErrInvalidLimit)
+	}
+	// the zero instant loads the whole schedule
+	rates, err := loadersFrom(ctx).rates.load(ctx, time.Time{})
+	if err != nil {
+		return nil, wrap(err)
+	}
+	history := rateResolvers(rates, r.c.Symbol)
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].r.ValidFrom.After(history[j].r.ValidFrom)
+	})
+	if len(history) > int(args.Limit) {
+		history = history[:args.Limit]
+	}
+	return history, nil
+}
+
+func (r *currencyResolver) Convert(ctx context.Context, args struct {
+	To     string
+	Amount float64
+	At     *graphql.Time
+}) (*conversionResolver, error) {
+	return convert(ctx, core.ConversionSVC{From: r.c.Symbol, To: args.To, Amount: args.Amount, At: timeOf(args.At)})
+}
+
+type rateResolver struct {
+	r core.CurrencyRate
+}
+
+// rateResolvers wraps the rates quoted from the symbol, every rate
+// when it is empty
+func rateResolvers(rates core.CurrencyRates, from string) []*rateResolver {
+	rs := []*rateResolver{}
+	for _, r := range rates {
+		if from == "" || r.From == from {
+			rs = append(rs, &rateResolver{r})
+		}
+	}
+	return rs
+}
+
+func (r *rateResolver) From() string            { return r.r.From }
+func (r *rateResolver) To() string              { return r.r.To }
+func (r *rateResolver) Rate() float64           { return r.r.Rate }
+func (r *rateResolver) ValidFrom() graphql.Time { return graphql.Time{Time: r.r.ValidFrom} }
+
+func (r *rateResolver) ValidTo() *graphql.Time {
+	if r.r.ValidTo == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *r.r.ValidTo}
+}
+
+type conversionResolver struct {
+	c core.ConversionResp
+}
+
+func (r *conversionResolver) From() string             { return r.c.From }
+func (r *conversionResolver) To() string               { return r.c.To }
+func (r *conversionResolver) OriginalAmount() float64  { return r.c.OriginalAmount }
+func (r *conversionResolver) ConvertedAmount() float64 { return r.c.ConvertedAmount }
+func (r *conversionResolver) Source() string           { return r.c.ConversionSource }
+
+func timeOf(t *graphql.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.Time
+}
+
+func deref[T any](v *T) (zero T) {
+	if v == nil {
+		return zero
+	}
+	return *v
+}
@@ -0,0 +1,168 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/ratelimit"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// metadata keys, the same credentials as the http headers
+const (
+	MetadataAuthorization = "authorization"
+	MetadataAPIKey        = "x-api-key"
+	MetadataActor         = "x-actor"
+)
+
+// TokenVerifier validates bearer tokens into principals
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (core.Principal, error)
+}
+
+type principalKey struct{}
+
+// rule is the scope and rate limit group of a method
+type rule struct {
+	scope core.Scope
+	group string
+}
+
+// rules mirror the scopes and groups of the http routes, methods
+// without a rule are refused
+var rules = map[string]rule{
+	"/bravo.v1.Conversions/Convert":       {core.ScopeConvertRead, ratelimit.GroupConvert},
+	"/bravo.v1.Currencies/ListCurrencies": {core.ScopeCurrenciesRead, ratelimit.GroupRead},
+	"/bravo.v1.Currencies/GetCurrency":    {core.ScopeCurrenciesRead, ratelimit.GroupRead},
+	"/bravo.v1.Currencies/AddCurrency":    {core.ScopeCurrenciesWrite, ratelimit.GroupWrite},
+	"/bravo.v1.Currencies/UpdateCurrency": {core.ScopeCurrenciesWrite, ratelimit.GroupWrite},
+	"/bravo.v1.Currencies/RemoveCurrency": {core.ScopeCurrenciesWrite, ratelimit.GroupWrite},
+	"/bravo.v1.Rates/ListRates":           {core.ScopeRatesRead, ratelimit.GroupRead},
+	"/bravo.v1.Rates/CreateRate":          {core.ScopeRatesWrite, ratelimit.GroupWrite},
+	"/bravo.v1.Rates/UpdateRate":          {core.ScopeRatesWrite, ratelimit.GroupWrite},
+	"/bravo.v1.Rates/RemoveRate":          {core.ScopeRatesWrite, ratelimit.GroupWrite},
+	"/bravo.v1.Rates/StreamRates":         {core.ScopeRatesRead, ratelimit.GroupRead},
+}
+
+// requireScope authenticates the caller with a bearer token or an
+// api key and ensures it was granted the scope of the method
+func (s Server) requireScope(ctx context.Context, method string, next call) error {
+	if !s.config.AuthEnabled {
+		return next(ctx)
+	}
+	r, ok := rules[method]
+	if !ok {
+		return core.ErrForbidden
+	}
+	lg := log.WithFields(log.Fields{
+		"pkg":    "grpc",
+		"method": method,
+		"cid":    requestIDFrom(ctx),
+		"scope":  r.scope,
+	})
+	md, _ := metadata.FromIncomingContext(ctx)
+	token, bearer := bearerToken(md)
+	p, err := s.authenticate(ctx, md, token, bearer)
+	if errors.Is(err, core.ErrUnauthenticated) || errors.Is(err, core.ErrInvalidToken) {
+		lg.WithError(err).Warn("unauthenticated")
+		if bearer && !errors.Is(err, core.ErrInvalidToken) {
+			err = core.ErrInvalidToken
+		}
+		return err
+	}
+	if err != nil {
+		lg.WithError(err).Error("authenticate")
+		return err
+	}
+	ctx = context.WithValue(ctx, principalKey{}, p)
+	if !p.HasScope(r.scope) {
+		lg.WithField("key_id", p.ID).Warn("forbidden")
+		return core.ErrForbidden
+	}
+	return next(ctx)
+}
+
+// authenticate uses the bearer token when sent, falling back to the api key
+func (s Server) authenticate(ctx context.Context, md metadata.MD, token string, bearer bool) (core.Principal, error) {
+	if !bearer {
+		return s.service.AuthenticateKey(ctx, first(md, MetadataAPIKey))
+	}
+	if s.verifier == nil {
+		return core.Principal{}, core.ErrUnauthenticated
+	}
+	return s.verifier.Verify(ctx, token)
+}
+
+func bearerToken(md metadata.MD) (string, bool) {
+	const prefix = "bearer "
+	h := first(md, MetadataAuthorization)
+	if len(h) < len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(h[len(prefix):]), true
+}
+
+func first(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func principal(ctx context.Context) (core.Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(core.Principal)
+	return p, ok
+}
+
+// keyID identifies the credential of the call in logs
+func keyID(ctx context.Context) string {
+	p, _ := principal(ctx)
+	return p.ID
+}
+
+// actor is the authenticated principal. The x-actor metadata is only
+// read when authentication is disabled and the metadata is trusted,
+// otherwise the actor is empty and rate changes are refused
+func (s Server) actor(ctx context.Context) string {
+	if p, ok := principal(ctx); ok {
+		return p.ID
+	}
+	if s.config.AuthEnabled || !s.config.TrustActorHeader {
+		return ""
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	return first(md, MetadataActor)
+}
+
+// clientID identifies the caller by credential, or by ip when anonymous
+func clientID(ctx context.Context) string {
+	if p, ok := principal(ctx); ok {
+		return p.Kind + ":" + p.ID
+	}
+	return "ip:" + peerIP(ctx)
+}
+
+// peerIP is the address of the caller, empty outside of a grpc call
+func peerIP(ctx context.Context) string {
+	pr, ok := peer.FromContext(ctx)
+	if !ok || pr.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(pr.Addr.String())
+	if err != nil {
+		return pr.Addr.String()
+	}
+	return host
+}
+
+// setHeader sends md with the response headers, it is a no-op
+// outside of a grpc call
+func setHeader(ctx context.Context, md metadata.MD) {
+	_ = grpc.SetHeader(ctx, md)
+}
@@ -0,0 +1,596 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: bravo/v1/bravo.proto
+
+package bravopb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ConversionsClient is the client API for Conversions service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ConversionsClient interface {
+	Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*Conversion, error)
+}
+
+type conversionsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewConversionsClient(cc grpc.ClientConnInterface) ConversionsClient {
+	return &conversionsClient{cc}
+}
+
+func (c *conversionsClient) Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*Conversion, error) {
+	out := new(Conversion)
+	err := c.cc.Invoke(ctx, "/bravo.v1.Conversions/Convert", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ConversionsServer is the server API for Conversions service.
+// All implementations must embed UnimplementedConversionsServer
+// for forward compatibility
+type ConversionsServer interface {
+	Convert(context.Context, *ConvertRequest) (*Conversion, error)
+	mustEmbedUnimplementedConversionsServer()
+}
+
+// UnimplementedConversionsServer must be embedded to have forward compatible implementations.
+type UnimplementedConversionsServer struct {
+}
+
+func (UnimplementedConversionsServer) Convert(context.Context, *ConvertRequest) (*Conversion, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Convert not implemented")
+}
+func (UnimplementedConversionsServer) mustEmbedUnimplementedConversionsServer() {}
+
+// UnsafeConversionsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ConversionsServer will
+// result in compilation errors.
+type UnsafeConversionsServer interface {
+	mustEmbedUnimplementedConversionsServer()
+}
+
+func RegisterConversionsServer(s grpc.ServiceRegistrar, srv ConversionsServer) {
+	s.RegisterService(&Conversions_ServiceDesc, srv)
+}
+
+func _Conversions_Convert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConversionsServer).Convert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bravo.v1.Conversions/Convert",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConversionsServer).Convert(ctx, req.(*ConvertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Conversions_ServiceDesc is the grpc.ServiceDesc for Conversions service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Conversions_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bravo.v1.Conversions",
+	HandlerType: (*ConversionsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Convert",
+			Handler:    _Conversions_Convert_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "bravo/v1/bravo.proto",
+}
+
+// CurrenciesClient is the client API for Currencies service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CurrenciesClient interface {
+	ListCurrencies(ctx context.Context, in *ListCurrenciesRequest, opts ...grpc.CallOption) (*ListCurrenciesResponse, error)
+	GetCurrency(ctx context.Context, in *GetCurrencyRequest, opts ...grpc.CallOption) (*Currency, error)
+	AddCurrency(ctx context.Context, in *AddCurrencyRequest, opts ...grpc.CallOption) (*Currency, error)
+	UpdateCurrency(ctx context.Context, in *UpdateCurrencyRequest, opts ...grpc.CallOption) (*Currency, error)
+	RemoveCurrency(ctx context.Context, in *RemoveCurrencyRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type currenciesClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCurrenciesClient(cc grpc.ClientConnInterface) CurrenciesClient {
+	return &currenciesClient{cc}
+}
+
+func (c *currenciesClient) ListCurrencies(ctx context.Context, in *ListCurrenciesRequest, opts ...grpc.CallOption) (*ListCurrenciesResponse, error) {
+	out := new(ListCurrenciesResponse)
+	err := c.cc.Invoke(ctx, "/bravo.v1.Currencies/ListCurrencies", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *currenciesClient) GetCurrency(ctx context.Context, in *GetCurrencyRequest, opts ...grpc.CallOption) (*Currency, error) {
+	out := new(Currency)
+	err := c.cc.Invoke(ctx, "/bravo.v1.Currencies/GetCurrency", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *currenciesClient) AddCurrency(ctx context.Context, in *AddCurrencyRequest, opts ...grpc.CallOption) (*Currency, error) {
+	out := new(Currency)
+	err := c.cc.Invoke(ctx, "/bravo.v1.Currencies/AddCurrency", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *currenciesClient) UpdateCurrency(ctx context.Context, in *UpdateCurrencyRequest, opts ...grpc.CallOption) (*Currency, error) {
+	out := new(Currency)
+	err := c.cc.Invoke(ctx, "/bravo.v1.Currencies/UpdateCurrency", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *currenciesClient) RemoveCurrency(ctx context.Context, in *RemoveCurrencyRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/bravo.v1.Currencies/RemoveCurrency", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CurrenciesServer is the server API for Currencies service.
+// All implementations must embed UnimplementedCurrenciesServer
+// for forward compatibility
+type CurrenciesServer interface {
+	ListCurrencies(context.Context, *ListCurrenciesRequest) (*ListCurrenciesResponse, error)
+	GetCurrency(context.Context, *GetCurrencyRequest) (*Currency, error)
+	AddCurrency(context.Context, *AddCurrencyRequest) (*Currency, error)
+	UpdateCurrency(context.Context, *UpdateCurrencyRequest) (*Currency, error)
+	RemoveCurrency(context.Context, *RemoveCurrencyRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedCurrenciesServer()
+}
+
+// UnimplementedCurrenciesServer must be embedded to have forward compatible implementations.
+type UnimplementedCurrenciesServer struct {
+}
+
+func (UnimplementedCurrenciesServer) ListCurrencies(context.Context, *ListCurrenciesRequest) (*ListCurrenciesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCurrencies not implemented")
+}
+func (UnimplementedCurrenciesServer) GetCurrency(context.Context, *GetCurrencyRequest) (*Currency, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCurrency not implemented")
+}
+func (UnimplementedCurrenciesServer) AddCurrency(context.Context, *AddCurrencyRequest) (*Currency, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddCurrency not implemented")
+}
+func (UnimplementedCurrenciesServer) UpdateCurrency(context.Context, *UpdateCurrencyRequest) (*Currency, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCurrency not implemented")
+}
+func (UnimplementedCurrenciesServer) RemoveCurrency(context.Context, *RemoveCurrencyRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveCurrency not implemented")
+}
+func (UnimplementedCurrenciesServer) mustEmbedUnimplementedCurrenciesServer() {}
+
+// UnsafeCurrenciesServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CurrenciesServer will
+// result in compilation errors.
+type UnsafeCurrenciesServer interface {
+	mustEmbedUnimplementedCurrenciesServer()
+}
+
+func RegisterCurrenciesServer(s grpc.ServiceRegistrar, srv CurrenciesServer) {
+	s.RegisterService(&Currencies_ServiceDesc, srv)
+}
+
+func _Currencies_ListCurrencies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCurrenciesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CurrenciesServer).ListCurrencies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bravo.v1.Currencies/ListCurrencies",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CurrenciesServer).ListCurrencies(ctx, req.(*ListCurrenciesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Currencies_GetCurrency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCurrencyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CurrenciesServer).GetCurrency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bravo.v1.Currencies/GetCurrency",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CurrenciesServer).GetCurrency(ctx, req.(*GetCurrencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Currencies_AddCurrency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddCurrencyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CurrenciesServer).AddCurrency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bravo.v1.Currencies/AddCurrency",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CurrenciesServer).AddCurrency(ctx, req.(*AddCurrencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Currencies_UpdateCurrency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCurrencyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CurrenciesServer).UpdateCurrency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bravo.v1.Currencies/UpdateCurrency",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CurrenciesServer).UpdateCurrency(ctx, req.(*UpdateCurrencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Currencies_RemoveCurrency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveCurrencyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CurrenciesServer).RemoveCurrency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bravo.v1.Currencies/RemoveCurrency",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CurrenciesServer).RemoveCurrency(ctx, req.(*RemoveCurrencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Currencies_ServiceDesc is the grpc.ServiceDesc for Currencies service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Currencies_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bravo.v1.Currencies",
+	HandlerType: (*CurrenciesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListCurrencies",
+			Handler:    _Currencies_ListCurrencies_Handler,
+		},
+		{
+			MethodName: "GetCurrency",
+			Handler:    _Currencies_GetCurrency_Handler,
+		},
+		{
+			MethodName: "AddCurrency",
+			Handler:    _Currencies_AddCurrency_Handler,
+		},
+		{
+			MethodName: "UpdateCurrency",
+			Handler:    _Currencies_UpdateCurrency_Handler,
+		},
+		{
+			MethodName: "RemoveCurrency",
+			Handler:    _Currencies_RemoveCurrency_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "bravo/v1/bravo.proto",
+}
+
+// RatesClient is the client API for Rates service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RatesClient interface {
+	ListRates(ctx context.Context, in *ListRatesRequest, opts ...grpc.CallOption) (*ListRatesResponse, error)
+	CreateRate(ctx context.Context, in *RateChangeRequest, opts ...grpc.CallOption) (*RateProposal, error)
+	UpdateRate(ctx context.Context, in *RateChangeRequest, opts ...grpc.CallOption) (*RateProposal, error)
+	RemoveRate(ctx context.Context, in *RateChangeRequest, opts ...grpc.CallOption) (*RateProposal, error)
+	// StreamRates pushes the rate changes of the selected pairs
+	StreamRates(ctx context.Context, in *StreamRatesRequest, opts ...grpc.CallOption) (Rates_StreamRatesClient, error)
+}
+
+type ratesClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRatesClient(cc grpc.ClientConnInterface) RatesClient {
+	return &ratesClient{cc}
+}
+
+func (c *ratesClient) ListRates(ctx context.Context, in *ListRatesRequest, opts ...grpc.CallOption) (*ListRatesResponse, error) {
+	out := new(ListRatesResponse)
+	err := c.cc.Invoke(ctx, "/bravo.v1.Rates/ListRates", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratesClient) CreateRate(ctx context.Context, in *RateChangeRequest, opts ...grpc.CallOption) (*RateProposal, error) {
+	out := new(RateProposal)
+	err := c.cc.Invoke(ctx, "/bravo.v1.Rates/CreateRate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratesClient) UpdateRate(ctx context.Context, in *RateChangeRequest, opts ...grpc.CallOption) (*RateProposal, error) {
+	out := new(RateProposal)
+	err := c.cc.Invoke(ctx, "/bravo.v1.Rates/UpdateRate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratesClient) RemoveRate(ctx context.Context, in *RateChangeRequest, opts ...grpc.CallOption) (*RateProposal, error) {
+	out := new(RateProposal)
+	err := c.cc.Invoke(ctx, "/bravo.v1.Rates/RemoveRate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratesClient) StreamRates(ctx context.Context, in *StreamRatesRequest, opts ...grpc.CallOption) (Rates_StreamRatesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Rates_ServiceDesc.Streams[0], "/bravo.v1.Rates/StreamRates", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ratesStreamRatesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Rates_StreamRatesClient interface {
+	Recv() (*RateEvent, error)
+	grpc.ClientStream
+}
+
+type ratesStreamRatesClient struct {
+	grpc.ClientStream
+}
+
+func (x *ratesStreamRatesClient) Recv() (*RateEvent, error) {
+	m := new(RateEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RatesServer is the server API for Rates service.
+// All implementations must embed UnimplementedRatesServer
+// for forward compatibility
+type RatesServer interface {
+	ListRates(context.Context, *ListRatesRequest) (*ListRatesResponse, error)
+	CreateRate(context.Context, *RateChangeRequest) (*RateProposal, error)
+	UpdateRate(context.Context, *RateChangeRequest) (*RateProposal, error)
+	RemoveRate(context.Context, *RateChangeRequest) (*RateProposal, error)
+	// StreamRates pushes the rate changes of the selected pairs
+	StreamRates(*StreamRatesRequest, Rates_StreamRatesServer) error
+	mustEmbedUnimplementedRatesServer()
+}
+
+// UnimplementedRatesServer must be embedded to have forward compatible implementations.
+type UnimplementedRatesServer struct {
+}
+
+func (UnimplementedRatesServer) ListRates(context.Context, *ListRatesRequest) (*ListRatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRates not implemented")
+}
+func (UnimplementedRatesServer) CreateRate(context.Context, *RateChangeRequest) (*RateProposal, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateRate not implemented")
+}
+func (UnimplementedRatesServer) UpdateRate(context.Context, *RateChangeRequest) (*RateProposal, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateRate not implemented")
+}
+func (UnimplementedRatesServer) RemoveRate(context.Context, *RateChangeRequest) (*RateProposal, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveRate not implemented")
+}
+func (UnimplementedRatesServer) StreamRates(*StreamRatesRequest, Rates_StreamRatesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamRates not implemented")
+}
+func (UnimplementedRatesServer) mustEmbedUnimplementedRatesServer() {}
+
+// UnsafeRatesServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RatesServer will
+// result in compilation errors.
+type UnsafeRatesServer interface {
+	mustEmbedUnimplementedRatesServer()
+}
+
+func RegisterRatesServer(s grpc.ServiceRegistrar, srv RatesServer) {
+	s.RegisterService(&Rates_ServiceDesc, srv)
+}
+
+func _Rates_ListRates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatesServer).ListRates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bravo.v1.Rates/ListRates",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatesServer).ListRates(ctx, req.(*ListRatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rates_CreateRate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RateChangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatesServer).CreateRate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bravo.v1.Rates/CreateRate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatesServer).CreateRate(ctx, req.(*RateChangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rates_UpdateRate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RateChangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatesServer).UpdateRate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bravo.v1.Rates/UpdateRate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatesServer).UpdateRate(ctx, req.(*RateChangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rates_RemoveRate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RateChangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatesServer).RemoveRate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bravo.v1.Rates/RemoveRate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatesServer).RemoveRate(ctx, req.(*RateChangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rates_StreamRates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamRatesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RatesServer).StreamRates(m, &ratesStreamRatesServer{stream})
+}
+
+type Rates_StreamRatesServer interface {
+	Send(*RateEvent) error
+	grpc.ServerStream
+}
+
+type ratesStreamRatesServer struct {
+	grpc.ServerStream
+}
+
+func (x *ratesStreamRatesServer) Send(m *RateEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Rates_ServiceDesc is the grpc.ServiceDesc for Rates service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Rates_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bravo.v1.Rates",
+	HandlerType: (*RatesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListRates",
+			Handler:    _Rates_ListRates_Handler,
+		},
+		{
+			MethodName: "CreateRate",
+			Handler:    _Rates_CreateRate_Handler,
+		},
+		{
+			MethodName: "UpdateRate",
+			Handler:    _Rates_UpdateRate_Handler,
+		},
+		{
+			MethodName: "RemoveRate",
+			Handler:    _Rates_RemoveRate_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamRates",
+			Handler:       _Rates_StreamRates_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "bravo/v1/bravo.proto",
+}
@@ -0,0 +1,15 @@
+package grpc
+
+type Config struct {
+	// Port serves the gRPC API next to the http server, 0 disables it
+	Port int `envconfig:"APP_GRPC_PORT" default:"9090"`
+	// the settings below are shared with the http server, each server
+	// keeps its own buckets while the monthly quota is counted once
+	AuthEnabled bool `envconfig:"APP_AUTH_ENABLED" default:"true"`
+	// TrustActorHeader takes the identity of rate changes from the
+	// x-actor metadata while AuthEnabled is off, for development only
+	TrustActorHeader bool               `envconfig:"APP_DEV_TRUST_ACTOR" default:"false"`
+	RateLimits       map[string]float64 `envconfig:"APP_RATE_LIMITS" default:"convert:10,read:20,write:2,admin:2,ip:50"`
+	RateBursts       map[string]int     `envconfig:"APP_RATE_BURSTS" default:"convert:20,read:40,write:5,admin:5,ip:100"`
+	MonthlyQuota     int64              `envconfig:"APP_MONTHLY_QUOTA" default:"0"`
+}
@@ -0,0 +1,64 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/grpc/bravopb"
+	log "github.com/sirupsen/logrus"
+)
+
+// Convert converts an amount with the manual rate effective at the
+// requested instant, falling back to the exchange provider
+func (s Server) Convert(ctx context.Context, req *bravopb.ConvertRequest) (*bravopb.Conversion, error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "grpc",
+		"method": "Convert",
+		"cid":    requestIDFrom(ctx),
+		"key_id": keyID(ctx),
+	})
+	conv := core.ConversionAPI{
+		From:   req.GetFrom(),
+		To:     req.GetTo(),
+		Amount: req.GetAmount(),
+	}
+	if req.GetAt() != nil {
+		conv.At = req.GetAt().AsTime().Format(time.RFC3339Nano)
+	}
+
+	if err := conv.Check(); err != nil {
+		lg.WithError(err).Error("check")
+		return nil, err
+	}
+
+	convService, shouldConvert, err := core.ConvertToService(conv)
+	if err != nil {
+		lg.WithError(err).Error("convertToService")
+		return nil, err
+	}
+
+	if !shouldConvert {
+		lg.WithField("should_convert", shouldConvert).Info("should_convert")
+		return conversionPB(core.TransformSVCToResp(convService, convService.Amount, "no-edit")), nil
+	}
+
+	amount, source, err := s.service.Convert(ctx, convService)
+	if err != nil {
+		lg.WithError(err).Error("service.Convert")
+		return nil, err
+	}
+
+	lg.Info("success")
+	return conversionPB(core.TransformSVCToResp(convService, amount, source)), nil
+}
+
+func conversionPB(c core.ConversionResp) *bravopb.Conversion {
+	return &bravopb.Conversion{
+		From:             c.From,
+		To:               c.To,
+		OriginalAmount:   c.OriginalAmount,
+		ConvertedAmount:  c.ConvertedAmount,
+		ConversionSource: c.ConversionSource,
+	}
+}
@@ -0,0 +1,182 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/grpc/bravopb"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ListCurrencies lists a page of the stored currencies, next_cursor
+// fetches the following page
+func (s Server) ListCurrencies(ctx context.Context, req *bravopb.ListCurrenciesRequest) (*bravopb.ListCurrenciesResponse, error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "grpc",
+		"method": "ListCurrencies",
+		"cid":    requestIDFrom(ctx),
+		"key_id": keyID(ctx),
+	})
+	f := core.CurrencyFilter{
+		Q:              req.GetQ(),
+		Source:         req.GetSource(),
+		Kind:           req.GetKind(),
+		IncludeDeleted: req.GetIncludeDeleted(),
+		Sort:           req.GetSort(),
+		Limit:          int(req.GetLimit()),
+		Cursor:         req.GetCursor(),
+	}
+	page, err := s.service.GetCurrencies(ctx, f)
+	if err != nil {
+		lg.WithError(err).Error("service.GetCurrencies")
+		return nil, err
+	}
+	resp := &bravopb.ListCurrenciesResponse{NextCursor: page.Next}
+	for _, c := range page.Currencies {
+		resp.Currencies = append(resp.Currencies, currencyPB(c))
+	}
+	lg.Info("success")
+	return resp, nil
+}
+
+// GetCurrency retrieves a currency with its version
+func (s Server) GetCurrency(ctx context.Context, req *bravopb.GetCurrencyRequest) (*bravopb.Currency, error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "grpc",
+		"method": "GetCurrency",
+		"cid":    requestIDFrom(ctx),
+		"key_id": keyID(ctx),
+	})
+	cr := core.Currency{Symbol: req.GetSymbol()}
+	if err := cr.Check(); err != nil {
+		lg.WithError(err).Error("currency.Check")
+		return nil, err
+	}
+
+	c, err := s.service.GetCurrency(ctx, cr.Symbol)
+	if errors.Is(err, core.ErrNotFound) {
+		lg.WithError(core.ErrCurrencyNotFound).Warn("not found")
+		return nil, core.ErrCurrencyNotFound
+	}
+	if err != nil {
+		lg.WithError(err).Error("service.GetCurrency")
+		return nil, err
+	}
+	lg.Info("success")
+	return currencyPB(c), nil
+}
+
+// AddCurrency registers a currency
+func (s Server) AddCurrency(ctx context.Context, req *bravopb.AddCurrencyRequest) (*bravopb.Currency, error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "grpc",
+		"method": "AddCurrency",
+		"cid":    requestIDFrom(ctx),
+		"key_id": keyID(ctx),
+	})
+	cr := core.Currency{Symbol: req.GetSymbol(), Description: req.GetDescription()}
+	if err := cr.Check(); err != nil {
+		lg.WithError(err).Error("currency.Check")
+		return nil, err
+	}
+
+	if err := s.service.AddCurrency(ctx, cr.Symbol, cr.Description); err != nil {
+		lg.WithError(err).Error("service.AddCurrency")
+		return nil, err
+	}
+	lg.Info("success")
+	return currencyPB(cr), nil
+}
+
+// UpdateCurrency changes the currency description, with a version
+// the change is only made when the currency still has it. The
+// updated currency is returned with its new version
+func (s Server) UpdateCurrency(ctx context.Context, req *bravopb.UpdateCurrencyRequest) (*bravopb.Currency, error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "grpc",
+		"method": "UpdateCurrency",
+		"cid":    requestIDFrom(ctx),
+		"key_id": keyID(ctx),
+	})
+	cr := core.Currency{Symbol: req.GetSymbol(), Description: req.GetDescription()}
+	if err := cr.Check(); err != nil {
+		lg.WithError(err).Error("currency.Check")
+		return nil, err
+	}
+
+	err := s.service.UpdateCurrency(ctx, cr.Symbol, cr.Description, timeOf(req.GetVersion()))
+	if errors.Is(err, core.ErrNotFound) {
+		lg.WithError(core.ErrCurrencyNotFound).Warn("not found")
+		return nil, core.ErrCurrencyNotFound
+	}
+	if err != nil {
+		lg.WithError(err).Error("service.UpdateCurrency")
+		return nil, err
+	}
+
+	c, err := s.service.GetCurrency(ctx, cr.Symbol)
+	if err != nil {
+		lg.WithError(err).Error("service.GetCurrency")
+		return nil, err
+	}
+	lg.Info("success")
+	return currencyPB(c), nil
+}
+
+// RemoveCurrency removes a currency
+func (s Server) RemoveCurrency(ctx context.Context, req *bravopb.RemoveCurrencyRequest) (*emptypb.Empty, error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "grpc",
+		"method": "RemoveCurrency",
+		"cid":    requestIDFrom(ctx),
+		"key_id": keyID(ctx),
+	})
+	cr := core.Currency{Symbol: req.GetSymbol()}
+	if err := cr.Check(); err != nil {
+		lg.WithError(err).Error("currency.Check")
+		return nil, err
+	}
+
+	err := s.service.RemoveCurrency(ctx, cr.Symbol)
+	if errors.Is(err, core.ErrNotFound) {
+		lg.WithError(core.ErrCurrencyNotFound).Warn("not found")
+		return nil, core.ErrCurrencyNotFound
+	}
+	if err != nil {
+		lg.WithError(err).Error("service.RemoveCurrency")
+		return nil, err
+	}
+	lg.Info("success")
+	return &emptypb.Empty{}, nil
+}
+
+func currencyPB(c core.Currency) *bravopb.Currency {
+	return &bravopb.Currency{
+		Symbol:      c.Symbol,
+		Description: c.Description,
+		Source:      c.Source,
+		Kind:        c.Kind,
+		Deleted:     c.Deleted,
+		Version:     timestampOf(c.UpdatedAt),
+	}
+}
+
+// timestampOf leaves zero times unset
+func timestampOf(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// timeOf is the zero time when ts is unset
+func timeOf(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
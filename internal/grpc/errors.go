@@ -0,0 +1,54 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain scopes the reasons of the ErrorInfo details, they are
+// the codes the http API answers in its problem details
+const errorDomain = "bravo"
+
+var kindCode = map[core.Kind]codes.Code{
+	core.KindInternal:        codes.Internal,
+	core.KindInvalid:         codes.InvalidArgument,
+	core.KindUnauthenticated: codes.Unauthenticated,
+	core.KindForbidden:       codes.PermissionDenied,
+	core.KindNotFound:        codes.NotFound,
+	core.KindConflict:        codes.FailedPrecondition,
+	core.KindUnprocessable:   codes.FailedPrecondition,
+	core.KindRateLimited:     codes.ResourceExhausted,
+	core.KindUpstream:        codes.Unavailable,
+	core.KindUnavailable:     codes.Unavailable,
+	core.KindTimeout:         codes.DeadlineExceeded,
+	core.KindPrecondition:    codes.Aborted,
+}
+
+// toStatus maps err to its status
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var ce *core.Error
+	if errors.As(err, &ce) {
+		code := kindCode[ce.Kind]
+		msg := err.Error()
+		if ce.Kind.ServerFault() {
+			msg = ce.Message
+		}
+		st, derr := status.New(code, msg).WithDetails(&errdetails.ErrorInfo{Reason: ce.Code, Domain: errorDomain})
+		if derr != nil {
+			return status.Error(code, msg)
+		}
+		return st.Err()
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.Internal, "internal error")
+}
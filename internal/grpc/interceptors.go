@@ -0,0 +1,199 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/metrics"
+	"github.com/arxdsilva/bravo/internal/tracing"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataRequestID correlates the logs of a call, it is generated
+// when the caller does not send one
+const MetadataRequestID = "x-request-id"
+
+// call runs the rest of the chain
+type call func(ctx context.Context) error
+
+// interceptor is the equivalent of an echo middleware, the same
+// chain wraps unary and streaming calls
+type interceptor func(ctx context.Context, method string, next call) error
+
+type requestIDKey struct{}
+
+// chain runs in order, the ones after observe return core errors
+// that observe maps to a status
+func (s Server) chain() []interceptor {
+	return []interceptor{requestID, traceCall, observe, recoverCall, s.limitIP, s.requireScope, s.limit}
+}
+
+func (s Server) intercept(ctx context.Context, method string, last call) error {
+	chain := s.chain()
+	var next func(i int) call
+	next = func(i int) call {
+		if i == len(chain) {
+			return last
+		}
+		return func(ctx context.Context) error {
+			return chain[i](ctx, method, next(i+1))
+		}
+	}
+	return next(0)(ctx)
+}
+
+func (s Server) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	err = s.intercept(ctx, info.FullMethod, func(ctx context.Context) (err error) {
+		resp, err = handler(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (s Server) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return s.intercept(ss.Context(), info.FullMethod, func(ctx context.Context) error {
+		return handler(srv, serverStream{ServerStream: ss, ctx: ctx})
+	})
+}
+
+// serverStream carries the context built by the interceptors
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// requestID keeps the id sent by the caller or generates one, it is
+// answered in the response headers
+func requestID(ctx context.Context, method string, next call) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := first(md, MetadataRequestID)
+	if id == "" {
+		id = uuid.New().String()
+	}
+	setHeader(ctx, metadata.Pairs(MetadataRequestID, id))
+	return next(context.WithValue(ctx, requestIDKey{}, id))
+}
+
+func requestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// traceCall starts a server span per call continuing the W3C trace
+// context sent by the caller
+func traceCall(ctx context.Context, method string, next call) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	svc, name := splitMethod(method)
+	ctx, span := tracing.Tracer().Start(ctx, strings.TrimPrefix(method, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.RPCSystemGRPC,
+			semconv.RPCServiceKey.String(svc),
+			semconv.RPCMethodKey.String(name),
+		))
+	defer span.End()
+
+	err := next(ctx)
+	code := status.Code(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+	if failedOnServer(code) {
+		span.SetStatus(otelcodes.Error, code.String())
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+// observe maps the returned error to its status, logs the call and
+// records its duration per method
+func observe(ctx context.Context, method string, next call) error {
+	start := time.Now()
+	err := toStatus(next(ctx))
+	code := status.Code(err)
+	latency := time.Since(start)
+	metrics.GRPCRequestDuration.WithLabelValues(method, code.String()).Observe(latency.Seconds())
+
+	lg := log.WithFields(log.Fields{
+		"pkg":     "grpc",
+		"method":  method,
+		"cid":     requestIDFrom(ctx),
+		"code":    code.String(),
+		"latency": latency.String(),
+	})
+	if failedOnServer(code) {
+		lg.WithError(err).Error("call failed")
+		return err
+	}
+	lg.Info("call")
+	return err
+}
+
+// recoverCall turns a panic into an internal error
+func recoverCall(ctx context.Context, method string, next call) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.WithFields(log.Fields{
+				"pkg":    "grpc",
+				"method": method,
+				"cid":    requestIDFrom(ctx),
+				"stack":  string(debug.Stack()),
+			}).Error("panic recovered")
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return next(ctx)
+}
+
+// splitMethod splits /package.Service/Method
+func splitMethod(method string) (string, string) {
+	svc, name, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	return svc, name
+}
+
+// metadataCarrier adapts the incoming metadata to the propagators
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	return first(metadata.MD(c), key)
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// failedOnServer reports whether a call ended with the status of a
+// server side failure, including the ones not mapped from a core.Kind
+func failedOnServer(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DeadlineExceeded,
+		codes.Unimplemented, codes.DataLoss:
+		return true
+	}
+	return false
+}
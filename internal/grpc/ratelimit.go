@@ -0,0 +1,92 @@
+package grpc
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/ratelimit"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// metadata keys of the rate limit, named as the http headers
+const (
+	MetadataRateLimitLimit     = "ratelimit-limit"
+	MetadataRateLimitRemaining = "ratelimit-remaining"
+	MetadataRateLimitReset     = "ratelimit-reset"
+	MetadataRetryAfter         = "retry-after"
+)
+
+// limitIP applies the token bucket of the caller address, it runs
+// before the authentication so calls with missing or invalid
+// credentials are throttled as well
+func (s Server) limitIP(ctx context.Context, method string, next call) error {
+	if s.limiter == nil {
+		return next(ctx)
+	}
+	ip := peerIP(ctx)
+	ok, _, _, reset := s.limiter.Allow(ratelimit.GroupIP, "ip:"+ip, time.Now())
+	if !ok {
+		log.WithFields(log.Fields{
+			"pkg":    "grpc",
+			"method": method,
+			"cid":    requestIDFrom(ctx),
+			"ip":     ip,
+		}).Warn("rate limited")
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(MetadataRetryAfter, ratelimit.Seconds(reset)))
+		return core.ErrRateLimited
+	}
+	return next(ctx)
+}
+
+// limit applies the token bucket of the method group and the monthly
+// quota of the client, it has to run after the authentication.
+// Requests are only counted when there is a quota to enforce
+func (s Server) limit(ctx context.Context, method string, next call) error {
+	if s.limiter == nil {
+		return next(ctx)
+	}
+	client := clientID(ctx)
+	lg := log.WithFields(log.Fields{
+		"pkg":    "grpc",
+		"method": method,
+		"cid":    requestIDFrom(ctx),
+		"key_id": keyID(ctx),
+		"client": client,
+	})
+
+	ok, limit, remaining, reset := s.limiter.Allow(rules[method].group, client, time.Now())
+	if limit > 0 {
+		setHeader(ctx, metadata.Pairs(
+			MetadataRateLimitLimit, strconv.Itoa(limit),
+			MetadataRateLimitRemaining, strconv.Itoa(remaining),
+			MetadataRateLimitReset, ratelimit.Seconds(reset),
+		))
+	}
+	if !ok {
+		lg.Warn("rate limited")
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(MetadataRetryAfter, ratelimit.Seconds(reset)))
+		return core.ErrRateLimited
+	}
+	if s.config.MonthlyQuota <= 0 {
+		return next(ctx)
+	}
+
+	count, err := s.service.IncrementUsage(ctx, client, 1)
+	if err != nil {
+		// usage accounting must not take the api down
+		lg.WithError(err).Error("service.IncrementUsage")
+		return next(ctx)
+	}
+	if count > s.config.MonthlyQuota {
+		lg.WithField("count", count).Warn("quota exceeded")
+		now := time.Now()
+		nextMonth := core.UsagePeriod(now).AddDate(0, 1, 0)
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(MetadataRetryAfter, ratelimit.Seconds(nextMonth.Sub(now))))
+		return core.ErrQuotaExceeded
+	}
+	return next(ctx)
+}
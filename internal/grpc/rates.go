@@ -0,0 +1,190 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/grpc/bravopb"
+	log "github.com/sirupsen/logrus"
+)
+
+// ListRates lists the rate windows effective at the requested
+// instant, or the whole schedule when at is unset
+func (s Server) ListRates(ctx context.Context, req *bravopb.ListRatesRequest) (*bravopb.ListRatesResponse, error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "grpc",
+		"method": "ListRates",
+		"cid":    requestIDFrom(ctx),
+		"key_id": keyID(ctx),
+	})
+	rates, err := s.service.GetRates(ctx, timeOf(req.GetAt()))
+	if err != nil {
+		lg.WithError(err).Error("service.GetRates")
+		return nil, err
+	}
+	resp := &bravopb.ListRatesResponse{}
+	for _, r := range rates {
+		resp.Rates = append(resp.Rates, ratePB(r))
+	}
+	lg.Info("success")
+	return resp, nil
+}
+
+// CreateRate proposes a new currency rate, it is only stored
+// after being approved by a different identity
+func (s Server) CreateRate(ctx context.Context, req *bravopb.RateChangeRequest) (*bravopb.RateProposal, error) {
+	return s.proposeRate(ctx, req, "CreateRate", core.RateActionCreate)
+}
+
+// UpdateRate proposes to change an existing rate window
+func (s Server) UpdateRate(ctx context.Context, req *bravopb.RateChangeRequest) (*bravopb.RateProposal, error) {
+	return s.proposeRate(ctx, req, "UpdateRate", core.RateActionUpdate)
+}
+
+// RemoveRate proposes to delete a rate window
+func (s Server) RemoveRate(ctx context.Context, req *bravopb.RateChangeRequest) (*bravopb.RateProposal, error) {
+	return s.proposeRate(ctx, req, "RemoveRate", core.RateActionDelete)
+}
+
+func (s Server) proposeRate(ctx context.Context, req *bravopb.RateChangeRequest, method string, action core.RateAction) (*bravopb.RateProposal, error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "grpc",
+		"method": method,
+		"cid":    requestIDFrom(ctx),
+		"key_id": keyID(ctx),
+	})
+	rate := rateOf(req.GetRate())
+	if err := rate.Check(); err != nil {
+		lg.WithError(err).Error("rate.Check")
+		return nil, err
+	}
+	override := core.Override{Force: req.GetForce(), Reason: req.GetForceReason()}
+	if err := override.Check(); err != nil {
+		lg.WithError(err).Error("override.Check")
+		return nil, err
+	}
+
+	proposal, err := s.service.ProposeRate(ctx, action, rate, override, "", s.actor(ctx))
+	if err != nil {
+		lg.WithError(err).Error("service.ProposeRate")
+		return nil, err
+	}
+
+	lg.WithFields(log.Fields{
+		"proposal": proposal.ID,
+		"forced":   proposal.Override.Force,
+	}).Info("success")
+	return proposalPB(proposal), nil
+}
+
+// StreamRates pushes the rate changes of the requested pairs,
+// last_event_id resumes a stream. An event with missed set is sent
+// when the changes since last_event_id are no longer kept. Clients
+// that do not read fast enough are disconnected
+func (s Server) StreamRates(req *bravopb.StreamRatesRequest, srv bravopb.Rates_StreamRatesServer) error {
+	ctx := srv.Context()
+	lg := log.WithFields(log.Fields{
+		"pkg":    "grpc",
+		"method": "StreamRates",
+		"cid":    requestIDFrom(ctx),
+		"key_id": keyID(ctx),
+	})
+
+	pairs, err := core.ParsePairs(strings.Join(req.GetPairs(), ","))
+	if err != nil {
+		lg.WithError(err).Error("core.ParsePairs")
+		return err
+	}
+
+	sub := s.service.SubscribeRates(pairs, req.GetLastEventId())
+	defer s.service.UnsubscribeRates(sub)
+
+	if sub.Missed {
+		if err = srv.Send(&bravopb.RateEvent{Missed: true}); err != nil {
+			return nil
+		}
+	}
+	for _, e := range sub.Backlog {
+		if err = srv.Send(rateEventPB(e)); err != nil {
+			return nil
+		}
+	}
+	lg.WithField("pairs", pairs).Info("stream opened")
+
+	for {
+		select {
+		case <-ctx.Done():
+			lg.Info("stream closed by the client")
+			return nil
+		case <-s.done:
+			return nil
+		case e, ok := <-sub.Events():
+			if !ok {
+				if sub.Dropped() {
+					lg.Warn("stream dropped, the client is too slow")
+				}
+				return nil
+			}
+			if err = srv.Send(rateEventPB(e)); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+func ratePB(r core.CurrencyRate) *bravopb.Rate {
+	pb := &bravopb.Rate{
+		From:      r.From,
+		To:        r.To,
+		Rate:      r.Rate,
+		ValidFrom: timestampOf(r.ValidFrom),
+	}
+	if r.ValidTo != nil {
+		pb.ValidTo = timestampOf(*r.ValidTo)
+	}
+	return pb
+}
+
+func rateOf(pb *bravopb.Rate) core.CurrencyRate {
+	r := core.CurrencyRate{
+		From:      pb.GetFrom(),
+		To:        pb.GetTo(),
+		Rate:      pb.GetRate(),
+		ValidFrom: timeOf(pb.GetValidFrom()),
+	}
+	if pb.GetValidTo() != nil {
+		validTo := pb.GetValidTo().AsTime()
+		r.ValidTo = &validTo
+	}
+	return r
+}
+
+func proposalPB(p core.RateProposal) *bravopb.RateProposal {
+	pb := &bravopb.RateProposal{
+		Id:          p.ID,
+		Action:      string(p.Action),
+		Rate:        ratePB(p.Rate),
+		Force:       p.Override.Force,
+		ForceReason: p.Override.Reason,
+		Status:      string(p.Status),
+		ProposedBy:  p.ProposedBy,
+		DecidedBy:   p.DecidedBy,
+		Reason:      p.Reason,
+		CreatedAt:   timestampOf(p.CreatedAt),
+	}
+	if p.DecidedAt != nil {
+		pb.DecidedAt = timestampOf(*p.DecidedAt)
+	}
+	return pb
+}
+
+func rateEventPB(e core.RateEvent) *bravopb.RateEvent {
+	return &bravopb.RateEvent{
+		Id:     e.ID,
+		Action: string(e.Action),
+		Source: e.Source,
+		Rate:   ratePB(e.Rate),
+		At:     timestampOf(e.At),
+	}
+}
@@ -0,0 +1,68 @@
+// Package grpc serves the API described in proto/bravo/v1 for
+// internal services, it shares the authentication, limits and
+// observability of the http server
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/arxdsilva/bravo/internal/grpc/bravopb"
+	"github.com/arxdsilva/bravo/internal/ratelimit"
+	"github.com/arxdsilva/bravo/internal/service"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+type Server struct {
+	bravopb.UnimplementedConversionsServer
+	bravopb.UnimplementedCurrenciesServer
+	bravopb.UnimplementedRatesServer
+
+	server   *grpc.Server
+	service  service.Resolver
+	verifier TokenVerifier
+	limiter  *ratelimit.Limiter
+	config   Config
+	// done is closed on shutdown to end the open streams
+	done <-chan struct{}
+}
+
+// NewServer creates the grpc server, verifier is optional and
+// enables bearer token authentication
+func NewServer(svc service.Resolver, verifier TokenVerifier, cfg Config) Server {
+	return Server{
+		service:  svc,
+		verifier: verifier,
+		limiter:  ratelimit.New(cfg.RateLimits, cfg.RateBursts),
+		config:   cfg,
+	}
+}
+
+func (s Server) Run(ctx context.Context) error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%v", s.config.Port))
+	if err != nil {
+		return err
+	}
+	s.done = ctx.Done()
+	s.server = s.register()
+	go func() {
+		<-ctx.Done()
+		s.server.GracefulStop()
+	}()
+	log.WithFields(log.Fields{"pkg": "grpc", "port": s.config.Port}).Info("grpc server started")
+	return s.server.Serve(lis)
+}
+
+// register builds the grpc server with the interceptors and services
+func (s Server) register() *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.unary),
+		grpc.ChainStreamInterceptor(s.stream),
+	)
+	bravopb.RegisterConversionsServer(srv, s)
+	bravopb.RegisterCurrenciesServer(srv, s)
+	bravopb.RegisterRatesServer(srv, s)
+	return srv
+}
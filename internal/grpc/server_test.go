@@ -0,0 +1,357 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/grpc/bravopb"
+	"github.com/arxdsilva/bravo/internal/ratelimit"
+	rsv "github.com/arxdsilva/bravo/internal/service/mock"
+	"github.com/arxdsilva/bravo/internal/stream"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// dial serves s in memory and returns a connection to it
+func dial(t *testing.T, s Server) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	done := make(chan struct{})
+	s.done = done
+	srv := s.register()
+	go func() { _ = srv.Serve(lis) }()
+
+	conn, err := grpc.DialContext(context.Background(), "bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		close(done)
+		srv.Stop()
+	})
+	return conn
+}
+
+// requireStatus checks the code and the ErrorInfo reason of err
+func requireStatus(t *testing.T, want *core.Error, err error) {
+	t.Helper()
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, kindCode[want.Kind], st.Code())
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	require.Equal(t, want.Code, info.Reason)
+	require.Equal(t, errorDomain, info.Domain)
+}
+
+func Test_Convert(t *testing.T) {
+	t.Parallel()
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		req     *bravopb.ConvertRequest
+		mock    func(m *rsv.MockResolver)
+		want    *bravopb.Conversion
+		wantErr *core.Error
+	}{
+		{
+			name: "converted",
+			req:  &bravopb.ConvertRequest{From: "USD", To: "BRL", Amount: "2", At: timestamppb.New(at)},
+			mock: func(m *rsv.MockResolver) {
+				m.EXPECT().Convert(gomock.Any(), core.ConversionSVC{From: "USD", To: "BRL", Amount: 2, At: at}).
+					Return(10.0, "manual", nil)
+			},
+			want: &bravopb.Conversion{From: "USD", To: "BRL", OriginalAmount: 2, ConvertedAmount: 10, ConversionSource: "manual"},
+		},
+		{
+			name: "same currency",
+			req:  &bravopb.ConvertRequest{From: "USD", To: "USD", Amount: "2"},
+			want: &bravopb.Conversion{From: "USD", To: "USD", OriginalAmount: 2, ConvertedAmount: 2, ConversionSource: "no-edit"},
+		},
+		{
+			name:    "invalid amount",
+			req:     &bravopb.ConvertRequest{From: "USD", To: "BRL", Amount: "two"},
+			wantErr: core.ErrAmountIsNotANumber,
+		},
+		{
+			name: "provider failure",
+			req:  &bravopb.ConvertRequest{From: "USD", To: "BRL", Amount: "2"},
+			mock: func(m *rsv.MockResolver) {
+				m.EXPECT().Convert(gomock.Any(), gomock.Any()).Return(0.0, "", core.ErrProviderUnavailable)
+			},
+			wantErr: core.ErrProviderUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mock := rsv.NewMockResolver(ctrl)
+			mock.EXPECT().IncrementUsage(gomock.Any(), gomock.Any(), int64(1)).Return(int64(1), nil).AnyTimes()
+			if tt.mock != nil {
+				tt.mock(mock)
+			}
+			conn := dial(t, NewServer(mock, nil, Config{}))
+
+			got, err := bravopb.NewConversionsClient(conn).Convert(context.Background(), tt.req)
+			if tt.wantErr != nil {
+				requireStatus(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want.String(), got.String())
+		})
+	}
+}
+
+func Test_requireScope(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		md        metadata.MD
+		principal core.Principal
+		authErr   error
+		wantErr   *core.Error
+	}{
+		{
+			name:    "missing key",
+			md:      metadata.MD{},
+			authErr: core.ErrUnauthenticated,
+			wantErr: core.ErrUnauthenticated,
+		},
+		{
+			name:    "bearer without verifier",
+			md:      metadata.Pairs(MetadataAuthorization, "Bearer token"),
+			wantErr: core.ErrInvalidToken,
+		},
+		{
+			name:      "key without scope",
+			md:        metadata.Pairs(MetadataAPIKey, "secret"),
+			principal: core.Principal{ID: "key", Scopes: []core.Scope{core.ScopeRatesRead}},
+			wantErr:   core.ErrForbidden,
+		},
+		{
+			name:      "key with scope",
+			md:        metadata.Pairs(MetadataAPIKey, "secret"),
+			principal: core.Principal{ID: "key", Kind: core.PrincipalAPIKey, Scopes: []core.Scope{core.ScopeCurrenciesRead}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mock := rsv.NewMockResolver(ctrl)
+			if first(tt.md, MetadataAuthorization) == "" {
+				mock.EXPECT().AuthenticateKey(gomock.Any(), first(tt.md, MetadataAPIKey)).Return(tt.principal, tt.authErr)
+			}
+			if tt.wantErr == nil {
+				mock.EXPECT().IncrementUsage(gomock.Any(), "api_key:key", int64(1)).Return(int64(1), nil)
+				mock.EXPECT().GetCurrency(gomock.Any(), "USD").Return(core.Currency{Symbol: "USD"}, nil)
+			}
+			conn := dial(t, NewServer(mock, nil, Config{AuthEnabled: true, MonthlyQuota: 100}))
+
+			ctx := metadata.NewOutgoingContext(context.Background(), tt.md)
+			got, err := bravopb.NewCurrenciesClient(conn).GetCurrency(ctx, &bravopb.GetCurrencyRequest{Symbol: "USD"})
+			if tt.wantErr != nil {
+				requireStatus(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "USD", got.Symbol)
+		})
+	}
+}
+
+func Test_limit(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := rsv.NewMockResolver(ctrl)
+	mock.EXPECT().IncrementUsage(gomock.Any(), gomock.Any(), int64(1)).Return(int64(1), nil)
+	mock.EXPECT().GetRates(gomock.Any(), time.Time{}).Return(core.CurrencyRates{}, nil)
+	s := NewServer(mock, nil, Config{MonthlyQuota: 100})
+	s.limiter = ratelimit.New(map[string]float64{ratelimit.GroupRead: 1}, map[string]int{ratelimit.GroupRead: 1})
+	client := bravopb.NewRatesClient(dial(t, s))
+
+	var header metadata.MD
+	_, err := client.ListRates(context.Background(), &bravopb.ListRatesRequest{}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.Equal(t, []string{"1"}, header.Get(MetadataRateLimitLimit))
+	require.Equal(t, []string{"0"}, header.Get(MetadataRateLimitRemaining))
+	require.NotEmpty(t, header.Get(MetadataRequestID))
+
+	var trailer metadata.MD
+	_, err = client.ListRates(context.Background(), &bravopb.ListRatesRequest{}, grpc.Trailer(&trailer))
+	requireStatus(t, core.ErrRateLimited, err)
+	require.Equal(t, []string{"1"}, trailer.Get(MetadataRetryAfter))
+}
+
+func Test_limitIP(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the second attempt is throttled before its key is checked
+	mock := rsv.NewMockResolver(ctrl)
+	mock.EXPECT().AuthenticateKey(gomock.Any(), "guess").Return(core.Principal{}, core.ErrUnauthenticated)
+	s := NewServer(mock, nil, Config{AuthEnabled: true})
+	s.limiter = ratelimit.New(map[string]float64{ratelimit.GroupIP: 1}, map[string]int{ratelimit.GroupIP: 1})
+	client := bravopb.NewCurrenciesClient(dial(t, s))
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs(MetadataAPIKey, "guess"))
+	_, err := client.GetCurrency(ctx, &bravopb.GetCurrencyRequest{Symbol: "USD"})
+	requireStatus(t, core.ErrUnauthenticated, err)
+
+	var trailer metadata.MD
+	_, err = client.GetCurrency(ctx, &bravopb.GetCurrencyRequest{Symbol: "USD"}, grpc.Trailer(&trailer))
+	requireStatus(t, core.ErrRateLimited, err)
+	require.Equal(t, []string{"1"}, trailer.Get(MetadataRetryAfter))
+}
+
+func Test_UpdateCurrency(t *testing.T) {
+	t.Parallel()
+	version := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		err     error
+		wantErr *core.Error
+	}{
+		{name: "updated"},
+		{name: "not found", err: core.ErrNotFound, wantErr: core.ErrCurrencyNotFound},
+		{name: "changed since read", err: core.ErrPreconditionFailed, wantErr: core.ErrPreconditionFailed},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mock := rsv.NewMockResolver(ctrl)
+			mock.EXPECT().UpdateCurrency(gomock.Any(), "USD", "Dollar", version).Return(tt.err)
+			updated := version.Add(time.Minute)
+			if tt.err == nil {
+				mock.EXPECT().GetCurrency(gomock.Any(), "USD").
+					Return(core.Currency{Symbol: "USD", Description: "Dollar", UpdatedAt: updated}, nil)
+			}
+			conn := dial(t, NewServer(mock, nil, Config{}))
+
+			got, err := bravopb.NewCurrenciesClient(conn).UpdateCurrency(context.Background(),
+				&bravopb.UpdateCurrencyRequest{Symbol: "USD", Description: "Dollar", Version: timestamppb.New(version)})
+			if tt.wantErr != nil {
+				requireStatus(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, updated, got.Version.AsTime())
+		})
+	}
+}
+
+func Test_CreateRate(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rate := core.CurrencyRate{From: "USD", To: "BRL", Rate: 5, ValidFrom: from}
+	override := core.Override{Force: true, Reason: "holiday"}
+	mock := rsv.NewMockResolver(ctrl)
+	mock.EXPECT().ProposeRate(gomock.Any(), core.RateActionCreate, rate, override, "", "maker").
+		Return(core.RateProposal{ID: "p1", Action: core.RateActionCreate, Rate: rate, Override: override,
+			Status: core.ProposalPending, ProposedBy: "maker", CreatedAt: from}, nil)
+	conn := dial(t, NewServer(mock, nil, Config{TrustActorHeader: true}))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), MetadataActor, "maker")
+	got, err := bravopb.NewRatesClient(conn).CreateRate(ctx, &bravopb.RateChangeRequest{
+		Rate:        &bravopb.Rate{From: "USD", To: "BRL", Rate: 5, ValidFrom: timestamppb.New(from)},
+		Force:       true,
+		ForceReason: "holiday",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "p1", got.Id)
+	require.Equal(t, "pending", got.Status)
+	require.Nil(t, got.DecidedAt)
+}
+
+func Test_StreamRates(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	broker := stream.NewBroker(stream.Config{Buffer: 8, History: 8})
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	published := core.RateEvent{
+		Action: core.RateActionUpdate,
+		Source: "manual",
+		Rate:   core.CurrencyRate{From: "USD", To: "BRL", Rate: 5, ValidFrom: at},
+		At:     at,
+	}
+	broker.Publish(published)
+
+	mock := rsv.NewMockResolver(ctrl)
+	mock.EXPECT().SubscribeRates([]string{"USD/BRL"}, uint64(9)).DoAndReturn(broker.Subscribe)
+	unsubscribed := make(chan struct{})
+	mock.EXPECT().UnsubscribeRates(gomock.Any()).Do(func(sub *stream.Subscription) {
+		broker.Unsubscribe(sub)
+		close(unsubscribed)
+	})
+	conn := dial(t, NewServer(mock, nil, Config{}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rates, err := bravopb.NewRatesClient(conn).StreamRates(ctx,
+		&bravopb.StreamRatesRequest{Pairs: []string{"usd/brl"}, LastEventId: 9})
+	require.NoError(t, err)
+
+	// the ids belong to another process so the client has to resync
+	e, err := rates.Recv()
+	require.NoError(t, err)
+	require.True(t, e.Missed)
+
+	broker.Publish(core.RateEvent{Action: core.RateActionUpdate, Source: "exchange",
+		Rate: core.CurrencyRate{From: "BTC", To: "USD", Rate: 1}, At: at})
+	broker.Publish(published)
+	e, err = rates.Recv()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), e.Id)
+	require.Equal(t, "USD", e.Rate.From)
+	require.Equal(t, "manual", e.Source)
+
+	cancel()
+	<-unsubscribed
+}
+
+func Test_toStatus(t *testing.T) {
+	t.Parallel()
+	for k := core.KindInternal; k <= core.KindPrecondition; k++ {
+		_, ok := kindCode[k]
+		require.True(t, ok, "kind %v has no code", k)
+	}
+	require.Nil(t, toStatus(nil))
+	require.Equal(t, codes.Canceled, status.Code(toStatus(context.Canceled)))
+	st := status.Convert(toStatus(net.ErrClosed))
+	require.Equal(t, codes.Internal, st.Code())
+	require.Equal(t, "internal error", st.Message())
+}
@@ -0,0 +1,141 @@
+// Package health runs the dependency probes behind the readiness check
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Statuses of a probe and of the whole report
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusFail     = "fail"
+)
+
+// DefaultTimeout bounds probes registered without a timeout
+const DefaultTimeout = 2 * time.Second
+
+type Config struct {
+	Timeout time.Duration `envconfig:"APP_HEALTH_TIMEOUT" default:"2s"`
+	// ProviderCritical and SeedCritical make the service not ready
+	// while the exchange provider is down or the seed did not finish
+	ProviderCritical bool `envconfig:"APP_HEALTH_PROVIDER_CRITICAL" default:"false"`
+	SeedCritical     bool `envconfig:"APP_HEALTH_SEED_CRITICAL" default:"false"`
+}
+
+// Probe checks a dependency, a failing critical probe makes the
+// service not ready while others only degrade it
+type Probe struct {
+	Name     string
+	Critical bool
+	Timeout  time.Duration
+	Check    func(ctx context.Context) error
+}
+
+type Result struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Ready is false when a critical probe failed
+func (r Report) Ready() bool {
+	return r.Status != StatusFail
+}
+
+// Checker holds the registered probes, it is safe for concurrent use
+type Checker struct {
+	mu     sync.RWMutex
+	probes []Probe
+}
+
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+func (c *Checker) Register(p Probe) {
+	if p.Timeout <= 0 {
+		p.Timeout = DefaultTimeout
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.probes = append(c.probes, p)
+}
+
+// Check runs every probe concurrently, each under its own timeout,
+// results keep the registration order
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.RLock()
+	probes := make([]Probe, len(c.probes))
+	copy(probes, c.probes)
+	c.mu.RUnlock()
+
+	results := make([]Result, len(probes))
+	var wg sync.WaitGroup
+	for i, p := range probes {
+		wg.Add(1)
+		go func(i int, p Probe) {
+			defer wg.Done()
+			results[i] = run(ctx, p)
+		}(i, p)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: results}
+	for _, r := range results {
+		if r.Status == StatusOK {
+			continue
+		}
+		if r.Critical {
+			report.Status = StatusFail
+			break
+		}
+		report.Status = StatusDegraded
+	}
+	return report
+}
+
+func run(ctx context.Context, p Probe) Result {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	errc := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errc <- fmt.Errorf("probe panicked: %v", r)
+			}
+		}()
+		errc <- p.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %v", p.Timeout)
+	}
+
+	r := Result{
+		Name:     p.Name,
+		Status:   StatusOK,
+		Critical: p.Critical,
+		Duration: time.Since(start).String(),
+	}
+	if err != nil {
+		r.Status = StatusFail
+		r.Error = err.Error()
+	}
+	return r
+}
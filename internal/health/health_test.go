@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Checker_Check(t *testing.T) {
+	t.Parallel()
+	ok := func(ctx context.Context) error { return nil }
+	fail := func(ctx context.Context) error { return errors.New("down") }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	tests := []struct {
+		name       string
+		probes     []Probe
+		wantStatus string
+		wantChecks []string
+	}{
+		{
+			name:       "no probes",
+			wantStatus: StatusOK,
+			wantChecks: []string{},
+		},
+		{
+			name: "all ok",
+			probes: []Probe{
+				{Name: "db", Critical: true, Check: ok},
+				{Name: "provider", Check: ok},
+			},
+			wantStatus: StatusOK,
+			wantChecks: []string{StatusOK, StatusOK},
+		},
+		{
+			name: "non critical failure degrades",
+			probes: []Probe{
+				{Name: "db", Critical: true, Check: ok},
+				{Name: "provider", Check: fail},
+			},
+			wantStatus: StatusDegraded,
+			wantChecks: []string{StatusOK, StatusFail},
+		},
+		{
+			name: "critical timeout fails",
+			probes: []Probe{
+				{Name: "db", Critical: true, Timeout: 10 * time.Millisecond, Check: slow},
+				{Name: "provider", Check: fail},
+			},
+			wantStatus: StatusFail,
+			wantChecks: []string{StatusFail, StatusFail},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := NewChecker()
+			for _, p := range tt.probes {
+				c.Register(p)
+			}
+			report := c.Check(context.Background())
+			require.Equal(t, tt.wantStatus, report.Status)
+			require.Equal(t, tt.wantStatus != StatusFail, report.Ready())
+			got := []string{}
+			for i, r := range report.Checks {
+				require.Equal(t, tt.probes[i].Name, r.Name)
+				got = append(got, r.Status)
+			}
+			require.Equal(t, tt.wantChecks, got)
+		})
+	}
+}
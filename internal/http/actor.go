@@ -0,0 +1,21 @@
+package http
+
+import "github.com/labstack/echo/v4"
+
+// HeaderActor carries the identity of who is calling the API when
+// authentication is disabled and Config.TrustActorHeader is set, used
+// by the maker-checker flow of manual rates
+const HeaderActor = "X-Actor"
+
+// actor is the authenticated principal. The X-Actor header is only
+// read when authentication is disabled and the header is trusted,
+// otherwise the actor is empty and rate changes are refused
+func (s Server) actor(c echo.Context) string {
+	if p, ok := principal(c); ok {
+		return p.ID
+	}
+	if s.config.AuthEnabled || !s.config.TrustActorHeader {
+		return ""
+	}
+	return c.Request().Header.Get(HeaderActor)
+}
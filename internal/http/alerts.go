@@ -0,0 +1,190 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+type alertReq struct {
+	From      string              `json:"from"`
+	To        string              `json:"to"`
+	Condition core.AlertCondition `json:"condition"`
+	Threshold float64             `json:"threshold"`
+	Window    core.Duration       `json:"window"`
+	URL       string              `json:"url"`
+	// Active is only read on updates, nil keeps the alert active
+	Active *bool `json:"active"`
+}
+
+func (r alertReq) core(id string) core.AlertSubscription {
+	a := core.AlertSubscription{
+		ID:        id,
+		From:      r.From,
+		To:        r.To,
+		Condition: r.Condition,
+		Threshold: r.Threshold,
+		Window:    r.Window,
+		URL:       r.URL,
+		Active:    true,
+	}
+	if r.Active != nil {
+		a.Active = *r.Active
+	}
+	return a
+}
+
+// alertResp is the only time the secret of an alert is shown
+type alertResp struct {
+	core.AlertSubscription
+	Secret string `json:"secret"`
+}
+
+// GetAlertSubscriptions retrieves every alert, secrets are never returned
+//
+// HTTP responses:
+// 200 OK
+// 500 Internal Server Error
+func (s Server) GetAlertSubscriptions(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "GetAlertSubscriptions",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+	subs, err := s.service.GetAlertSubscriptions(c.Request().Context())
+	if err != nil {
+		lg.WithError(err).Error("service.GetAlertSubscriptions")
+		return err
+	}
+	lg.Info("success")
+	return respond(c, http.StatusOK, subs)
+}
+
+// CreateAlertSubscription registers a webhook notified when the rate
+// of a pair crosses a threshold or moves more than a percentage
+// within a window. The secret signing the deliveries is returned
+//
+// HTTP responses:
+// 201 Created
+// 400 Bad Request
+// 422 Unprocessable Entity
+// 500 Internal Server Error
+func (s Server) CreateAlertSubscription(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "CreateAlertSubscription",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+	req := &alertReq{}
+	if err = bind(c, req); err != nil {
+		lg.WithError(err).Error("bind")
+		return err
+	}
+	sub, err := s.service.CreateAlertSubscription(c.Request().Context(), req.core(""))
+	if err != nil {
+		lg.WithError(err).Error("service.CreateAlertSubscription")
+		return err
+	}
+	lg.WithField("alert_id", sub.ID).Info("success")
+	return respond(c, http.StatusCreated, alertResp{AlertSubscription: sub, Secret: sub.Secret})
+}
+
+// GetAlertSubscription retrieves an alert
+//
+// HTTP responses:
+// 200 OK
+// 404 Not Found
+// 500 Internal Server Error
+func (s Server) GetAlertSubscription(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "GetAlertSubscription",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+	sub, err := s.service.GetAlertSubscription(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		lg.WithError(err).Error("service.GetAlertSubscription")
+		return err
+	}
+	lg.Info("success")
+	return respond(c, http.StatusOK, sub)
+}
+
+// UpdateAlertSubscription replaces the settings of an alert, the
+// secret is kept. Send active false to pause it
+//
+// HTTP responses:
+// 200 OK
+// 400 Bad Request
+// 404 Not Found
+// 422 Unprocessable Entity
+// 500 Internal Server Error
+func (s Server) UpdateAlertSubscription(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "UpdateAlertSubscription",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+	req := &alertReq{}
+	if err = bind(c, req); err != nil {
+		lg.WithError(err).Error("bind")
+		return err
+	}
+	sub, err := s.service.UpdateAlertSubscription(c.Request().Context(), req.core(c.Param("id")))
+	if err != nil {
+		lg.WithError(err).Error("service.UpdateAlertSubscription")
+		return err
+	}
+	lg.WithField("alert_id", sub.ID).Info("success")
+	return respond(c, http.StatusOK, sub)
+}
+
+// RemoveAlertSubscription deletes an alert and its delivery log
+//
+// HTTP responses:
+// 204 No Content
+// 404 Not Found
+// 500 Internal Server Error
+func (s Server) RemoveAlertSubscription(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "RemoveAlertSubscription",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+	err = s.service.RemoveAlertSubscription(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		lg.WithError(err).Error("service.RemoveAlertSubscription")
+		return err
+	}
+	lg.WithField("alert_id", c.Param("id")).Info("success")
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetWebhookDeliveries lists the delivery log of an alert, newest first
+//
+// HTTP responses:
+// 200 OK
+// 404 Not Found
+// 500 Internal Server Error
+func (s Server) GetWebhookDeliveries(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "GetWebhookDeliveries",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+	ds, err := s.service.GetWebhookDeliveries(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		lg.WithError(err).Error("service.GetWebhookDeliveries")
+		return err
+	}
+	lg.Info("success")
+	return respond(c, http.StatusOK, ds)
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	rsv "github.com/arxdsilva/bravo/internal/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreateAlertSubscription(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		body       string
+		wantCreate bool
+		want       core.AlertSubscription
+		createErr  error
+		wantCode   int
+		wantBody   string
+	}{
+		{
+			name:       "threshold alert",
+			body:       `{"from":"usd","to":"brl","condition":"above","threshold":5.5,"url":"https://example.com/hook"}`,
+			wantCreate: true,
+			want:       core.AlertSubscription{From: "usd", To: "brl", Condition: core.AlertAbove, Threshold: 5.5, URL: "https://example.com/hook", Active: true},
+			wantCode:   http.StatusCreated,
+			wantBody:   `"secret":"whsec_x"`,
+		},
+		{
+			name:       "change alert",
+			body:       `{"from":"USD","to":"BRL","condition":"change","threshold":2,"window":"1h","url":"https://example.com/hook"}`,
+			wantCreate: true,
+			want:       core.AlertSubscription{From: "USD", To: "BRL", Condition: core.AlertChange, Threshold: 2, Window: core.Duration(time.Hour), URL: "https://example.com/hook", Active: true},
+			wantCode:   http.StatusCreated,
+			wantBody:   `"window":"1h0m0s"`,
+		},
+		{
+			name:     "invalid window",
+			body:     `{"from":"USD","to":"BRL","condition":"change","threshold":2,"window":"an hour"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_body"`,
+		},
+		{
+			name:       "invalid alert",
+			body:       `{"from":"USD","to":"BRL","condition":"equal","threshold":2}`,
+			wantCreate: true,
+			want:       core.AlertSubscription{From: "USD", To: "BRL", Condition: "equal", Threshold: 2, Active: true},
+			createErr:  core.ErrInvalidAlertCondition,
+			wantCode:   http.StatusBadRequest,
+			wantBody:   `"code":"invalid_alert_condition"`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mock := rsv.NewMockResolver(ctrl)
+			if tt.wantCreate {
+				created := tt.want
+				created.ID, created.Secret = "a1", "whsec_x"
+				mock.EXPECT().CreateAlertSubscription(gomock.Any(), tt.want).Return(created, tt.createErr)
+			}
+
+			e := echo.New()
+			e.HTTPErrorHandler = HTTPErrorHandler
+			e.POST("/v1/alerts", Server{service: mock}.CreateAlertSubscription)
+			req := httptest.NewRequest(http.MethodPost, "/v1/alerts", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantCode, rec.Code)
+			require.Contains(t, rec.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// HeaderAPIKey carries the api key secret of the caller
+const HeaderAPIKey = "X-API-Key"
+
+const principalKey = "principal"
+
+// TokenVerifier validates bearer tokens into principals
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (core.Principal, error)
+}
+
+// requireScope authenticates the caller with a bearer token or an
+// api key and ensures it was granted the scope of the route, an
+// empty scope only authenticates the caller
+func (s Server) requireScope(scope core.Scope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !s.config.AuthEnabled {
+				return next(c)
+			}
+			lg := log.WithFields(log.Fields{
+				"pkg":   "http",
+				"route": c.Path(),
+				"cid":   c.Response().Header().Get(echo.HeaderXRequestID),
+				"scope": scope,
+			})
+			token, bearer := bearerToken(c)
+			p, err := s.authenticate(c, token, bearer)
+			if errors.Is(err, core.ErrUnauthenticated) || errors.Is(err, core.ErrInvalidToken) {
+				lg.WithError(err).Warn("unauthenticated")
+				if bearer {
+					c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+					if !errors.Is(err, core.ErrInvalidToken) {
+						err = core.ErrInvalidToken
+					}
+				}
+				return err
+			}
+			if err != nil {
+				lg.WithError(err).Error("authenticate")
+				return err
+			}
+			c.Set(principalKey, p)
+			if scope != "" && !p.HasScope(scope) {
+				lg.WithField("key_id", p.ID).Warn("forbidden")
+				if bearer {
+					c.Response().Header().Set(echo.HeaderWWWAuthenticate,
+						`Bearer error="insufficient_scope", scope="`+string(scope)+`"`)
+				}
+				return core.ErrForbidden
+			}
+			return next(c)
+		}
+	}
+}
+
+// authenticate uses the bearer token when sent, falling back to the api key
+func (s Server) authenticate(c echo.Context, token string, bearer bool) (core.Principal, error) {
+	ctx := c.Request().Context()
+	if !bearer {
+		return s.service.AuthenticateKey(ctx, c.Request().Header.Get(HeaderAPIKey))
+	}
+	if s.verifier == nil {
+		return core.Principal{}, core.ErrUnauthenticated
+	}
+	return s.verifier.Verify(ctx, token)
+}
+
+func bearerToken(c echo.Context) (string, bool) {
+	const prefix = "bearer "
+	h := c.Request().Header.Get(echo.HeaderAuthorization)
+	if len(h) < len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(h[len(prefix):]), true
+}
+
+func principal(c echo.Context) (core.Principal, bool) {
+	p, ok := c.Get(principalKey).(core.Principal)
+	return p, ok
+}
+
+// keyID identifies the credential of the request in logs
+func keyID(c echo.Context) string {
+	p, _ := principal(c)
+	return p.ID
+}
@@ -0,0 +1,195 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	rsv "github.com/arxdsilva/bravo/internal/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_requireScope(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		authEnabled bool
+		key         string
+		principal   core.Principal
+		authErr     error
+		wantCalled  bool
+		wantHTTPErr *echo.HTTPError
+	}{
+		{
+			name:        "auth disabled",
+			authEnabled: false,
+			wantCalled:  true,
+		},
+		{
+			name:        "missing key",
+			authEnabled: true,
+			authErr:     core.ErrUnauthenticated,
+			wantHTTPErr: &echo.HTTPError{
+				Code:    http.StatusUnauthorized,
+				Message: core.ErrUnauthenticated.Error(),
+			},
+		},
+		{
+			name:        "key without scope",
+			authEnabled: true,
+			key:         "secret",
+			principal:   core.Principal{ID: "key", Scopes: []core.Scope{core.ScopeConvertRead}},
+			wantHTTPErr: &echo.HTTPError{
+				Code:    http.StatusForbidden,
+				Message: core.ErrForbidden.Error(),
+			},
+		},
+		{
+			name:        "key with scope",
+			authEnabled: true,
+			key:         "secret",
+			principal:   core.Principal{ID: "key", Scopes: []core.Scope{core.ScopeRatesWrite}},
+			wantCalled:  true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mock := rsv.NewMockResolver(ctrl)
+			if tt.authEnabled {
+				mock.EXPECT().AuthenticateKey(gomock.Any(), tt.key).Return(tt.principal, tt.authErr)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/convertion/rates", nil)
+			req.Header.Set(HeaderAPIKey, tt.key)
+			ctx := echo.New().NewContext(req, httptest.NewRecorder())
+
+			called := false
+			s := Server{service: mock, config: Config{AuthEnabled: tt.authEnabled}}
+			err := s.requireScope(core.ScopeRatesWrite)(func(c echo.Context) error {
+				called = true
+				require.Equal(t, tt.principal.ID, s.actor(c))
+				return nil
+			})(ctx)
+
+			require.Equal(t, tt.wantCalled, called)
+			if tt.wantHTTPErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			requireProblem(t, tt.wantHTTPErr, err)
+		})
+	}
+}
+
+func Test_actor(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		config    Config
+		principal *core.Principal
+		header    string
+		want      string
+	}{
+		{name: "principal", config: Config{AuthEnabled: true}, principal: &core.Principal{ID: "key"}, header: "other", want: "key"},
+		{name: "auth disabled ignores the header", header: "maker"},
+		{name: "trusted header", config: Config{TrustActorHeader: true}, header: "maker", want: "maker"},
+		{name: "trust needs auth disabled", config: Config{AuthEnabled: true, TrustActorHeader: true}, header: "maker"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodPost, "/v1/rates/proposals/p1/approve", nil)
+			req.Header.Set(HeaderActor, tt.header)
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+			if tt.principal != nil {
+				c.Set(principalKey, *tt.principal)
+			}
+			require.Equal(t, tt.want, Server{config: tt.config}.actor(c))
+		})
+	}
+}
+
+type stubVerifier struct {
+	principal core.Principal
+	err       error
+}
+
+func (v stubVerifier) Verify(ctx context.Context, token string) (core.Principal, error) {
+	return v.principal, v.err
+}
+
+func Test_requireScope_bearer(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		verifier    TokenVerifier
+		wantCalled  bool
+		wantCode    int
+		wantErrCode string
+	}{
+		{
+			name:        "bearer without verifier",
+			verifier:    nil,
+			wantCode:    http.StatusUnauthorized,
+			wantErrCode: "invalid_token",
+		},
+		{
+			name:        "invalid token",
+			verifier:    stubVerifier{err: core.ErrInvalidToken},
+			wantCode:    http.StatusUnauthorized,
+			wantErrCode: "invalid_token",
+		},
+		{
+			name: "role without write access",
+			verifier: stubVerifier{principal: core.Principal{
+				ID: "sub", Scopes: core.ScopesForRoles([]string{"reader"}),
+			}},
+			wantCode:    http.StatusForbidden,
+			wantErrCode: "insufficient_scope",
+		},
+		{
+			name: "role with write access",
+			verifier: stubVerifier{principal: core.Principal{
+				ID: "sub", Scopes: core.ScopesForRoles([]string{"rate-editor"}),
+			}},
+			wantCalled: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodPost, "/convertion/rates", nil)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer token")
+			rec := httptest.NewRecorder()
+			ctx := echo.New().NewContext(req, rec)
+
+			called := false
+			s := Server{verifier: tt.verifier, config: Config{AuthEnabled: true}}
+			err := s.requireScope(core.ScopeRatesWrite)(func(c echo.Context) error {
+				called = true
+				return nil
+			})(ctx)
+
+			require.Equal(t, tt.wantCalled, called)
+			if tt.wantCalled {
+				require.NoError(t, err)
+				return
+			}
+			p := problem(err)
+			require.Equal(t, tt.wantCode, p.Status)
+			require.Equal(t, tt.wantErrCode, p.Code)
+			require.Contains(t, rec.Header().Get(echo.HeaderWWWAuthenticate), tt.wantErrCode)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/labstack/echo/v4"
+)
+
+// Headers of conditional requests, RFC 9110 section 13
+const (
+	HeaderETag        = "ETag"
+	HeaderIfMatch     = "If-Match"
+	HeaderIfNoneMatch = "If-None-Match"
+)
+
+// etag is the core.ETag of v. XML and MessagePack responses share
+// the tag so If-Match works whatever the format of the client
+func etag(v interface{}, modified time.Time) (string, error) {
+	return core.ETag(v, modified)
+}
+
+// conditional sends v with its ETag and Last-Modified, answering
+// 304 Not Modified when the client copy is still current.
+// If-None-Match takes precedence over If-Modified-Since
+func conditional(c echo.Context, code int, v interface{}, modified time.Time) error {
+	tag, err := etag(v, modified)
+	if err != nil {
+		return err
+	}
+	h := c.Response().Header()
+	h.Set(HeaderETag, tag)
+	if !modified.IsZero() {
+		h.Set(echo.HeaderLastModified, modified.UTC().Format(http.TimeFormat))
+	}
+	if notModified(c.Request(), tag, modified) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return respond(c, code, v)
+}
+
+func notModified(r *http.Request, tag string, modified time.Time) bool {
+	if inm := r.Header.Get(HeaderIfNoneMatch); inm != "" {
+		return matchETag(inm, tag, true)
+	}
+	ims := r.Header.Get(echo.HeaderIfModifiedSince)
+	if ims == "" || modified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	// http dates have no sub second precision
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// ifMatch reports whether the If-Match header allows a write over
+// the representation tagged with tag, exists is false when there is
+// no current representation
+func ifMatch(r *http.Request, tag string, exists bool) bool {
+	im := r.Header.Get(HeaderIfMatch)
+	if im == "" {
+		return true
+	}
+	if !exists {
+		return false
+	}
+	return matchETag(im, tag, false)
+}
+
+// matchETag compares tag with a header list of entity tags, weak
+// tags only match on the weak comparison used by If-None-Match
+func matchETag(header, tag string, weak bool) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" {
+			return true
+		}
+		if strings.HasPrefix(t, "W/") {
+			if !weak {
+				continue
+			}
+			t = strings.TrimPrefix(t, "W/")
+		}
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
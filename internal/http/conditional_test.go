@@ -0,0 +1,201 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	rsv "github.com/arxdsilva/bravo/internal/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_conditional(t *testing.T) {
+	t.Parallel()
+	modified := time.Date(2026, 5, 1, 10, 30, 15, 500, time.UTC)
+	body := core.Currencies{{Symbol: "BRL", Description: "Brazilian Real"}}
+	tag, err := etag(body, modified)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		wantCode int
+	}{
+		{
+			name:     "no validators",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "matching etag",
+			headers:  map[string]string{HeaderIfNoneMatch: `"other", ` + tag},
+			wantCode: http.StatusNotModified,
+		},
+		{
+			name:     "weak matching etag",
+			headers:  map[string]string{HeaderIfNoneMatch: "W/" + tag},
+			wantCode: http.StatusNotModified,
+		},
+		{
+			name:     "stale etag",
+			headers:  map[string]string{HeaderIfNoneMatch: `"other"`},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "not modified since",
+			headers:  map[string]string{echo.HeaderIfModifiedSince: modified.Format(http.TimeFormat)},
+			wantCode: http.StatusNotModified,
+		},
+		{
+			name:     "modified since",
+			headers:  map[string]string{echo.HeaderIfModifiedSince: modified.Add(-time.Second).Format(http.TimeFormat)},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "etag takes precedence",
+			headers: map[string]string{
+				HeaderIfNoneMatch:          `"other"`,
+				echo.HeaderIfModifiedSince: modified.Format(http.TimeFormat),
+			},
+			wantCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodGet, "/v1/currencies", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			require.NoError(t, conditional(c, http.StatusOK, body, modified))
+			require.Equal(t, tt.wantCode, rec.Code)
+			require.Equal(t, tag, rec.Header().Get(HeaderETag))
+			require.Equal(t, modified.Format(http.TimeFormat), rec.Header().Get(echo.HeaderLastModified))
+			if tt.wantCode == http.StatusNotModified {
+				require.Empty(t, rec.Body.String())
+			}
+		})
+	}
+}
+
+func Test_etag_changesWithVersion(t *testing.T) {
+	t.Parallel()
+	c := core.Currency{Symbol: "BRL"}
+	first, err := etag(c, time.Unix(1, 0))
+	require.NoError(t, err)
+	second, err := etag(c, time.Unix(2, 0))
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+}
+
+func Test_ifMatch(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		header string
+		exists bool
+		want   bool
+	}{
+		{name: "no header", header: "", exists: false, want: true},
+		{name: "same tag", header: `"abc"`, exists: true, want: true},
+		{name: "tag in list", header: `"x", "abc"`, exists: true, want: true},
+		{name: "other tag", header: `"x"`, exists: true, want: false},
+		{name: "weak tag", header: `W/"abc"`, exists: true, want: false},
+		{name: "any", header: "*", exists: true, want: true},
+		{name: "any without current", header: "*", exists: false, want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodPut, "/v1/currencies/BRL", nil)
+			if tt.header != "" {
+				req.Header.Set(HeaderIfMatch, tt.header)
+			}
+			require.Equal(t, tt.want, ifMatch(req, `"abc"`, tt.exists))
+		})
+	}
+}
+
+func Test_UpdateCurrency_ifMatch(t *testing.T) {
+	t.Parallel()
+	version := time.Date(2026, 5, 1, 10, 30, 15, 0, time.UTC)
+	current := core.Currency{Symbol: "BRL", Description: "Real", Source: "exchange", UpdatedAt: version}
+	tag, err := etag(current, version)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		ifMatch    string
+		getErr     error
+		wantToUpdt bool
+		updtErr    error
+		wantErr    error
+	}{
+		{
+			name:       "current tag",
+			ifMatch:    tag,
+			wantToUpdt: true,
+		},
+		{
+			name:    "stale tag",
+			ifMatch: `"stale"`,
+			wantErr: core.ErrPreconditionFailed,
+		},
+		{
+			name:    "missing currency",
+			ifMatch: "*",
+			getErr:  core.ErrNotFound,
+			wantErr: core.ErrPreconditionFailed,
+		},
+		{
+			name:       "changed before the write",
+			ifMatch:    tag,
+			wantToUpdt: true,
+			updtErr:    core.ErrPreconditionFailed,
+			wantErr:    core.ErrPreconditionFailed,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mock := rsv.NewMockResolver(ctrl)
+			req := httptest.NewRequest(http.MethodPut, "/v1/currencies/BRL",
+				strings.NewReader(`{"symbol":"BRL","description":"Brazilian Real"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(HeaderIfMatch, tt.ifMatch)
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			got := current
+			if tt.getErr != nil {
+				got = core.Currency{}
+			}
+			mock.EXPECT().GetCurrency(gomock.Any(), "BRL").Return(got, tt.getErr)
+			if tt.wantToUpdt {
+				mock.EXPECT().UpdateCurrency(gomock.Any(), "BRL", "Brazilian Real", version).Return(tt.updtErr)
+			}
+
+			s := Server{service: mock}
+			err := s.UpdateCurrency(c)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, http.StatusPreconditionFailed, problem(err).Status)
+		})
+	}
+}
@@ -1,5 +1,32 @@
 package http
 
+import (
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/graphql"
+)
+
 type Config struct {
 	Port int `envconfig:"APP_HTTP_PORT" default:"8888"`
+	// AuthEnabled requires an api key with the route scope on every route
+	AuthEnabled bool `envconfig:"APP_AUTH_ENABLED" default:"true"`
+	// TrustActorHeader takes the identity of the maker-checker flow
+	// from X-Actor while AuthEnabled is off. Anyone can claim any
+	// identity with it, so it is only meant for local development.
+	// Without it rate changes are refused while auth is disabled
+	TrustActorHeader bool `envconfig:"APP_DEV_TRUST_ACTOR" default:"false"`
+	// RateLimits is the sustained requests per second and RateBursts
+	// the bucket size of each client per route group, the ip group
+	// limits each address before the authentication
+	RateLimits map[string]float64 `envconfig:"APP_RATE_LIMITS" default:"convert:10,read:20,write:2,admin:2,ip:50"`
+	RateBursts map[string]int     `envconfig:"APP_RATE_BURSTS" default:"convert:20,read:40,write:5,admin:5,ip:100"`
+	// MonthlyQuota is the amount of requests a client can make per month, 0 disables it
+	MonthlyQuota int64 `envconfig:"APP_MONTHLY_QUOTA" default:"0"`
+	// LegacySunset is announced on unversioned routes as the date
+	// they stop being served
+	LegacySunset time.Time `envconfig:"APP_LEGACY_SUNSET" default:"2027-06-30T00:00:00Z"`
+	// StreamHeartbeat is how often an idle rate stream gets a comment
+	// so proxies and clients do not close it
+	StreamHeartbeat time.Duration `envconfig:"APP_STREAM_HEARTBEAT" default:"15s"`
+	GraphQL         graphql.Config
 }
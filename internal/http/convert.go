@@ -2,52 +2,118 @@ package http
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/export"
 	"github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
 )
 
-// Convert retrieves a conversion from two currencies
+// HeaderAcceptLanguage picks the locale of the formatted amounts when
+// there is no locale param
+const HeaderAcceptLanguage = "Accept-Language"
+
+// Convert retrieves a conversion from two currencies, the amount can
+// be written in the locale param, otherwise it is plain and refused
+// when the Accept-Language of the client would read it differently
 //
 // HTTP responses:
 // 200 OK
 // 400 Bad request
 // 500 Internal Server Error
+// 502 Bad Gateway
+// 503 Service Unavailable
+// 504 Gateway Timeout
 func (s Server) Convert(c echo.Context) (err error) {
 	lg := log.WithFields(log.Fields{
-		"pkg":   "http",
-		"route": "convert",
-		"cid":   c.Response().Header().Get(echo.HeaderXRequestID),
+		"pkg":    "http",
+		"route":  "convert",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
 	})
 	conv := core.ConversionAPI{
 		From:   c.QueryParam("from"),
 		To:     c.QueryParam("to"),
 		Amount: c.QueryParam("amount"),
+		At:     c.QueryParam("at"),
+
+		Locale:         c.QueryParam("locale"),
+		AcceptLanguage: c.Request().Header.Get(HeaderAcceptLanguage),
 	}
+	c.Response().Header().Add(echo.HeaderVary, HeaderAcceptLanguage)
 
 	if err := conv.Check(); err != nil {
 		lg.WithError(err).Error("check")
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	convService, shouldConvert, err := core.ConvertToService(conv)
 	if err != nil {
 		lg.WithError(err).Error("convertToService")
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
+	// Check has already validated the locale
+	nf, _ := conv.NumberFormat()
+
 	if !shouldConvert {
 		lg.WithField("should_convert", shouldConvert).Info("should_convert")
-		return c.JSON(http.StatusOK, core.TransformSVCToResp(convService, convService.Amount, "no-edit"))
+		resp := core.TransformSVCToResp(convService, convService.Amount, "no-edit")
+		resp.Formatted = nf.FormatConversion(resp)
+		return respond(c, http.StatusOK, resp)
 	}
 
 	amount, source, err := s.service.Convert(c.Request().Context(), convService)
 	if err != nil {
 		lg.WithError(err).Error("service.Convert")
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	lg.Info("success")
-	return c.JSON(http.StatusOK, core.TransformSVCToResp(convService, amount, source))
+	resp := core.TransformSVCToResp(convService, amount, source)
+	resp.Formatted = nf.FormatConversion(resp)
+	return respond(c, http.StatusOK, resp)
+}
+
+// GetConversionHistory downloads the conversions made in the
+// [since, until) window of the optional RFC3339 query params. The
+// history is only served as a file, CSV unless XLSX is asked with
+// `Accept` or the `format` query param, see exportConversions
+//
+// HTTP responses:
+// 200 OK
+// 400 Bad Request
+// 500 Internal Server Error
+func (s Server) GetConversionHistory(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "GetConversionHistory",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+
+	var since, until time.Time
+	if q := c.QueryParam("since"); q != "" {
+		if since, err = time.Parse(time.RFC3339, q); err != nil {
+			lg.WithError(err).Error("time.Parse")
+			return core.ErrInvalidHistoryRange
+		}
+	}
+	if q := c.QueryParam("until"); q != "" {
+		if until, err = time.Parse(time.RFC3339, q); err != nil {
+			lg.WithError(err).Error("time.Parse")
+			return core.ErrInvalidHistoryRange
+		}
+	}
+
+	format, ok, err := exportFormat(c)
+	if err != nil {
+		lg.WithError(err).Error("exportFormat")
+		return err
+	}
+	if !ok {
+		format = export.FormatCSV
+	}
+	return s.exportConversions(c, lg, since, until, format)
 }
@@ -23,6 +23,8 @@ func Test_Convert(t *testing.T) {
 		wantToConv bool
 		from, to   string
 		amount     string
+		locale     string
+		acceptLang string
 
 		wantConvSVC core.ConversionSVC
 
@@ -115,7 +117,7 @@ func Test_Convert(t *testing.T) {
 			wantCode:  http.StatusInternalServerError,
 			wantHTTPErr: &echo.HTTPError{
 				Code:     http.StatusInternalServerError,
-				Message:  "some err",
+				Message:  "Internal Server Error",
 				Internal: nil,
 			},
 		},
@@ -136,6 +138,61 @@ func Test_Convert(t *testing.T) {
 			wantCode:    http.StatusOK,
 			wantHTTPErr: nil,
 		},
+		{
+			name:   "locale param",
+			from:   "USD",
+			to:     "BRL",
+			amount: "1.234,56",
+			locale: "pt-BR",
+
+			wantToConv:  true,
+			convAmount:  6172.8,
+			convSource:  "manual",
+			wantConvSVC: core.ConversionSVC{From: "USD", To: "BRL", Amount: 1234.56},
+
+			wantBody: "{\"from\":\"USD\",\"to\":\"BRL\",\"original_amount\":1234.56,\"converted_amount\":6172.8,\"conversion_source\":\"manual\"," +
+				"\"formatted\":{\"locale\":\"pt-BR\",\"original_amount\":\"US$\u00a01.234,56\",\"converted_amount\":\"R$\u00a06.172,80\"}}\n",
+			wantErrFn: require.NoError,
+			wantCode:  http.StatusOK,
+		},
+		{
+			name:       "accept language",
+			from:       "EUR",
+			to:         "EUR",
+			amount:     "10.5",
+			acceptLang: "sw, de-DE;q=0.8",
+
+			wantBody: "{\"from\":\"EUR\",\"to\":\"EUR\",\"original_amount\":10.5,\"converted_amount\":10.5,\"conversion_source\":\"no-edit\"," +
+				"\"formatted\":{\"locale\":\"de-DE\",\"original_amount\":\"10,50\u00a0€\",\"converted_amount\":\"10,50\u00a0€\"}}\n",
+			wantErrFn: require.NoError,
+			wantCode:  http.StatusOK,
+		},
+		{
+			name:       "ambiguous in the accept language",
+			from:       "USD",
+			to:         "BRL",
+			amount:     "1.234",
+			acceptLang: "pt-BR",
+
+			wantErrFn: require.Error,
+			wantHTTPErr: &echo.HTTPError{
+				Code:    http.StatusBadRequest,
+				Message: core.ErrAmbiguousAmount.Error(),
+			},
+		},
+		{
+			name:   "invalid locale",
+			from:   "USD",
+			to:     "BRL",
+			amount: "10",
+			locale: "not a locale",
+
+			wantErrFn: require.Error,
+			wantHTTPErr: &echo.HTTPError{
+				Code:    http.StatusBadRequest,
+				Message: "locale has to be a BCP 47 language tag, ex: pt-BR",
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -150,9 +207,13 @@ func Test_Convert(t *testing.T) {
 			vals.Set("from", tt.from)
 			vals.Set("to", tt.to)
 			vals.Set("amount", tt.amount)
+			if tt.locale != "" {
+				vals.Set("locale", tt.locale)
+			}
 
 			req := httptest.NewRequest(http.MethodGet, "/convertion/convert?"+vals.Encode(), nil)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(HeaderAcceptLanguage, tt.acceptLang)
 
 			rec := httptest.NewRecorder()
 			ctx := echo.New().NewContext(req, rec)
@@ -173,7 +234,7 @@ func Test_Convert(t *testing.T) {
 				require.Equal(t, tt.wantBody, string(b))
 				return
 			}
-			require.Equal(t, tt.wantHTTPErr, err)
+			requireProblem(t, tt.wantHTTPErr, err)
 		})
 	}
 }
@@ -3,30 +3,71 @@ package http
 import (
 	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/arxdsilva/bravo/internal/core"
 	"github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
 )
 
-// GetCurrencies retrieves currencies from DB and external exchange
+// GetCurrencies lists a page of the stored currencies, the next
+// page is linked on the Link header with rel="next"
+//
+// Query params: q, source, kind, include_deleted, sort, limit and cursor
 //
 // HTTP responses:
 // 200 OK
+// 304 Not Modified
+// 400 Bad Request
 // 500 Internal Server Error
 func (s Server) GetCurrencies(c echo.Context) (err error) {
 	lg := log.WithFields(log.Fields{
-		"pkg":   "http",
-		"route": "GetCurrencies",
-		"cid":   c.Response().Header().Get(echo.HeaderXRequestID),
+		"pkg":    "http",
+		"route":  "GetCurrencies",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
 	})
-	currencies, err := s.service.GetCurrencies(c.Request().Context())
+
+	f := core.CurrencyFilter{
+		Q:      c.QueryParam("q"),
+		Source: c.QueryParam("source"),
+		Kind:   c.QueryParam("kind"),
+		Sort:   c.QueryParam("sort"),
+		Cursor: c.QueryParam("cursor"),
+	}
+	if qLimit := c.QueryParam("limit"); qLimit != "" {
+		if f.Limit, err = strconv.Atoi(qLimit); err != nil || f.Limit == 0 {
+			lg.WithError(err).Error("strconv.Atoi")
+			return core.ErrInvalidLimit
+		}
+	}
+	if qDeleted := c.QueryParam("include_deleted"); qDeleted != "" {
+		if f.IncludeDeleted, err = strconv.ParseBool(qDeleted); err != nil {
+			lg.WithError(err).Error("strconv.ParseBool")
+			return core.ErrInvalidIncludeDeleted
+		}
+	}
+
+	page, err := s.service.GetCurrencies(c.Request().Context(), f)
 	if err != nil {
 		lg.WithError(err).Error("service.GetCurrencies")
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
+	}
+	if page.Next != "" {
+		c.Response().Header().Add(HeaderLink, "<"+pageURL(c, page.Next)+`>; rel="next"`)
 	}
 	lg.Info("success")
-	return c.JSON(http.StatusOK, currencies)
+	return conditional(c, http.StatusOK, page.Currencies, page.Currencies.LastModified())
+}
+
+// pageURL is the request URL pointing at the given cursor
+func pageURL(c echo.Context, cursor string) string {
+	u := *c.Request().URL
+	q := u.Query()
+	q.Set("cursor", cursor)
+	u.RawQuery = q.Encode()
+	return u.RequestURI()
 }
 
 // AddCurrency retrieves currencies from DB and external exchange
@@ -37,87 +78,99 @@ func (s Server) GetCurrencies(c echo.Context) (err error) {
 // 500 Internal Server Error
 func (s Server) AddCurrency(c echo.Context) (err error) {
 	lg := log.WithFields(log.Fields{
-		"pkg":   "http",
-		"route": "AddCurrency",
-		"cid":   c.Response().Header().Get(echo.HeaderXRequestID),
+		"pkg":    "http",
+		"route":  "AddCurrency",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
 	})
 
 	currency := &core.Currency{}
 
-	if err = c.Bind(currency); err != nil {
-		lg.WithError(err).Error("c.Bind")
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err = bind(c, currency); err != nil {
+		lg.WithError(err).Error("bind")
+		return err
 	}
 
 	if err = currency.Check(); err != nil {
 		lg.WithError(err).Error("currency.Check")
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	err = s.service.AddCurrency(
 		c.Request().Context(), currency.Symbol, currency.Description)
 	if err != nil {
 		lg.WithError(err).Error("service.AddCurrency")
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	lg.Info("success")
-	return c.JSON(http.StatusCreated, currency)
+	return respond(c, http.StatusCreated, currency)
 }
 
-// UpdateCurrency retrieves currencies from DB and external exchange
+// UpdateCurrency changes the currency description, with If-Match the
+// change is only made when the currency still has that ETag
 //
 // HTTP responses:
 // 204 No Content
 // 400 Bad Request
+// 404 Not Found
+// 412 Precondition Failed
 // 500 Internal Server Error
 func (s Server) UpdateCurrency(c echo.Context) (err error) {
 	lg := log.WithFields(log.Fields{
-		"pkg":   "http",
-		"route": "UpdateCurrency",
-		"cid":   c.Response().Header().Get(echo.HeaderXRequestID),
+		"pkg":    "http",
+		"route":  "UpdateCurrency",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
 	})
 
 	currency := &core.Currency{}
 
-	if err = c.Bind(currency); err != nil {
-		lg.WithError(err).Error("c.Bind")
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err = bind(c, currency); err != nil {
+		lg.WithError(err).Error("bind")
+		return err
 	}
 
 	if err = currency.Check(); err != nil {
 		lg.WithError(err).Error("currency.Check")
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	err = s.service.UpdateCurrency(
-		c.Request().Context(), currency.Symbol, currency.Description)
-	if err != nil && err != core.ErrNotFound {
-		lg.WithError(err).Error("service.UpdateCurrency")
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	version, err := s.currencyVersion(c, currency.Symbol)
+	if err != nil {
+		lg.WithError(err).Warn("s.currencyVersion")
+		return err
 	}
 
+	err = s.service.UpdateCurrency(
+		c.Request().Context(), currency.Symbol, currency.Description, version)
 	if errors.Is(err, core.ErrNotFound) {
-		lg.WithError(core.ErrCurrencyNotFound).Error("not found")
-		return echo.NewHTTPError(http.StatusBadRequest, core.ErrCurrencyNotFound.Error())
+		lg.WithError(core.ErrCurrencyNotFound).Warn("not found")
+		return core.ErrCurrencyNotFound
+	}
+	if err != nil {
+		lg.WithError(err).Error("service.UpdateCurrency")
+		return err
 	}
 
 	lg.Info("success")
-	return c.JSON(http.StatusCreated, currency)
+	return respond(c, http.StatusCreated, currency)
 }
 
 // GetCurrency retrieves a currency from DB
 //
 // HTTP responses:
 // 200 OK
+// 304 Not Modified
 // 400 Bad Request
 // 404 Not Found
 // 500 Internal Server Error
 func (s Server) GetCurrency(c echo.Context) (err error) {
 	lg := log.WithFields(log.Fields{
-		"pkg":   "http",
-		"route": "GetCurrency",
-		"cid":   c.Response().Header().Get(echo.HeaderXRequestID),
+		"pkg":    "http",
+		"route":  "GetCurrency",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
 	})
 	symbol := c.Param("symbol")
 
@@ -125,22 +178,21 @@ func (s Server) GetCurrency(c echo.Context) (err error) {
 
 	if err = cr.Check(); err != nil {
 		lg.WithError(err).Error("currency.Check")
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	svcCurrency, err := s.service.GetCurrency(c.Request().Context(), cr.Symbol)
-	if err != nil && err != core.ErrNotFound {
-		lg.WithError(err).Error("service.GetCurrency")
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-
 	if errors.Is(err, core.ErrNotFound) {
-		lg.WithError(core.ErrCurrencyNotFound).Error("not found")
-		return echo.NewHTTPError(http.StatusBadRequest, core.ErrCurrencyNotFound.Error())
+		lg.WithError(core.ErrCurrencyNotFound).Warn("not found")
+		return core.ErrCurrencyNotFound
+	}
+	if err != nil {
+		lg.WithError(err).Error("service.GetCurrency")
+		return err
 	}
 
 	lg.Info("success")
-	return c.JSON(http.StatusOK, svcCurrency)
+	return conditional(c, http.StatusOK, svcCurrency, svcCurrency.UpdatedAt)
 }
 
 // RemoveCurrency retrieves a currency from DB
@@ -152,9 +204,10 @@ func (s Server) GetCurrency(c echo.Context) (err error) {
 // 500 Internal Server Error
 func (s Server) RemoveCurrency(c echo.Context) (err error) {
 	lg := log.WithFields(log.Fields{
-		"pkg":   "http",
-		"route": "RemoveCurrency",
-		"cid":   c.Response().Header().Get(echo.HeaderXRequestID),
+		"pkg":    "http",
+		"route":  "RemoveCurrency",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
 	})
 	symbol := c.Param("symbol")
 
@@ -162,20 +215,39 @@ func (s Server) RemoveCurrency(c echo.Context) (err error) {
 
 	if err = cr.Check(); err != nil {
 		lg.WithError(err).Error("currency.Check")
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	err = s.service.RemoveCurrency(c.Request().Context(), cr.Symbol)
-	if err != nil && err != core.ErrNotFound {
-		lg.WithError(err).Error("service.RemoveCurrency")
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-
 	if errors.Is(err, core.ErrNotFound) {
-		lg.WithError(core.ErrCurrencyNotFound).Error("not found")
-		return echo.NewHTTPError(http.StatusBadRequest, core.ErrCurrencyNotFound.Error())
+		lg.WithError(core.ErrCurrencyNotFound).Warn("not found")
+		return core.ErrCurrencyNotFound
+	}
+	if err != nil {
+		lg.WithError(err).Error("service.RemoveCurrency")
+		return err
 	}
 
 	lg.Info("success")
 	return c.NoContent(http.StatusNoContent)
 }
+
+// currencyVersion checks If-Match against the current currency and
+// returns the version the write has to find, zero without If-Match
+func (s Server) currencyVersion(c echo.Context, symbol string) (time.Time, error) {
+	if c.Request().Header.Get(HeaderIfMatch) == "" {
+		return time.Time{}, nil
+	}
+	cur, err := s.service.GetCurrency(c.Request().Context(), symbol)
+	if err != nil && !errors.Is(err, core.ErrNotFound) {
+		return time.Time{}, err
+	}
+	tag, err := etag(cur, cur.UpdatedAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !ifMatch(c.Request(), tag, cur.Symbol != "") {
+		return time.Time{}, core.ErrPreconditionFailed
+	}
+	return cur.UpdatedAt, nil
+}
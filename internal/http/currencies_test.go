@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/arxdsilva/bravo/internal/core"
 	rsv "github.com/arxdsilva/bravo/internal/service/mock"
@@ -19,40 +20,93 @@ import (
 func Test_GetCurrencies(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		name string
+		name  string
+		query string
 
-		getCurrenciesResp core.Currencies
+		wantToGet         bool
+		wantFilter        core.CurrencyFilter
+		getCurrenciesResp core.CurrencyPage
 		getCurrenciesErr  error
 
 		wantBody    string
+		wantLink    string
 		wantErrFn   require.ErrorAssertionFunc
 		wantCode    int
 		wantHTTPErr *echo.HTTPError
 	}{
 		{
 			name:              "retrieve error",
-			getCurrenciesResp: core.Currencies{},
+			wantToGet:         true,
+			getCurrenciesResp: core.CurrencyPage{},
 			getCurrenciesErr:  errors.New("some err"),
 			wantBody:          "",
 			wantErrFn:         require.Error,
 			wantCode:          http.StatusInternalServerError,
 			wantHTTPErr: &echo.HTTPError{
 				Code:     http.StatusInternalServerError,
-				Message:  "some err",
+				Message:  "Internal Server Error",
 				Internal: nil,
 			},
 		},
 		{
-			name: "no error",
-			getCurrenciesResp: core.Currencies{
-				core.Currency{Symbol: "BRL"},
+			name:      "invalid limit",
+			query:     "limit=many",
+			wantToGet: false,
+			wantErrFn: require.Error,
+			wantCode:  http.StatusBadRequest,
+			wantHTTPErr: &echo.HTTPError{
+				Code:     http.StatusBadRequest,
+				Message:  "limit has to be between 1 and 200",
+				Internal: nil,
 			},
+		},
+		{
+			name:      "invalid include_deleted",
+			query:     "include_deleted=maybe",
+			wantToGet: false,
+			wantErrFn: require.Error,
+			wantCode:  http.StatusBadRequest,
+			wantHTTPErr: &echo.HTTPError{
+				Code:     http.StatusBadRequest,
+				Message:  "include_deleted has to be true or false",
+				Internal: nil,
+			},
+		},
+		{
+			name:      "no error",
+			wantToGet: true,
+			getCurrenciesResp: core.CurrencyPage{Currencies: core.Currencies{
+				core.Currency{Symbol: "BRL"},
+			}},
 			getCurrenciesErr: nil,
 			wantBody:         "[{\"symbol\":\"BRL\",\"description\":\"\",\"source\":\"\"}]\n",
 			wantErrFn:        require.NoError,
 			wantCode:         http.StatusOK,
 			wantHTTPErr:      nil,
 		},
+		{
+			name:      "filtered page with next",
+			query:     "q=real&kind=fiat&source=exchange&include_deleted=true&sort=-description&limit=1",
+			wantToGet: true,
+			wantFilter: core.CurrencyFilter{
+				Q:              "real",
+				Source:         "exchange",
+				Kind:           core.CurrencyFiat,
+				IncludeDeleted: true,
+				Sort:           "-description",
+				Limit:          1,
+			},
+			getCurrenciesResp: core.CurrencyPage{
+				Currencies: core.Currencies{
+					core.Currency{Symbol: "BRL", Description: "Brazilian Real", Source: "exchange", Kind: core.CurrencyFiat},
+				},
+				Next: "abc",
+			},
+			wantBody:  "[{\"symbol\":\"BRL\",\"description\":\"Brazilian Real\",\"source\":\"exchange\",\"kind\":\"fiat\"}]\n",
+			wantLink:  "</currencies?cursor=abc&include_deleted=true&kind=fiat&limit=1&q=real&sort=-description&source=exchange>; rel=\"next\"",
+			wantErrFn: require.NoError,
+			wantCode:  http.StatusOK,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -63,7 +117,7 @@ func Test_GetCurrencies(t *testing.T) {
 
 			mock := rsv.NewMockResolver(ctrl)
 
-			req, err := http.NewRequest(http.MethodGet, "/currencies", nil)
+			req, err := http.NewRequest(http.MethodGet, "/currencies?"+tt.query, nil)
 			require.NoError(t, err)
 
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
@@ -72,19 +126,22 @@ func Test_GetCurrencies(t *testing.T) {
 			ctx := echo.New().NewContext(req, rec)
 			ctx.SetPath("/currencies")
 
-			mock.EXPECT().GetCurrencies(gomock.Any()).Return(tt.getCurrenciesResp, tt.getCurrenciesErr)
+			if tt.wantToGet {
+				mock.EXPECT().GetCurrencies(gomock.Any(), tt.wantFilter).Return(tt.getCurrenciesResp, tt.getCurrenciesErr)
+			}
 
 			s := Server{service: mock}
 			err = s.GetCurrencies(ctx)
 			tt.wantErrFn(t, err)
 			if err == nil {
 				require.Equal(t, tt.wantCode, rec.Code)
+				require.Equal(t, tt.wantLink, rec.Header().Get(HeaderLink))
 				b, err := io.ReadAll(rec.Body)
 				require.NoError(t, err)
 				require.Equal(t, tt.wantBody, string(b))
 				return
 			}
-			require.Equal(t, tt.wantHTTPErr, err)
+			requireProblem(t, tt.wantHTTPErr, err)
 		})
 	}
 }
@@ -148,7 +205,7 @@ func Test_AddCurrency(t *testing.T) {
 			wantCode:       http.StatusInternalServerError,
 			wantHTTPErr: &echo.HTTPError{
 				Code:     http.StatusInternalServerError,
-				Message:  "some err",
+				Message:  "Internal Server Error",
 				Internal: nil,
 			},
 		},
@@ -201,7 +258,7 @@ func Test_AddCurrency(t *testing.T) {
 				require.Equal(t, tt.wantBody, string(b))
 				return
 			}
-			require.Equal(t, tt.wantHTTPErr, err)
+			requireProblem(t, tt.wantHTTPErr, err)
 		})
 	}
 }
@@ -265,7 +322,7 @@ func Test_UpdateCurrency(t *testing.T) {
 			wantCode:          http.StatusInternalServerError,
 			wantHTTPErr: &echo.HTTPError{
 				Code:     http.StatusInternalServerError,
-				Message:  "some err",
+				Message:  "Internal Server Error",
 				Internal: nil,
 			},
 		},
@@ -278,9 +335,9 @@ func Test_UpdateCurrency(t *testing.T) {
 			updateCurrencyErr: core.ErrNotFound,
 			wantBody:          "",
 			wantErrFn:         require.Error,
-			wantCode:          http.StatusBadRequest,
+			wantCode:          http.StatusNotFound,
 			wantHTTPErr: &echo.HTTPError{
-				Code:     http.StatusBadRequest,
+				Code:     http.StatusNotFound,
 				Message:  core.ErrCurrencyNotFound.Error(),
 				Internal: nil,
 			},
@@ -320,7 +377,7 @@ func Test_UpdateCurrency(t *testing.T) {
 			ctx.SetPath("/currencies")
 
 			if tt.wantToUpdt {
-				mock.EXPECT().UpdateCurrency(gomock.Any(), tt.sentCurrency.Symbol, tt.sentCurrency.Description).
+				mock.EXPECT().UpdateCurrency(gomock.Any(), tt.sentCurrency.Symbol, tt.sentCurrency.Description, time.Time{}).
 					Return(tt.updateCurrencyErr)
 			}
 
@@ -334,7 +391,7 @@ func Test_UpdateCurrency(t *testing.T) {
 				require.Equal(t, tt.wantBody, string(b))
 				return
 			}
-			require.Equal(t, tt.wantHTTPErr, err)
+			requireProblem(t, tt.wantHTTPErr, err)
 		})
 	}
 }
@@ -381,7 +438,7 @@ func Test_GetCurrency(t *testing.T) {
 			wantCode:        http.StatusInternalServerError,
 			wantHTTPErr: &echo.HTTPError{
 				Code:     http.StatusInternalServerError,
-				Message:  "some err",
+				Message:  "Internal Server Error",
 				Internal: nil,
 			},
 		},
@@ -393,9 +450,9 @@ func Test_GetCurrency(t *testing.T) {
 			getCurrencyErr:  core.ErrNotFound,
 			wantBody:        "",
 			wantErrFn:       require.Error,
-			wantCode:        http.StatusBadRequest,
+			wantCode:        http.StatusNotFound,
 			wantHTTPErr: &echo.HTTPError{
-				Code:     http.StatusBadRequest,
+				Code:     http.StatusNotFound,
 				Message:  core.ErrCurrencyNotFound.Error(),
 				Internal: nil,
 			},
@@ -452,7 +509,7 @@ func Test_GetCurrency(t *testing.T) {
 				require.Equal(t, tt.wantBody, string(b))
 				return
 			}
-			require.Equal(t, tt.wantHTTPErr, err)
+			requireProblem(t, tt.wantHTTPErr, err)
 		})
 	}
 }
@@ -496,7 +553,7 @@ func Test_RemoveCurrency(t *testing.T) {
 			wantCode:       http.StatusInternalServerError,
 			wantHTTPErr: &echo.HTTPError{
 				Code:     http.StatusInternalServerError,
-				Message:  "some err",
+				Message:  "Internal Server Error",
 				Internal: nil,
 			},
 		},
@@ -507,9 +564,9 @@ func Test_RemoveCurrency(t *testing.T) {
 			rmvCurrencyErr: core.ErrNotFound,
 			wantBody:       "",
 			wantErrFn:      require.Error,
-			wantCode:       http.StatusBadRequest,
+			wantCode:       http.StatusNotFound,
 			wantHTTPErr: &echo.HTTPError{
-				Code:     http.StatusBadRequest,
+				Code:     http.StatusNotFound,
 				Message:  core.ErrCurrencyNotFound.Error(),
 				Internal: nil,
 			},
@@ -563,7 +620,7 @@ func Test_RemoveCurrency(t *testing.T) {
 				require.Equal(t, tt.wantBody, string(b))
 				return
 			}
-			require.Equal(t, tt.wantHTTPErr, err)
+			requireProblem(t, tt.wantHTTPErr, err)
 		})
 	}
 }
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/arxdsilva/bravo/internal/metrics"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// Headers announcing the removal of legacy routes
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+	HeaderLink        = "Link"
+)
+
+// deprecated sets the Deprecation, Sunset and successor Link headers
+// and logs which clients still call the legacy route
+func (s Server) deprecated(successor string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			h := c.Response().Header()
+			h.Set(HeaderDeprecation, "true")
+			if !s.config.LegacySunset.IsZero() {
+				h.Set(HeaderSunset, s.config.LegacySunset.UTC().Format(http.TimeFormat))
+			}
+			h.Set(HeaderLink, "<"+successorPath(c, successor)+`>; rel="successor-version"`)
+
+			err := next(c)
+
+			metrics.LegacyRequests.WithLabelValues(c.Request().Method, c.Path()).Inc()
+			log.WithFields(log.Fields{
+				"pkg":       "http",
+				"route":     c.Path(),
+				"cid":       c.Response().Header().Get(echo.HeaderXRequestID),
+				"client":    clientID(c),
+				"successor": successor,
+			}).Warn("legacy route called")
+			return err
+		}
+	}
+}
+
+// successorPath fills the params of the successor route with the
+// values of the current request
+func successorPath(c echo.Context, successor string) string {
+	for i, name := range c.ParamNames() {
+		successor = strings.Replace(successor, ":"+name, c.ParamValues()[i], 1)
+	}
+	return successor
+}
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_route(t *testing.T) {
+	t.Parallel()
+	sunset := time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC)
+	s := Server{config: Config{LegacySunset: sunset}}
+	e := echo.New()
+	s.route(e, http.MethodGet, "/things/:id", "/old/things/:id", func(c echo.Context) error {
+		return c.String(http.StatusOK, c.Param("id"))
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/things/42", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "42", rec.Body.String())
+	require.Empty(t, rec.Header().Get(HeaderDeprecation))
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/old/things/42", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "42", rec.Body.String())
+	require.Equal(t, "true", rec.Header().Get(HeaderDeprecation))
+	require.Equal(t, "Wed, 30 Jun 2027 00:00:00 GMT", rec.Header().Get(HeaderSunset))
+	require.Equal(t, `</v1/things/42>; rel="successor-version"`, rec.Header().Get(HeaderLink))
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// MIMEProblemJSON is the content type of error responses, RFC 7807
+const MIMEProblemJSON = "application/problem+json"
+
+// Problem is the body of every error response
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Code      string `json:"code"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+var kindStatus = map[core.Kind]int{
+	core.KindInternal:        http.StatusInternalServerError,
+	core.KindInvalid:         http.StatusBadRequest,
+	core.KindUnauthenticated: http.StatusUnauthorized,
+	core.KindForbidden:       http.StatusForbidden,
+	core.KindNotFound:        http.StatusNotFound,
+	core.KindConflict:        http.StatusConflict,
+	core.KindUnprocessable:   http.StatusUnprocessableEntity,
+	core.KindRateLimited:     http.StatusTooManyRequests,
+	core.KindUpstream:        http.StatusBadGateway,
+	core.KindUnavailable:     http.StatusServiceUnavailable,
+	core.KindTimeout:         http.StatusGatewayTimeout,
+	core.KindPrecondition:    http.StatusPreconditionFailed,
+}
+
+// problem maps err to its response
+func problem(err error) Problem {
+	var ce *core.Error
+	if errors.As(err, &ce) {
+		p := Problem{Status: kindStatus[ce.Kind], Code: ce.Code, Detail: err.Error()}
+		if ce.Kind.ServerFault() {
+			p.Detail = ce.Message
+		}
+		return p.titled()
+	}
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		p := Problem{Status: he.Code, Code: statusCode(he.Code)}
+		if p.Status < http.StatusInternalServerError {
+			p.Detail = fmt.Sprint(he.Message)
+		}
+		return p.titled()
+	}
+	return Problem{Status: http.StatusInternalServerError, Code: "internal_error"}.titled()
+}
+
+func (p Problem) titled() Problem {
+	p.Type = "about:blank"
+	p.Title = http.StatusText(p.Status)
+	if p.Detail == "" {
+		p.Detail = p.Title
+	}
+	return p
+}
+
+// statusCode derives a code from the status of errors raised by echo
+// itself, ex: 405 is method_not_allowed
+func statusCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	return strings.ToLower(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+}
+
+// HTTPErrorHandler renders the errors returned by handlers and
+// middlewares as problem details
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+	p := problem(err)
+	p.Instance = c.Request().URL.Path
+	p.RequestID = c.Response().Header().Get(echo.HeaderXRequestID)
+
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  c.Path(),
+		"cid":    p.RequestID,
+		"status": p.Status,
+		"code":   p.Code,
+	})
+	if p.Status >= http.StatusInternalServerError {
+		lg.WithError(err).Error("request failed")
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(p.Status)
+	} else {
+		err = send(c, p.Status, p, true)
+	}
+	if err != nil {
+		lg.WithError(err).Error("HTTPErrorHandler")
+	}
+}
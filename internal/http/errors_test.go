@@ -0,0 +1,118 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+// requireProblem asserts the status and detail err is rendered with
+func requireProblem(t *testing.T, want *echo.HTTPError, err error) {
+	t.Helper()
+	p := problem(err)
+	require.Equal(t, want.Code, p.Status)
+	require.Equal(t, want.Message, p.Detail)
+}
+
+func Test_problem(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantDetail string
+	}{
+		{
+			name:       "invalid",
+			err:        core.ErrSymbolMinLen,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "symbol_too_short",
+			wantDetail: core.ErrSymbolMinLen.Error(),
+		},
+		{
+			name:       "not found",
+			err:        core.ErrCurrencyNotFound,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "currency_not_found",
+			wantDetail: core.ErrCurrencyNotFound.Error(),
+		},
+		{
+			name:       "wrapped keeps the detail",
+			err:        fmt.Errorf("%w: 6 is 20%% away", core.ErrRateDeviation),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "rate_deviation",
+			wantDetail: core.ErrRateDeviation.Error() + ": 6 is 20% away",
+		},
+		{
+			name:       "upstream hides the cause",
+			err:        fmt.Errorf("%w: dial tcp 10.0.0.1:443", core.ErrProviderUnavailable),
+			wantStatus: http.StatusBadGateway,
+			wantCode:   "provider_error",
+			wantDetail: core.ErrProviderUnavailable.Error(),
+		},
+		{
+			name:       "timeout",
+			err:        fmt.Errorf("%w: deadline", core.ErrProviderTimeout),
+			wantStatus: http.StatusGatewayTimeout,
+			wantCode:   "provider_timeout",
+			wantDetail: core.ErrProviderTimeout.Error(),
+		},
+		{
+			name:       "echo error",
+			err:        echo.ErrMethodNotAllowed,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantCode:   "method_not_allowed",
+			wantDetail: "Method Not Allowed",
+		},
+		{
+			name:       "unknown",
+			err:        errors.New("pq: password authentication failed"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "internal_error",
+			wantDetail: "Internal Server Error",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p := problem(tt.err)
+			require.Equal(t, tt.wantStatus, p.Status)
+			require.Equal(t, tt.wantCode, p.Code)
+			require.Equal(t, tt.wantDetail, p.Detail)
+			require.Equal(t, http.StatusText(tt.wantStatus), p.Title)
+		})
+	}
+}
+
+func Test_HTTPErrorHandler(t *testing.T) {
+	t.Parallel()
+	req := httptest.NewRequest(http.MethodGet, "/v1/currencies/XYZ", nil)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.Response().Header().Set(echo.HeaderXRequestID, "rid")
+
+	HTTPErrorHandler(core.ErrCurrencyNotFound, c)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Equal(t, MIMEProblemJSON, rec.Header().Get(echo.HeaderContentType))
+	p := Problem{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	require.Equal(t, Problem{
+		Type:      "about:blank",
+		Title:     "Not Found",
+		Status:    http.StatusNotFound,
+		Detail:    core.ErrCurrencyNotFound.Error(),
+		Code:      "currency_not_found",
+		Instance:  "/v1/currencies/XYZ",
+		RequestID: "rid",
+	}, p)
+}
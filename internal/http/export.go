@@ -0,0 +1,103 @@
+package http
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/export"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// rateColumns is the layout of rate exports, columns are only ever
+// appended so spreadsheets built on top of them keep working
+var rateColumns = []string{"from", "to", "rate", "valid_from", "valid_to"}
+
+// conversionColumns is the layout of conversion history exports
+var conversionColumns = []string{
+	"id", "from", "to", "original_amount", "converted_amount", "conversion_source", "at", "created_at",
+}
+
+// exportFormat picks the format of a download, the format query param
+// takes precedence over Accept. ok is false when JSON is wanted
+func exportFormat(c echo.Context) (f export.Format, ok bool, err error) {
+	if q := c.QueryParam("format"); q != "" {
+		f, err = export.ParseFormat(q)
+		return f, err == nil, err
+	}
+	for _, part := range strings.Split(c.Request().Header.Get(echo.HeaderAccept), ",") {
+		media, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch media {
+		case export.MIMECSV:
+			return export.FormatCSV, true, nil
+		case export.MIMEXLSX:
+			return export.FormatXLSX, true, nil
+		case echo.MIMEApplicationJSON, "*/*":
+			return "", false, nil
+		}
+	}
+	return "", false, nil
+}
+
+// exportRates streams the rates as an attachment
+func (s Server) exportRates(c echo.Context, lg *log.Entry, at time.Time, format export.Format) error {
+	return download(c, lg, "rates", format, rateColumns, func(write func(row ...interface{}) error) error {
+		return s.service.ExportRates(c.Request().Context(), at, func(r core.CurrencyRate) error {
+			return write(r.From, r.To, r.Rate, r.ValidFrom, r.ValidTo)
+		})
+	})
+}
+
+// exportConversions streams the conversion history as an attachment
+func (s Server) exportConversions(c echo.Context, lg *log.Entry, since, until time.Time, format export.Format) error {
+	return download(c, lg, "conversions", format, conversionColumns, func(write func(row ...interface{}) error) error {
+		return s.service.ExportConversions(c.Request().Context(), since, until, func(cv core.Conversion) error {
+			return write(cv.ID, cv.From, cv.To, cv.OriginalAmount, cv.ConvertedAmount, cv.ConversionSource, cv.At, cv.CreatedAt)
+		})
+	})
+}
+
+// download streams the rows each writes as the attachment name, the
+// `locale` and `decimals` query params control how the numbers are
+// written. Once rows were sent a failure aborts the connection, so
+// clients do not take a truncated file as complete
+func download(c echo.Context, lg *log.Entry, name string, format export.Format, columns []string,
+	each func(write func(row ...interface{}) error) error) error {
+	opts, err := export.ParseOptions(c.QueryParam("locale"), c.QueryParam("decimals"))
+	if err != nil {
+		lg.WithError(err).Error("export.ParseOptions")
+		return err
+	}
+	h := c.Response().Header()
+	h.Set(echo.HeaderContentType, format.ContentType())
+	h.Set(echo.HeaderContentDisposition, `attachment; filename="`+name+`.`+string(format)+`"`)
+	w, err := export.New(c.Response(), format, columns, opts)
+	if err != nil {
+		lg.WithError(err).Error("export.New")
+		return err
+	}
+	rows := 0
+	err = each(func(row ...interface{}) error {
+		rows++
+		return w.Write(row)
+	})
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		lg.WithError(err).Error("download")
+		if c.Response().Committed {
+			panic(http.ErrAbortHandler)
+		}
+		h.Del(echo.HeaderContentDisposition)
+		return err
+	}
+	lg.WithFields(log.Fields{"format": format, "rows": rows}).Info("success")
+	return nil
+}
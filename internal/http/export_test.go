@@ -0,0 +1,215 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/export"
+	rsv "github.com/arxdsilva/bravo/internal/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetRates_export(t *testing.T) {
+	t.Parallel()
+	validFrom := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rates := core.CurrencyRates{
+		{From: "USD", To: "BRL", Rate: 1234.5, ValidFrom: validFrom},
+	}
+	tests := []struct {
+		name       string
+		query      string
+		accept     string
+		wantExport bool
+		exportErr  error
+		wantCode   int
+		wantType   string
+		wantBody   string
+	}{
+		{
+			name:       "csv by accept",
+			accept:     "text/csv, application/json;q=0.5",
+			wantExport: true,
+			wantCode:   http.StatusOK,
+			wantType:   "text/csv; charset=utf-8",
+			wantBody:   "from,to,rate,valid_from,valid_to\nUSD,BRL,1234.5,2026-01-01T00:00:00Z,\n",
+		},
+		{
+			name:       "localized csv",
+			query:      "?format=csv&locale=pt-BR&decimals=2",
+			wantExport: true,
+			wantCode:   http.StatusOK,
+			wantType:   "text/csv; charset=utf-8",
+			wantBody:   "from;to;rate;valid_from;valid_to\nUSD;BRL;1.234,50;2026-01-01T00:00:00Z;\n",
+		},
+		{
+			name:       "xlsx by format",
+			query:      "?format=xlsx",
+			accept:     "application/json",
+			wantExport: true,
+			wantCode:   http.StatusOK,
+			wantType:   export.MIMEXLSX,
+			wantBody:   "PK",
+		},
+		{
+			name:     "invalid format",
+			query:    "?format=pdf",
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_export_format"`,
+		},
+		{
+			name:     "invalid locale",
+			query:    "?format=csv&locale=not+a+locale",
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_locale"`,
+		},
+		{
+			name:       "failure before the first row",
+			accept:     export.MIMECSV,
+			wantExport: true,
+			exportErr:  errors.New("conn refused"),
+			wantCode:   http.StatusInternalServerError,
+			wantType:   MIMEProblemJSON,
+			wantBody:   `"code":"internal_error"`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mock := rsv.NewMockResolver(ctrl)
+			if tt.wantExport {
+				mock.EXPECT().ExportRates(gomock.Any(), time.Time{}, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ time.Time, fn func(core.CurrencyRate) error) error {
+						if tt.exportErr != nil {
+							return tt.exportErr
+						}
+						for _, r := range rates {
+							if err := fn(r); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+			}
+
+			e := echo.New()
+			e.HTTPErrorHandler = HTTPErrorHandler
+			e.GET("/v1/rates", Server{service: mock}.GetRates)
+			req := httptest.NewRequest(http.MethodGet, "/v1/rates"+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set(echo.HeaderAccept, tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantCode, rec.Code)
+			require.Contains(t, rec.Body.String(), tt.wantBody)
+			if tt.wantType != "" {
+				require.Equal(t, tt.wantType, rec.Header().Get(echo.HeaderContentType))
+			}
+			if tt.wantCode == http.StatusOK {
+				require.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "attachment")
+			}
+		})
+	}
+}
+
+func Test_GetConversionHistory(t *testing.T) {
+	t.Parallel()
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	convs := []core.Conversion{{
+		ID: "c1",
+		ConversionEvent: core.ConversionEvent{
+			ConversionResp: core.ConversionResp{
+				From: "USD", To: "BRL", OriginalAmount: 10, ConvertedAmount: 51.5, ConversionSource: "manual",
+			},
+			At: since,
+		},
+		CreatedAt: since.Add(time.Second),
+	}}
+	tests := []struct {
+		name       string
+		query      string
+		accept     string
+		wantSince  time.Time
+		wantExport bool
+		wantCode   int
+		wantType   string
+		wantBody   string
+	}{
+		{
+			name:       "csv by default",
+			accept:     echo.MIMEApplicationJSON,
+			wantExport: true,
+			wantCode:   http.StatusOK,
+			wantType:   "text/csv; charset=utf-8",
+			wantBody: "id,from,to,original_amount,converted_amount,conversion_source,at,created_at\n" +
+				"c1,USD,BRL,10,51.5,manual,2026-01-01T00:00:00Z,2026-01-01T00:00:01Z\n",
+		},
+		{
+			name:       "xlsx since",
+			query:      "?format=xlsx&since=2026-01-01T00:00:00Z",
+			wantSince:  since,
+			wantExport: true,
+			wantCode:   http.StatusOK,
+			wantType:   export.MIMEXLSX,
+			wantBody:   "PK",
+		},
+		{
+			name:     "invalid since",
+			query:    "?since=yesterday",
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_history_range"`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mock := rsv.NewMockResolver(ctrl)
+			if tt.wantExport {
+				mock.EXPECT().ExportConversions(gomock.Any(), tt.wantSince, time.Time{}, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _, _ time.Time, fn func(core.Conversion) error) error {
+						for _, cv := range convs {
+							if err := fn(cv); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+			}
+
+			e := echo.New()
+			e.HTTPErrorHandler = HTTPErrorHandler
+			e.GET("/v1/conversions/history", Server{service: mock}.GetConversionHistory)
+			req := httptest.NewRequest(http.MethodGet, "/v1/conversions/history"+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set(echo.HeaderAccept, tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantCode, rec.Code)
+			require.Contains(t, rec.Body.String(), tt.wantBody)
+			if tt.wantType != "" {
+				require.Equal(t, tt.wantType, rec.Header().Get(echo.HeaderContentType))
+			}
+			if tt.wantCode == http.StatusOK {
+				require.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), `filename="conversions.`)
+			}
+		})
+	}
+}
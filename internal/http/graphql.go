@@ -0,0 +1,54 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/arxdsilva/bravo/internal/graphql"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// chargeConversions takes a token of the convert group and a unit of
+// the quota per conversion, the query itself is only limited as a read
+func (s Server) chargeConversions(c echo.Context) graphql.Charge {
+	return func(_ context.Context, n int) error {
+		return s.take(c, groupConvert, n, false)
+	}
+}
+
+// GraphQL runs a query over currencies, rates and conversions, errors
+// of the fields are answered in the errors of the body with the same
+// codes as the problem details
+//
+// HTTP responses:
+// 200 OK
+// 400 Bad Request
+// 500 Internal Server Error
+func (s Server) GraphQL(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "GraphQL",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+	req := graphql.Request{}
+	if err = bind(c, &req); err != nil {
+		lg.WithError(err).Error("bind")
+		return err
+	}
+	ctx := c.Request().Context()
+	if p, ok := principal(c); ok {
+		ctx = graphql.WithPrincipal(ctx, p)
+	}
+	if s.limiter != nil {
+		ctx = graphql.WithCharge(ctx, s.chargeConversions(c))
+	}
+	resp := s.graphql.Exec(ctx, req)
+	if len(resp.Errors) > 0 {
+		lg.WithField("errors", resp.Errors).Warn("query errors")
+	} else {
+		lg.Info("success")
+	}
+	return respond(c, http.StatusOK, resp)
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/graphql"
+	rsv "github.com/arxdsilva/bravo/internal/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GraphQL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		body     string
+		scopes   []core.Scope
+		wantGet  bool
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "query",
+			body:     `{"query":"query($s: String!) { currency(symbol: $s) { symbol description } }","variables":{"s":"USD"}}`,
+			scopes:   []core.Scope{core.ScopeCurrenciesRead},
+			wantGet:  true,
+			wantCode: http.StatusOK,
+			wantBody: `{"data":{"currency":{"symbol":"USD","description":"dollar"}}}`,
+		},
+		{
+			name:     "field without scope",
+			body:     `{"query":"{ currency(symbol: \"USD\") { symbol } }"}`,
+			scopes:   []core.Scope{core.ScopeConvertRead},
+			wantCode: http.StatusOK,
+			wantBody: `"extensions":{"code":"insufficient_scope"}`,
+		},
+		{
+			name:     "invalid body",
+			body:     `{"query":`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_body"`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mock := rsv.NewMockResolver(ctrl)
+			mock.EXPECT().AuthenticateKey(gomock.Any(), "secret").
+				Return(core.Principal{ID: "k1", Kind: core.PrincipalAPIKey, Scopes: tt.scopes}, nil).AnyTimes()
+			if tt.wantGet {
+				mock.EXPECT().GetCurrency(gomock.Any(), "USD").Return(core.Currency{Symbol: "USD", Description: "dollar"}, nil)
+			}
+
+			s := Server{
+				service: mock,
+				graphql: graphql.New(mock, graphql.Config{MaxDepth: 8, BatchWait: time.Millisecond, BatchMax: 10}),
+				config:  Config{AuthEnabled: true},
+			}
+			e := echo.New()
+			e.HTTPErrorHandler = HTTPErrorHandler
+			e.POST("/v1/graphql", s.GraphQL, s.requireScope(""))
+			req := httptest.NewRequest(http.MethodPost, "/v1/graphql", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(HeaderAPIKey, "secret")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantCode, rec.Code)
+			require.Contains(t, rec.Body.String(), tt.wantBody)
+		})
+	}
+}
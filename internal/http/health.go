@@ -0,0 +1,39 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/arxdsilva/bravo/internal/health"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// Liveness reports the process is up, it does not check dependencies
+// so a failing database does not get the service restarted
+//
+// HTTP responses:
+// 200 OK
+func Liveness(c echo.Context) (err error) {
+	return respond(c, http.StatusOK, health.Report{Status: health.StatusOK, Checks: []health.Result{}})
+}
+
+// Readiness runs the registered probes and reports each of them
+//
+// HTTP responses:
+// 200 OK all critical probes passed
+// 503 Service Unavailable a critical probe failed
+func (s Server) Readiness(c echo.Context) (err error) {
+	report := health.Report{Status: health.StatusOK, Checks: []health.Result{}}
+	if s.health != nil {
+		report = s.health.Check(c.Request().Context())
+	}
+	if !report.Ready() {
+		log.WithFields(log.Fields{
+			"pkg":   "http",
+			"route": "Readiness",
+			"cid":   c.Response().Header().Get(echo.HeaderXRequestID),
+		}).WithField("report", report).Warn("not ready")
+		return respond(c, http.StatusServiceUnavailable, report)
+	}
+	return respond(c, http.StatusOK, report)
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// Headers of idempotent requests
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotencyReplayed = "Idempotency-Replayed"
+)
+
+// idempotentMaxBytes caps the bodies read to fingerprint a request,
+// it is the largest body a route accepts, the rate import
+const idempotentMaxBytes = importMaxBytes
+
+// idempotentStoreTimeout bounds storing the outcome of a request
+const idempotentStoreTimeout = 5 * time.Second
+
+// replayedHeaders are the response headers stored with the body
+var replayedHeaders = []string{
+	echo.HeaderContentType,
+	echo.HeaderLocation,
+	HeaderETag,
+	echo.HeaderLastModified,
+}
+
+// idempotent runs a POST, PUT or DELETE sent with an Idempotency-Key
+// once per client, retries get the stored response back. Responses
+// of server side failures are not stored so the request can be
+// retried, it has to run after the authentication. Routes whose
+// responses carry secrets are not guarded by it, see guardSecret
+func (s Server) idempotent(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) (err error) {
+		key := c.Request().Header.Get(HeaderIdempotencyKey)
+		if key == "" || !mutating(c.Request().Method) {
+			return next(c)
+		}
+		lg := log.WithFields(log.Fields{
+			"pkg":    "http",
+			"route":  c.Path(),
+			"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+			"client": clientID(c),
+		})
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Response(), c.Request().Body, idempotentMaxBytes))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			lg.WithError(err).Warn("io.ReadAll")
+			return echo.ErrStatusRequestEntityTooLarge
+		}
+		if err != nil {
+			return core.ErrInvalidBody
+		}
+		c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request().Context()
+		req := core.IdempotentRequest{
+			Client:      clientID(c),
+			Key:         key,
+			Method:      c.Request().Method,
+			Path:        c.Request().URL.RequestURI(),
+			Fingerprint: fingerprint(c.Request(), body),
+		}
+		held, err := s.service.BeginIdempotentRequest(ctx, req)
+		if err != nil {
+			lg.WithError(err).Warn("service.BeginIdempotentRequest")
+			return err
+		}
+		if held.Completed() {
+			lg.Info("replayed")
+			return replay(c, held)
+		}
+
+		rec := &responseRecorder{ResponseWriter: c.Response().Writer}
+		c.Response().Writer = rec
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			store, cancel := storeContext()
+			defer cancel()
+			// the request panicked or failed, a retry has to run it again
+			if rerr := s.service.ReleaseIdempotentRequest(store, req); rerr != nil {
+				lg.WithError(rerr).Error("service.ReleaseIdempotentRequest")
+			}
+		}()
+
+		if err = next(c); err != nil {
+			// render the error now so it is stored as well
+			c.Error(err)
+		}
+		status := c.Response().Status
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		req.Status = status
+		req.Body = rec.body.Bytes()
+		req.Header = http.Header{}
+		for _, h := range replayedHeaders {
+			if v := c.Response().Header().Values(h); len(v) > 0 {
+				req.Header[h] = v
+			}
+		}
+		store, cancel := storeContext()
+		defer cancel()
+		if cerr := s.service.CompleteIdempotentRequest(store, req); cerr != nil {
+			lg.WithError(cerr).Error("service.CompleteIdempotentRequest")
+			return
+		}
+		completed = true
+		return
+	}
+}
+
+// storeContext is used to store the outcome of a request instead of
+// the request context, which is canceled when the client goes away
+// and would leave the key in flight until its lease ends
+func storeContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), idempotentStoreTimeout)
+}
+
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// fingerprint identifies the request a key was first used with
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replay(c echo.Context, r core.IdempotentRequest) error {
+	h := c.Response().Header()
+	for k, v := range r.Header {
+		h[k] = v
+	}
+	h.Set(HeaderIdempotencyReplayed, "true")
+	c.Response().WriteHeader(r.Status)
+	_, err := c.Response().Write(r.Body)
+	return err
+}
+
+// responseRecorder keeps a copy of the body written to the client
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
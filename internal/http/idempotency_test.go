@@ -0,0 +1,253 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	rsv "github.com/arxdsilva/bravo/internal/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_idempotent(t *testing.T) {
+	t.Parallel()
+	stored := core.IdempotentRequest{
+		Key:    "k1",
+		Status: http.StatusCreated,
+		Header: http.Header{echo.HeaderContentType: {echo.MIMEApplicationJSONCharsetUTF8}},
+		Body:   []byte(`{"symbol":"BRL"}`),
+	}
+	tests := []struct {
+		name     string
+		method   string
+		key      string
+		body     string
+		held     core.IdempotentRequest
+		beginErr error
+		handler  echo.HandlerFunc
+		// gone cancels the request context, as a client that hangs up
+		gone bool
+
+		wantBegin    bool
+		wantCalls    int
+		wantComplete bool
+		wantRelease  bool
+		wantCode     int
+		wantBody     string
+		wantReplayed bool
+	}{
+		{
+			name:      "without key",
+			method:    http.MethodPost,
+			wantCalls: 1,
+			wantCode:  http.StatusCreated,
+			wantBody:  "{\"symbol\":\"BRL\",\"description\":\"\",\"source\":\"\"}\n",
+		},
+		{
+			name:      "reads are not tracked",
+			method:    http.MethodGet,
+			key:       "k1",
+			wantCalls: 1,
+			wantCode:  http.StatusCreated,
+			wantBody:  "{\"symbol\":\"BRL\",\"description\":\"\",\"source\":\"\"}\n",
+		},
+		{
+			name:         "first attempt is stored",
+			method:       http.MethodPost,
+			key:          "k1",
+			wantBegin:    true,
+			wantCalls:    1,
+			wantComplete: true,
+			wantCode:     http.StatusCreated,
+			wantBody:     "{\"symbol\":\"BRL\",\"description\":\"\",\"source\":\"\"}\n",
+		},
+		{
+			name:         "retry is replayed",
+			method:       http.MethodPost,
+			key:          "k1",
+			held:         stored,
+			wantBegin:    true,
+			wantCode:     http.StatusCreated,
+			wantBody:     `{"symbol":"BRL"}`,
+			wantReplayed: true,
+		},
+		{
+			name:      "client errors are stored",
+			method:    http.MethodPut,
+			key:       "k1",
+			wantBegin: true,
+			handler: func(c echo.Context) error {
+				return core.ErrCurrencyNotFound
+			},
+			wantCalls:    1,
+			wantComplete: true,
+			wantCode:     http.StatusNotFound,
+		},
+		{
+			name:      "server errors release the key",
+			method:    http.MethodDelete,
+			key:       "k1",
+			wantBegin: true,
+			handler: func(c echo.Context) error {
+				return errors.New("db is down")
+			},
+			wantCalls:   1,
+			wantRelease: true,
+			wantCode:    http.StatusInternalServerError,
+		},
+		{
+			name:      "client gone still releases the key",
+			method:    http.MethodDelete,
+			key:       "k1",
+			gone:      true,
+			wantBegin: true,
+			handler: func(c echo.Context) error {
+				return c.Request().Context().Err()
+			},
+			wantCalls:   1,
+			wantRelease: true,
+			wantCode:    http.StatusInternalServerError,
+		},
+		{
+			name:         "client gone still stores the response",
+			method:       http.MethodPost,
+			key:          "k1",
+			gone:         true,
+			wantBegin:    true,
+			wantCalls:    1,
+			wantComplete: true,
+			wantCode:     http.StatusCreated,
+		},
+		{
+			name:     "body over the limit",
+			method:   http.MethodPost,
+			key:      "k1",
+			body:     strings.Repeat("a", idempotentMaxBytes+1),
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:      "key reused",
+			method:    http.MethodPost,
+			key:       "k1",
+			beginErr:  core.ErrIdempotencyKeyReused,
+			wantBegin: true,
+			wantCode:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:      "key in flight",
+			method:    http.MethodPost,
+			key:       "k1",
+			beginErr:  core.ErrIdempotencyKeyInFlight,
+			wantBegin: true,
+			wantCode:  http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mock := rsv.NewMockResolver(ctrl)
+			if tt.wantBegin {
+				mock.EXPECT().BeginIdempotentRequest(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ interface{}, r core.IdempotentRequest) (core.IdempotentRequest, error) {
+						require.Equal(t, tt.key, r.Key)
+						require.Equal(t, "/v1/currencies", r.Path)
+						require.Equal(t, fingerprint(httptest.NewRequest(tt.method, "/v1/currencies", nil), []byte(`{"symbol":"BRL"}`)), r.Fingerprint)
+						return tt.held, tt.beginErr
+					})
+			}
+			if tt.wantComplete {
+				mock.EXPECT().CompleteIdempotentRequest(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, r core.IdempotentRequest) error {
+						require.NoError(t, ctx.Err())
+						require.Equal(t, tt.wantCode, r.Status)
+						require.NotEmpty(t, r.Body)
+						require.NotEmpty(t, r.Header.Get(echo.HeaderContentType))
+						return nil
+					})
+			}
+			if tt.wantRelease {
+				mock.EXPECT().ReleaseIdempotentRequest(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, _ core.IdempotentRequest) error {
+						require.NoError(t, ctx.Err())
+						return nil
+					})
+			}
+
+			calls := 0
+			handler := tt.handler
+			if handler == nil {
+				handler = func(c echo.Context) error {
+					return c.JSON(http.StatusCreated, core.Currency{Symbol: "BRL"})
+				}
+			}
+			e := echo.New()
+			e.HTTPErrorHandler = HTTPErrorHandler
+			s := Server{service: mock}
+			e.Add(tt.method, "/v1/currencies", func(c echo.Context) error {
+				calls++
+				return handler(c)
+			}, s.idempotent)
+
+			body := tt.body
+			if body == "" {
+				body = `{"symbol":"BRL"}`
+			}
+			req := httptest.NewRequest(tt.method, "/v1/currencies", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.key != "" {
+				req.Header.Set(HeaderIdempotencyKey, tt.key)
+			}
+			if tt.gone {
+				ctx, cancel := context.WithCancel(req.Context())
+				cancel()
+				req = req.WithContext(ctx)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantCalls, calls)
+			require.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantBody != "" {
+				require.Equal(t, tt.wantBody, rec.Body.String())
+			}
+			require.Equal(t, tt.wantReplayed, rec.Header().Get(HeaderIdempotencyReplayed) == "true")
+		})
+	}
+}
+
+func Test_guardSecret(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the key is neither claimed nor stored, so the secret is only
+	// ever written to the client
+	mock := rsv.NewMockResolver(ctrl)
+	e := echo.New()
+	e.HTTPErrorHandler = HTTPErrorHandler
+	s := Server{service: mock}
+	calls := 0
+	e.POST("/v1/admin/keys", func(c echo.Context) error {
+		calls++
+		return c.JSON(http.StatusCreated, map[string]string{"secret": "s"})
+	}, s.guardSecret(core.ScopeKeysAdmin, groupAdmin)...)
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/keys", strings.NewReader(`{"name":"ci"}`))
+		req.Header.Set(HeaderIdempotencyKey, "k1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusCreated, rec.Code)
+	}
+	require.Equal(t, 2, calls)
+}
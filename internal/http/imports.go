@@ -0,0 +1,108 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// importMaxBytes caps the size of rate import uploads
+const importMaxBytes = 8 << 20
+
+// ImportRates proposes a rate window per row of a CSV file, sent as
+// the body or as the `file` field of a multipart form. The columns are
+// from, to, rate and optionally valid_from, valid_to, force and
+// force_reason, the layout of rate exports is accepted as is.
+//
+// Query params: dry_run only validates the rows, strict (default
+// true) proposes nothing unless every row is valid. The report lists
+// the outcome of every row
+//
+// HTTP responses:
+// 200 OK nothing was proposed
+// 202 Accepted rows were proposed
+// 400 Bad Request
+// 401 Unauthorized
+// 500 Internal Server Error
+func (s Server) ImportRates(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "ImportRates",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+	opts := core.ImportOptions{Strict: true}
+	if opts.DryRun, err = boolParam(c, "dry_run", opts.DryRun); err != nil {
+		lg.WithError(err).Error("dry_run")
+		return core.ErrInvalidImportFlags
+	}
+	if opts.Strict, err = boolParam(c, "strict", opts.Strict); err != nil {
+		lg.WithError(err).Error("strict")
+		return core.ErrInvalidImportFlags
+	}
+
+	file, err := importFile(c)
+	if err != nil {
+		lg.WithError(err).Error("importFile")
+		return err
+	}
+	defer file.Close()
+	rows, err := core.ParseRateImport(file)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		err = core.ErrImportTooLarge
+	}
+	if err != nil {
+		lg.WithError(err).Error("core.ParseRateImport")
+		return err
+	}
+
+	report, err := s.service.ImportRates(c.Request().Context(), rows, opts, s.actor(c))
+	if err != nil {
+		lg.WithError(err).Error("service.ImportRates")
+		return err
+	}
+	lg.WithFields(log.Fields{
+		"dry_run":  opts.DryRun,
+		"strict":   opts.Strict,
+		"invalid":  report.Invalid,
+		"proposed": report.Proposed,
+	}).Info("success")
+	if report.Proposed > 0 {
+		return respond(c, http.StatusAccepted, report)
+	}
+	return respond(c, http.StatusOK, report)
+}
+
+// importFile opens the uploaded file, the body itself unless it is a
+// multipart form
+func importFile(c echo.Context) (io.ReadCloser, error) {
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, importMaxBytes)
+	if !strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		return req.Body, nil
+	}
+	fh, err := c.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, core.ErrImportTooLarge
+	}
+	if err != nil {
+		return nil, core.ErrInvalidImportFile
+	}
+	return fh.Open()
+}
+
+func boolParam(c echo.Context, name string, def bool) (bool, error) {
+	v := c.QueryParam(name)
+	if v == "" {
+		return def, nil
+	}
+	return strconv.ParseBool(v)
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	rsv "github.com/arxdsilva/bravo/internal/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ImportRates(t *testing.T) {
+	t.Parallel()
+	const file = "from,to,rate\nUSD,BRL,5.2\n"
+	rows := []core.RateImportRow{{Line: 2, Rate: core.CurrencyRate{From: "USD", To: "BRL", Rate: 5.2}}}
+	multipartBody := func() (string, string) {
+		buf := &bytes.Buffer{}
+		w := multipart.NewWriter(buf)
+		fw, _ := w.CreateFormFile("file", "rates.csv")
+		fw.Write([]byte(file))
+		w.Close()
+		return buf.String(), w.FormDataContentType()
+	}
+	tests := []struct {
+		name        string
+		query       string
+		body        string
+		contentType string
+		multipart   bool
+		wantOpts    *core.ImportOptions
+		report      core.ImportReport
+		wantCode    int
+		wantBody    string
+	}{
+		{
+			name:        "csv body",
+			body:        file,
+			contentType: "text/csv",
+			wantOpts:    &core.ImportOptions{Strict: true},
+			report:      core.ImportReport{Strict: true, Valid: 1, Proposed: 1},
+			wantCode:    http.StatusAccepted,
+			wantBody:    `"proposed":1`,
+		},
+		{
+			name:      "multipart dry run",
+			query:     "?dry_run=true&strict=false",
+			multipart: true,
+			wantOpts:  &core.ImportOptions{DryRun: true},
+			report:    core.ImportReport{DryRun: true, Valid: 1},
+			wantCode:  http.StatusOK,
+			wantBody:  `"dry_run":true`,
+		},
+		{
+			name:     "invalid flag",
+			query:    "?strict=sometimes",
+			body:     file,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_import_flags"`,
+		},
+		{
+			name:     "invalid file",
+			body:     "symbol,value\nUSD,5\n",
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_import_file"`,
+		},
+		{
+			name:        "multipart without file",
+			body:        "--x--\r\n",
+			contentType: echo.MIMEMultipartForm + "; boundary=x",
+			wantCode:    http.StatusBadRequest,
+			wantBody:    `"code":"invalid_import_file"`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mock := rsv.NewMockResolver(ctrl)
+			if tt.wantOpts != nil {
+				mock.EXPECT().ImportRates(gomock.Any(), rows, *tt.wantOpts, "maker").Return(tt.report, nil)
+			}
+
+			body, contentType := tt.body, tt.contentType
+			if tt.multipart {
+				body, contentType = multipartBody()
+			}
+			e := echo.New()
+			e.HTTPErrorHandler = HTTPErrorHandler
+			e.POST("/v1/rates/import", Server{service: mock, config: Config{TrustActorHeader: true}}.ImportRates)
+			req := httptest.NewRequest(http.MethodPost, "/v1/rates/import"+tt.query, strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, contentType)
+			req.Header.Set(HeaderActor, "maker")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantCode, rec.Code)
+			require.Contains(t, rec.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+type apiKeyReq struct {
+	Name   string       `json:"name"`
+	Scopes []core.Scope `json:"scopes"`
+}
+
+// apiKeyResp is the only time the secret of a key is shown
+type apiKeyResp struct {
+	core.APIKey
+	Secret string `json:"secret"`
+}
+
+// GetAPIKeys retrieves every api key, secrets are never returned
+//
+// HTTP responses:
+// 200 OK
+// 500 Internal Server Error
+func (s Server) GetAPIKeys(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "GetAPIKeys",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+	keys, err := s.service.GetAPIKeys(c.Request().Context())
+	if err != nil {
+		lg.WithError(err).Error("service.GetAPIKeys")
+		return err
+	}
+	lg.Info("success")
+	return respond(c, http.StatusOK, keys)
+}
+
+// CreateAPIKey creates an api key with the given scopes
+//
+// HTTP responses:
+// 201 Created
+// 400 Bad Request
+// 500 Internal Server Error
+func (s Server) CreateAPIKey(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "CreateAPIKey",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+	req := &apiKeyReq{}
+	if err = bind(c, req); err != nil {
+		lg.WithError(err).Error("bind")
+		return err
+	}
+	key, secret, err := s.service.CreateAPIKey(c.Request().Context(), req.Name, req.Scopes)
+	if err != nil {
+		lg.WithError(err).Error("service.CreateAPIKey")
+		return err
+	}
+	lg.WithField("created_key_id", key.ID).Info("success")
+	return respond(c, http.StatusCreated, apiKeyResp{APIKey: key, Secret: secret})
+}
+
+// RotateAPIKey replaces the secret of an api key
+//
+// HTTP responses:
+// 200 OK
+// 404 Not Found
+// 500 Internal Server Error
+func (s Server) RotateAPIKey(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "RotateAPIKey",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+	key, secret, err := s.service.RotateAPIKey(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		lg.WithError(err).Error("service.RotateAPIKey")
+		return err
+	}
+	lg.WithField("rotated_key_id", key.ID).Info("success")
+	return respond(c, http.StatusOK, apiKeyResp{APIKey: key, Secret: secret})
+}
+
+// RevokeAPIKey disables an api key
+//
+// HTTP responses:
+// 204 No Content
+// 404 Not Found
+// 500 Internal Server Error
+func (s Server) RevokeAPIKey(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "RevokeAPIKey",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+	err = s.service.RevokeAPIKey(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		lg.WithError(err).Error("service.RevokeAPIKey")
+		return err
+	}
+	lg.WithField("revoked_key_id", c.Param("id")).Info("success")
+	return c.NoContent(http.StatusNoContent)
+}
@@ -1,13 +1,27 @@
 package http
 
 import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/metrics"
+	"github.com/arxdsilva/bravo/internal/tracing"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func RegisterMiddlewares(e *echo.Echo) {
+	e.HTTPErrorHandler = HTTPErrorHandler
+	e.Use(traceRequest)
 	e.Use(middleware.Logger())
+	e.Use(observe)
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORS())
 	e.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{
@@ -20,3 +34,62 @@ func RegisterMiddlewares(e *echo.Echo) {
 func customGenerator() string {
 	return uuid.New().String()
 }
+
+// observe records the duration of the request per route template
+func observe(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		err := next(c)
+		status := responseStatus(c, err)
+		route := c.Path()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HTTPRequestDuration.
+			WithLabelValues(c.Request().Method, route, strconv.Itoa(status)).
+			Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// traceRequest starts a server span per request continuing the
+// W3C trace context sent by the caller
+func traceRequest(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+		route := c.Path()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx, span := tracing.Tracer().Start(ctx, req.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethodKey.String(req.Method),
+				semconv.HTTPRouteKey.String(route),
+				semconv.HTTPTargetKey.String(req.URL.RequestURI()),
+			))
+		defer span.End()
+		c.SetRequest(req.WithContext(ctx))
+
+		err := next(c)
+		status := responseStatus(c, err)
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		if err != nil {
+			span.RecordError(err)
+		}
+		return err
+	}
+}
+
+// responseStatus resolves the status of returned errors before
+// HTTPErrorHandler writes them
+func responseStatus(c echo.Context, err error) int {
+	if err != nil {
+		return problem(err).Status
+	}
+	return c.Response().Status
+}
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"embed"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// docs holds the OpenAPI document of every route in RouterRegister
+// and the page rendering it
+//
+//go:embed docs/openapi.json docs/index.html
+var docs embed.FS
+
+// OpenAPI serves the OpenAPI 3 document of the API
+//
+// HTTP responses:
+// 200 OK
+func OpenAPI(c echo.Context) error {
+	b, err := docs.ReadFile("docs/openapi.json")
+	if err != nil {
+		return err
+	}
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSONCharsetUTF8, b)
+}
+
+// Docs serves the interactive documentation of the API
+//
+// HTTP responses:
+// 200 OK
+func Docs(c echo.Context) error {
+	b, err := docs.ReadFile("docs/index.html")
+	if err != nil {
+		return err
+	}
+	return c.HTMLBlob(http.StatusOK, b)
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+func Test_OpenAPI_coversRoutes(t *testing.T) {
+	t.Parallel()
+	e := echo.New()
+	Server{}.RouterRegister(e)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	spec := struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &spec))
+
+	for _, r := range e.Routes() {
+		path := pathParam.ReplaceAllString(r.Path, "{$1}")
+		ops, ok := spec.Paths[path]
+		if !ok {
+			t.Errorf("route %v %v is missing from the spec", r.Method, r.Path)
+			continue
+		}
+		if _, ok := ops[strings.ToLower(r.Method)]; !ok {
+			t.Errorf("route %v %v is missing from the spec", r.Method, r.Path)
+		}
+	}
+}
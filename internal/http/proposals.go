@@ -0,0 +1,120 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetRateProposals retrieves the manual rate proposals
+//
+// the optional `status` query param filters by pending, approved or rejected
+//
+// HTTP responses:
+// 200 OK
+// 500 Internal Server Error
+func (s Server) GetRateProposals(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "GetRateProposals",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+	proposals, err := s.service.GetRateProposals(
+		c.Request().Context(), core.ProposalStatus(c.QueryParam("status")))
+	if err != nil {
+		lg.WithError(err).Error("service.GetRateProposals")
+		return err
+	}
+	lg.Info("success")
+	return respond(c, http.StatusOK, proposals)
+}
+
+// GetRateProposal retrieves a manual rate proposal
+//
+// HTTP responses:
+// 200 OK
+// 404 Not Found
+// 500 Internal Server Error
+func (s Server) GetRateProposal(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "GetRateProposal",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+	proposal, err := s.service.GetRateProposal(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		lg.WithError(err).Error("service.GetRateProposal")
+		return err
+	}
+	lg.Info("success")
+	return respond(c, http.StatusOK, proposal)
+}
+
+// ApproveRateProposal applies a manual rate proposal, the approver
+// has to be a different identity than the proposer
+//
+// HTTP responses:
+// 200 OK
+// 401 Unauthorized
+// 403 Forbidden
+// 404 Not Found
+// 409 Conflict
+// 412 Precondition Failed
+// 500 Internal Server Error
+func (s Server) ApproveRateProposal(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "ApproveRateProposal",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+	proposal, err := s.service.ApproveRateProposal(
+		c.Request().Context(), c.Param("id"), s.actor(c))
+	if err != nil {
+		lg.WithError(err).Error("service.ApproveRateProposal")
+		return err
+	}
+	lg.Info("success")
+	return respond(c, http.StatusOK, proposal)
+}
+
+type rejectReq struct {
+	Reason string `json:"reason"`
+}
+
+// RejectRateProposal discards a manual rate proposal, the rejecter
+// has to be a different identity than the proposer
+//
+// HTTP responses:
+// 200 OK
+// 400 Bad Request
+// 401 Unauthorized
+// 403 Forbidden
+// 404 Not Found
+// 409 Conflict
+// 500 Internal Server Error
+func (s Server) RejectRateProposal(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "RejectRateProposal",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+	req := &rejectReq{}
+	if err = bind(c, req); err != nil {
+		lg.WithError(err).Error("bind")
+		return err
+	}
+	proposal, err := s.service.RejectRateProposal(
+		c.Request().Context(), c.Param("id"), s.actor(c), req.Reason)
+	if err != nil {
+		lg.WithError(err).Error("service.RejectRateProposal")
+		return err
+	}
+	lg.Info("success")
+	return respond(c, http.StatusOK, proposal)
+}
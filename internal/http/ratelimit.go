@@ -0,0 +1,121 @@
+package http
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/ratelimit"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// route groups share the same limits
+const (
+	groupConvert = ratelimit.GroupConvert
+	groupRead    = ratelimit.GroupRead
+	groupWrite   = ratelimit.GroupWrite
+	groupAdmin   = ratelimit.GroupAdmin
+	groupIP      = ratelimit.GroupIP
+)
+
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// clientID identifies the caller by credential, or by ip when anonymous
+func clientID(c echo.Context) string {
+	if p, ok := principal(c); ok {
+		return p.Kind + ":" + p.ID
+	}
+	return "ip:" + c.RealIP()
+}
+
+// limitIP applies the token bucket of the client address, it runs
+// before the authentication so requests with missing or invalid
+// credentials are throttled as well
+func (s Server) limitIP(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if s.limiter == nil {
+			return next(c)
+		}
+		ok, _, _, reset := s.limiter.Allow(groupIP, "ip:"+c.RealIP(), time.Now())
+		if !ok {
+			log.WithFields(log.Fields{
+				"pkg":   "http",
+				"route": c.Path(),
+				"cid":   c.Response().Header().Get(echo.HeaderXRequestID),
+				"ip":    c.RealIP(),
+			}).Warn("rate limited")
+			c.Response().Header().Set(HeaderRetryAfter, ratelimit.Seconds(reset))
+			return core.ErrRateLimited
+		}
+		return next(c)
+	}
+}
+
+// limit applies the token bucket of the route group and the monthly
+// quota of the client, it has to run after the authentication.
+// Requests are only counted when there is a quota to enforce
+func (s Server) limit(group string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if s.limiter == nil {
+				return next(c)
+			}
+			if err := s.take(c, group, 1, true); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// take takes n tokens of the group bucket of the client and counts n
+// requests against its quota, the RateLimit headers are only set
+// when they describe the limit of the route
+func (s Server) take(c echo.Context, group string, n int, headers bool) error {
+	client := clientID(c)
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  c.Path(),
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+		"client": client,
+		"group":  group,
+	})
+
+	ok, limit, remaining, reset := s.limiter.AllowN(group, client, n, time.Now())
+	if headers && limit > 0 {
+		h := c.Response().Header()
+		h.Set(HeaderRateLimitLimit, strconv.Itoa(limit))
+		h.Set(HeaderRateLimitRemaining, strconv.Itoa(remaining))
+		h.Set(HeaderRateLimitReset, ratelimit.Seconds(reset))
+	}
+	if !ok {
+		lg.WithField("n", n).Warn("rate limited")
+		c.Response().Header().Set(HeaderRetryAfter, ratelimit.Seconds(reset))
+		return core.ErrRateLimited
+	}
+	if s.config.MonthlyQuota <= 0 {
+		return nil
+	}
+
+	count, err := s.service.IncrementUsage(c.Request().Context(), client, int64(n))
+	if err != nil {
+		// usage accounting must not take the api down
+		lg.WithError(err).Error("service.IncrementUsage")
+		return nil
+	}
+	if count > s.config.MonthlyQuota {
+		lg.WithField("count", count).Warn("quota exceeded")
+		now := time.Now()
+		nextMonth := core.UsagePeriod(now).AddDate(0, 1, 0)
+		c.Response().Header().Set(HeaderRetryAfter, ratelimit.Seconds(nextMonth.Sub(now)))
+		return core.ErrQuotaExceeded
+	}
+	return nil
+}
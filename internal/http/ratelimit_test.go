@@ -0,0 +1,130 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/ratelimit"
+	rsv "github.com/arxdsilva/bravo/internal/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_limit(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		burst       int
+		quota       int64
+		usage       int64
+		wantCalled  bool
+		wantHTTPErr *echo.HTTPError
+	}{
+		{
+			name:       "allowed",
+			burst:      1,
+			quota:      100,
+			usage:      10,
+			wantCalled: true,
+		},
+		{
+			name:       "quotas off are not counted",
+			burst:      1,
+			wantCalled: true,
+		},
+		{
+			name:  "bucket empty",
+			burst: 0,
+			wantHTTPErr: &echo.HTTPError{
+				Code:    http.StatusTooManyRequests,
+				Message: core.ErrRateLimited.Error(),
+			},
+		},
+		{
+			name:  "quota exceeded",
+			burst: 1,
+			quota: 10,
+			usage: 11,
+			wantHTTPErr: &echo.HTTPError{
+				Code:    http.StatusTooManyRequests,
+				Message: core.ErrQuotaExceeded.Error(),
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mock := rsv.NewMockResolver(ctrl)
+			if tt.usage > 0 {
+				mock.EXPECT().IncrementUsage(gomock.Any(), "api_key:key", int64(1)).Return(tt.usage, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/currencies", nil)
+			rec := httptest.NewRecorder()
+			ctx := echo.New().NewContext(req, rec)
+			ctx.Set(principalKey, core.Principal{ID: "key", Kind: core.PrincipalAPIKey})
+
+			l := ratelimit.New(map[string]float64{groupRead: 1}, map[string]int{groupRead: 1})
+			if tt.burst == 0 {
+				l.Allow(groupRead, "api_key:key", time.Now())
+			}
+			s := Server{service: mock, limiter: l, config: Config{MonthlyQuota: tt.quota}}
+
+			called := false
+			err := s.limit(groupRead)(func(c echo.Context) error {
+				called = true
+				return nil
+			})(ctx)
+
+			require.Equal(t, tt.wantCalled, called)
+			require.Equal(t, "1", rec.Header().Get(HeaderRateLimitLimit))
+			if tt.wantHTTPErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			requireProblem(t, tt.wantHTTPErr, err)
+			require.NotEmpty(t, rec.Header().Get(HeaderRetryAfter))
+		})
+	}
+}
+
+func Test_limitIP(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the credentials are never checked once the address is limited
+	mock := rsv.NewMockResolver(ctrl)
+	mock.EXPECT().AuthenticateKey(gomock.Any(), "bad").Return(core.Principal{}, core.ErrUnauthenticated)
+	e := echo.New()
+	e.HTTPErrorHandler = HTTPErrorHandler
+	s := Server{
+		service: mock,
+		limiter: ratelimit.New(map[string]float64{groupIP: 1}, map[string]int{groupIP: 1}),
+		config:  Config{AuthEnabled: true},
+	}
+	e.GET("/v1/currencies", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, s.guard(core.ScopeCurrenciesRead, groupRead)...)
+
+	codes := []int{}
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/v1/currencies", nil)
+		req.Header.Set(HeaderAPIKey, "bad")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+		if rec.Code == http.StatusTooManyRequests {
+			require.NotEmpty(t, rec.Header().Get(HeaderRetryAfter))
+		}
+	}
+	require.Equal(t, []int{http.StatusUnauthorized, http.StatusTooManyRequests}, codes)
+}
@@ -1,8 +1,8 @@
 package http
 
 import (
-	"errors"
 	"net/http"
+	"time"
 
 	"github.com/arxdsilva/bravo/internal/core"
 	"github.com/labstack/echo/v4"
@@ -11,135 +11,167 @@ import (
 
 // GetRates retrieves all currency rates in DB
 //
+// the optional `at` query param (RFC3339) filters the rates
+// that were effective at that instant. The rates are downloaded as
+// CSV or XLSX with `Accept` or the `format` query param, see exportRates
+//
 // HTTP responses:
 // 200 OK
+// 304 Not Modified
+// 400 Bad Request
 // 500 Internal Server Error
 func (s Server) GetRates(c echo.Context) (err error) {
 	lg := log.WithFields(log.Fields{
-		"pkg":   "http",
-		"route": "GetRates",
-		"cid":   c.Response().Header().Get(echo.HeaderXRequestID),
+		"pkg":    "http",
+		"route":  "GetRates",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
 	})
-	currencies, err := s.service.GetRates(c.Request().Context())
+
+	var at time.Time
+	if qAt := c.QueryParam("at"); qAt != "" {
+		at, err = time.Parse(time.RFC3339, qAt)
+		if err != nil {
+			lg.WithError(err).Error("time.Parse")
+			return core.ErrInvalidTimestamp
+		}
+	}
+
+	format, ok, err := exportFormat(c)
+	if err != nil {
+		lg.WithError(err).Error("exportFormat")
+		return err
+	}
+	if ok {
+		return s.exportRates(c, lg, at, format)
+	}
+
+	rates, err := s.service.GetRates(c.Request().Context(), at)
 	if err != nil {
 		lg.WithError(err).Error("service.GetRates")
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	lg.Info("success")
-	return c.JSON(http.StatusOK, currencies)
+	return conditional(c, http.StatusOK, rates, rates.LastModified())
 }
 
-// CreateRate tries to create a currency rate into DB
+// CreateRate proposes a new currency rate, it is only stored
+// after being approved by a different identity
 //
 // HTTP responses:
-// 201 Created
+// 202 Accepted
 // 400 Bad Request
+// 401 Unauthorized
+// 409 Conflict
+// 412 Precondition Failed
+// 422 Unprocessable Entity
 // 500 Internal Server Error
+// 503 Service Unavailable
 func (s Server) CreateRate(c echo.Context) (err error) {
-	lg := log.WithFields(log.Fields{
-		"pkg":   "http",
-		"route": "CreateRate",
-		"cid":   c.Response().Header().Get(echo.HeaderXRequestID),
-	})
-
-	rate := &core.CurrencyRate{}
-	if err = c.Bind(rate); err != nil {
-		lg.WithError(err).Error("c.Bind")
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-	}
-
-	if err = rate.Check(); err != nil {
-		lg.WithError(err).Error("rate.Check")
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-	}
-
-	err = s.service.CreateRate(
-		c.Request().Context(), rate.From, rate.To, rate.Rate)
-	if err != nil { // check not found err
-		lg.WithError(err).Error("service.CreateRate")
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-
-	lg.Info("success")
-	return c.JSON(http.StatusCreated, rate)
+	return s.proposeRate(c, "CreateRate", core.RateActionCreate)
 }
 
-// UpdateRate tries to create a currency rate into DB
+// UpdateRate proposes a change to a currency rate window, it is
+// only stored after being approved by a different identity
 //
 // HTTP responses:
 // 202 Accepted
 // 400 Bad Request
+// 401 Unauthorized
+// 404 Not Found
+// 409 Conflict
+// 412 Precondition Failed
+// 422 Unprocessable Entity
 // 500 Internal Server Error
+// 503 Service Unavailable
 func (s Server) UpdateRate(c echo.Context) (err error) {
-	lg := log.WithFields(log.Fields{
-		"pkg":   "http",
-		"route": "UpdateRate",
-		"cid":   c.Response().Header().Get(echo.HeaderXRequestID),
-	})
-
-	rate := &core.CurrencyRate{}
-	if err = c.Bind(rate); err != nil {
-		lg.WithError(err).Error("c.Bind")
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-	}
-
-	if err = rate.Check(); err != nil {
-		lg.WithError(err).Error("rate.Check")
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-	}
-
-	err = s.service.UpdateRate(
-		c.Request().Context(), rate.From, rate.To, rate.Rate)
-	if err != nil && err != core.ErrNotFound { // check not found err
-		lg.WithError(err).Error("service.UpdateRate")
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-
-	if errors.Is(err, core.ErrNotFound) {
-		lg.WithError(core.ErrCurrencyNotFound).Error("not found")
-		return echo.NewHTTPError(http.StatusBadRequest, core.ErrCurrencyNotFound.Error())
-	}
-
-	lg.Info("success")
-	return c.JSON(http.StatusAccepted, rate)
+	return s.proposeRate(c, "UpdateRate", core.RateActionUpdate)
 }
 
-// RemoveRate tries to create a currency rate into DB
+// RemoveRate proposes the removal of a currency rate window, it is
+// only removed after being approved by a different identity
 //
 // HTTP responses:
-// 204 No Content
+// 202 Accepted
 // 400 Bad Request
+// 401 Unauthorized
+// 404 Not Found
+// 412 Precondition Failed
+// 422 Unprocessable Entity
 // 500 Internal Server Error
 func (s Server) RemoveRate(c echo.Context) (err error) {
+	return s.proposeRate(c, "RemoveRate", core.RateActionDelete)
+}
+
+// rateChangeReq is the body of manual rate changes, force and
+// force_reason allow a rate far from the market to be proposed
+type rateChangeReq struct {
+	core.CurrencyRate
+	core.Override
+}
+
+func (s Server) proposeRate(c echo.Context, route string, action core.RateAction) (err error) {
 	lg := log.WithFields(log.Fields{
-		"pkg":   "http",
-		"route": "RemoveRate",
-		"cid":   c.Response().Header().Get(echo.HeaderXRequestID),
+		"pkg":    "http",
+		"route":  route,
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
 	})
 
-	rate := &core.CurrencyRate{}
-	if err = c.Bind(rate); err != nil {
-		lg.WithError(err).Error("c.Bind")
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	req := &rateChangeReq{}
+	if err = bind(c, req); err != nil {
+		lg.WithError(err).Error("bind")
+		return err
 	}
 
-	if err = rate.Check(); err != nil {
+	if err = req.CurrencyRate.Check(); err != nil {
 		lg.WithError(err).Error("rate.Check")
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	err = s.service.RemoveRate(
-		c.Request().Context(), rate.From, rate.To)
-	if err != nil && err != core.ErrNotFound { // check not found err
-		lg.WithError(err).Error("service.RemoveRate")
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	if err = req.Override.Check(); err != nil {
+		lg.WithError(err).Error("override.Check")
+		return err
 	}
 
-	if errors.Is(err, core.ErrNotFound) {
-		lg.WithError(core.ErrCurrencyNotFound).Error("not found")
-		return echo.NewHTTPError(http.StatusBadRequest, core.ErrCurrencyNotFound.Error())
+	version, err := s.ratesVersion(c)
+	if err != nil {
+		lg.WithError(err).Warn("s.ratesVersion")
+		return err
 	}
 
-	lg.Info("success")
-	return c.NoContent(http.StatusNoContent)
+	proposal, err := s.service.ProposeRate(
+		c.Request().Context(), action, req.CurrencyRate, req.Override, version, s.actor(c))
+	if err != nil {
+		lg.WithError(err).Error("service.ProposeRate")
+		return err
+	}
+
+	lg.WithFields(log.Fields{
+		"proposal": proposal.ID,
+		"forced":   proposal.Override.Force,
+	}).Info("success")
+	return respond(c, http.StatusAccepted, proposal)
+}
+
+// ratesVersion compares If-Match with the schedule served by
+// GET /rates, so a change is not proposed over windows the client
+// has not seen. It returns the version the approval has to find,
+// empty without If-Match
+func (s Server) ratesVersion(c echo.Context) (string, error) {
+	if c.Request().Header.Get(HeaderIfMatch) == "" {
+		return "", nil
+	}
+	rates, err := s.service.GetRates(c.Request().Context(), time.Time{})
+	if err != nil {
+		return "", err
+	}
+	tag, err := rates.Version()
+	if err != nil {
+		return "", err
+	}
+	if !ifMatch(c.Request(), tag, true) {
+		return "", core.ErrPreconditionFailed
+	}
+	return tag, nil
 }
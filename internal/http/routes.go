@@ -3,30 +3,92 @@ package http
 import (
 	"net/http"
 
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/metrics"
 	"github.com/labstack/echo/v4"
 )
 
+// apiVersion prefixes every versioned route
+const apiVersion = "/v1"
+
 func (s Server) RouterRegister(e *echo.Echo) {
 	e.GET("/", HealthCheck)
-	e.GET("/convertion/convert", s.Convert)
+	e.GET("/healthz", Liveness)
+	e.GET("/readyz", s.Readiness)
+	e.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+	e.GET("/openapi.json", OpenAPI)
+	e.GET("/docs", Docs)
+	// versioned routes, the second path is the deprecated alias
+	s.route(e, http.MethodGet, "/conversions", "/convertion/convert", s.Convert, s.guard(core.ScopeConvertRead, groupConvert)...)
+	s.route(e, http.MethodGet, "/conversions/history", "", s.GetConversionHistory, s.guard(core.ScopeConvertRead, groupRead)...)
 	// currency management
-	e.GET("/currencies", s.GetCurrencies)
-	e.POST("/currencies", s.AddCurrency)
-	e.GET("/currencies/:symbol", s.GetCurrency)
-	e.PUT("/currencies/:symbol", s.UpdateCurrency)
-	e.DELETE("/currencies/:symbol", s.RemoveCurrency)
+	s.route(e, http.MethodGet, "/currencies", "/currencies", s.GetCurrencies, s.guard(core.ScopeCurrenciesRead, groupRead)...)
+	s.route(e, http.MethodPost, "/currencies", "/currencies", s.AddCurrency, s.guard(core.ScopeCurrenciesWrite, groupWrite)...)
+	s.route(e, http.MethodGet, "/currencies/:symbol", "/currencies/:symbol", s.GetCurrency, s.guard(core.ScopeCurrenciesRead, groupRead)...)
+	s.route(e, http.MethodPut, "/currencies/:symbol", "/currencies/:symbol", s.UpdateCurrency, s.guard(core.ScopeCurrenciesWrite, groupWrite)...)
+	s.route(e, http.MethodDelete, "/currencies/:symbol", "/currencies/:symbol", s.RemoveCurrency, s.guard(core.ScopeCurrenciesWrite, groupWrite)...)
 	// currency rate management
-	e.GET("/convertion/rates", s.GetRates)
-	e.POST("/convertion/rates", s.CreateRate)
-	e.PUT("/convertion/rates", s.UpdateRate)
-	e.DELETE("/convertion/rates", s.RemoveRate)
+	s.route(e, http.MethodGet, "/rates", "/convertion/rates", s.GetRates, s.guard(core.ScopeRatesRead, groupRead)...)
+	s.route(e, http.MethodPost, "/rates", "/convertion/rates", s.CreateRate, s.guard(core.ScopeRatesWrite, groupWrite)...)
+	s.route(e, http.MethodPut, "/rates", "/convertion/rates", s.UpdateRate, s.guard(core.ScopeRatesWrite, groupWrite)...)
+	s.route(e, http.MethodDelete, "/rates", "/convertion/rates", s.RemoveRate, s.guard(core.ScopeRatesWrite, groupWrite)...)
+	s.route(e, http.MethodPost, "/rates/import", "/convertion/rates/import", s.ImportRates, s.guard(core.ScopeRatesWrite, groupWrite)...)
+	// live rate changes, the broker is in memory so with several
+	// replicas a client only sees the changes made on its own replica
+	s.route(e, http.MethodGet, "/rates/stream", "", s.StreamRates, s.guard(core.ScopeRatesRead, groupRead)...)
+	// manual rate approval
+	s.route(e, http.MethodGet, "/rates/proposals", "/convertion/rates/proposals", s.GetRateProposals, s.guard(core.ScopeRatesRead, groupRead)...)
+	s.route(e, http.MethodGet, "/rates/proposals/:id", "/convertion/rates/proposals/:id", s.GetRateProposal, s.guard(core.ScopeRatesRead, groupRead)...)
+	s.route(e, http.MethodPost, "/rates/proposals/:id/approve", "/convertion/rates/proposals/:id/approve", s.ApproveRateProposal, s.guard(core.ScopeRatesApprove, groupWrite)...)
+	s.route(e, http.MethodPost, "/rates/proposals/:id/reject", "/convertion/rates/proposals/:id/reject", s.RejectRateProposal, s.guard(core.ScopeRatesApprove, groupWrite)...)
+	// rate alerts delivered by webhooks
+	s.route(e, http.MethodGet, "/alerts", "", s.GetAlertSubscriptions, s.guard(core.ScopeAlertsRead, groupRead)...)
+	s.route(e, http.MethodPost, "/alerts", "", s.CreateAlertSubscription, s.guardSecret(core.ScopeAlertsWrite, groupWrite)...)
+	s.route(e, http.MethodGet, "/alerts/:id", "", s.GetAlertSubscription, s.guard(core.ScopeAlertsRead, groupRead)...)
+	s.route(e, http.MethodPut, "/alerts/:id", "", s.UpdateAlertSubscription, s.guard(core.ScopeAlertsWrite, groupWrite)...)
+	s.route(e, http.MethodDelete, "/alerts/:id", "", s.RemoveAlertSubscription, s.guard(core.ScopeAlertsWrite, groupWrite)...)
+	s.route(e, http.MethodGet, "/alerts/:id/deliveries", "", s.GetWebhookDeliveries, s.guard(core.ScopeAlertsRead, groupRead)...)
+	// queries over currencies, rates and conversions, the scope of
+	// each field is checked by the executor
+	s.route(e, http.MethodPost, "/graphql", "", s.GraphQL, s.limitIP, s.requireScope(""), s.limit(groupRead))
+	// api key management
+	s.route(e, http.MethodGet, "/admin/keys", "/admin/keys", s.GetAPIKeys, s.guard(core.ScopeKeysAdmin, groupAdmin)...)
+	s.route(e, http.MethodPost, "/admin/keys", "/admin/keys", s.CreateAPIKey, s.guardSecret(core.ScopeKeysAdmin, groupAdmin)...)
+	s.route(e, http.MethodPost, "/admin/keys/:id/rotate", "/admin/keys/:id/rotate", s.RotateAPIKey, s.guardSecret(core.ScopeKeysAdmin, groupAdmin)...)
+	s.route(e, http.MethodDelete, "/admin/keys/:id", "/admin/keys/:id", s.RevokeAPIKey, s.guard(core.ScopeKeysAdmin, groupAdmin)...)
+	s.route(e, http.MethodGet, "/admin/usage", "/admin/usage", s.GetUsage, s.guard(core.ScopeKeysAdmin, groupAdmin)...)
+	s.route(e, http.MethodGet, "/admin/provider/budget", "/admin/provider/budget", s.GetProviderBudget, s.guard(core.ScopeKeysAdmin, groupAdmin)...)
+}
+
+// route mounts h under /v1 and, when legacy is not empty, keeps it
+// reachable at the legacy path announcing its successor
+func (s Server) route(e *echo.Echo, method, path, legacy string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) {
+	e.Add(method, apiVersion+path, h, m...)
+	if legacy == "" {
+		return
+	}
+	lm := append([]echo.MiddlewareFunc{s.deprecated(apiVersion + path)}, m...)
+	e.Add(method, legacy, h, lm...)
+}
+
+// guard limits the client address, authenticates the route scope,
+// applies the rate limit and quota of the route group and then
+// honors Idempotency-Key on writes
+func (s Server) guard(scope core.Scope, group string) []echo.MiddlewareFunc {
+	return []echo.MiddlewareFunc{s.limitIP, s.requireScope(scope), s.limit(group), s.idempotent}
+}
+
+// guardSecret is guard without Idempotency-Key for the routes whose
+// responses carry a secret, such as a new api key, which must not be
+// kept in the stored responses
+func (s Server) guardSecret(scope core.Scope, group string) []echo.MiddlewareFunc {
+	return []echo.MiddlewareFunc{s.limitIP, s.requireScope(scope), s.limit(group)}
 }
 
-// todo: allow this to be configurable and to pass optional checks
-// ex: db, services, connections ...
+// HealthCheck is kept for existing monitors, see Liveness and Readiness
 func HealthCheck(c echo.Context) (err error) {
 	ok := struct {
 		Service string `json:"service"`
 	}{"ok"}
-	return c.JSON(http.StatusOK, ok)
+	return respond(c, http.StatusOK, ok)
 }
@@ -0,0 +1,509 @@
+package http
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/labstack/echo/v4"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MIMEProblemXML is the content type of error responses in XML,
+// RFC 7807 appendix A
+const MIMEProblemXML = "application/problem+xml"
+
+// Serializer writes and reads bodies in one media type. Every format
+// goes through the JSON data model so field names, omitted fields and
+// custom marshalers are the same whatever the client asks for
+type Serializer interface {
+	// MediaType is the content type of successful responses
+	MediaType() string
+	// ProblemType is the content type of error responses
+	ProblemType() string
+	Serialize(w io.Writer, v interface{}) error
+	Deserialize(r io.Reader, v interface{}) error
+}
+
+var (
+	jsonFormat    Serializer = jsonSerializer{}
+	xmlFormat     Serializer = xmlSerializer{}
+	msgpackFormat Serializer = msgpackSerializer{}
+)
+
+// mediaTypes are the media types accepted for each format
+var mediaTypes = map[string]Serializer{
+	echo.MIMEApplicationJSON:    jsonFormat,
+	echo.MIMEApplicationXML:     xmlFormat,
+	echo.MIMETextXML:            xmlFormat,
+	echo.MIMEApplicationMsgpack: msgpackFormat,
+	"application/x-msgpack":     msgpackFormat,
+	"application/vnd.msgpack":   msgpackFormat,
+}
+
+// serializerOf returns the format of a media type, structured syntax
+// suffixes are honored so application/problem+json is JSON
+func serializerOf(mediaType string) Serializer {
+	if s, ok := mediaTypes[mediaType]; ok {
+		return s
+	}
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return jsonFormat
+	case strings.HasSuffix(mediaType, "+xml"):
+		return xmlFormat
+	}
+	return nil
+}
+
+// negotiate picks the format of the response from the Accept header,
+// the highest quality wins and exact types win over wildcards of the
+// same quality. JSON is sent when nothing else is acceptable
+func negotiate(accept string) Serializer {
+	best, bestQ, bestExact := jsonFormat, 0.0, false
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		s, exact := serializerOf(mt), true
+		if mt == "*/*" || mt == "application/*" {
+			s, exact = jsonFormat, false
+		}
+		if s == nil || q <= 0 || q < bestQ || (q == bestQ && (bestExact || !exact)) {
+			continue
+		}
+		best, bestQ, bestExact = s, q, exact
+	}
+	return best
+}
+
+// respond sends v with the status code in the format negotiated with
+// the Accept header
+func respond(c echo.Context, code int, v interface{}) error {
+	return send(c, code, v, false)
+}
+
+// send serializes v before writing the header so a failure can still
+// be answered with an error, problem details get their own content type
+func send(c echo.Context, code int, v interface{}, problem bool) error {
+	s := negotiate(c.Request().Header.Get(echo.HeaderAccept))
+	buf := &bytes.Buffer{}
+	if err := s.Serialize(buf, v); err != nil {
+		return err
+	}
+	h := c.Response().Header()
+	h.Add(echo.HeaderVary, echo.HeaderAccept)
+	if problem {
+		h.Set(echo.HeaderContentType, s.ProblemType())
+	} else {
+		h.Set(echo.HeaderContentType, s.MediaType())
+	}
+	c.Response().WriteHeader(code)
+	_, err := buf.WriteTo(c.Response())
+	return err
+}
+
+// bind decodes the request body into v in the format of its Content-Type,
+// bodies without one are read as JSON. Unknown formats are answered
+// with 415 Unsupported Media Type
+func bind(c echo.Context, v interface{}) error {
+	req := c.Request()
+	if req.ContentLength == 0 {
+		return nil
+	}
+	s := jsonFormat
+	if ct := req.Header.Get(echo.HeaderContentType); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			return echo.ErrUnsupportedMediaType
+		}
+		if s = serializerOf(mt); s == nil {
+			return echo.ErrUnsupportedMediaType
+		}
+	}
+	if err := s.Deserialize(req.Body, v); err != nil {
+		return fmt.Errorf("%w: %v", core.ErrInvalidBody, err)
+	}
+	return nil
+}
+
+type jsonSerializer struct{}
+
+func (jsonSerializer) MediaType() string   { return echo.MIMEApplicationJSONCharsetUTF8 }
+func (jsonSerializer) ProblemType() string { return MIMEProblemJSON }
+
+func (jsonSerializer) Serialize(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func (jsonSerializer) Deserialize(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// xmlSerializer maps JSON objects to elements named after their keys
+// under a response root, array values to item elements and keys that
+// are not XML names to entry elements with a key attribute. null
+// values are left out
+type xmlSerializer struct{}
+
+func (xmlSerializer) MediaType() string   { return echo.MIMEApplicationXMLCharsetUTF8 }
+func (xmlSerializer) ProblemType() string { return MIMEProblemXML }
+
+func (xmlSerializer) Serialize(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	if err = writeXML(e, d, "response"); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+func writeXML(e *xml.Encoder, d *json.Decoder, name string) error {
+	tok, err := d.Token()
+	if err != nil {
+		return err
+	}
+	start := xmlElement(name)
+	switch t := tok.(type) {
+	case nil:
+		return nil
+	case json.Delim:
+		if err = e.EncodeToken(start); err != nil {
+			return err
+		}
+		for d.More() {
+			child := "item"
+			if t == '{' {
+				k, err := d.Token()
+				if err != nil {
+					return err
+				}
+				child = k.(string)
+			}
+			if err = writeXML(e, d, child); err != nil {
+				return err
+			}
+		}
+		// closing delimiter
+		if _, err = d.Token(); err != nil {
+			return err
+		}
+		return e.EncodeToken(start.End())
+	default:
+		return e.EncodeElement(fmt.Sprint(t), start)
+	}
+}
+
+func xmlElement(name string) xml.StartElement {
+	if isXMLName(name) {
+		return xml.StartElement{Name: xml.Name{Local: name}}
+	}
+	return xml.StartElement{
+		Name: xml.Name{Local: "entry"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}},
+	}
+}
+
+func isXMLName(s string) bool {
+	if s == "" || strings.HasPrefix(strings.ToLower(s), "xml") {
+		return false
+	}
+	for i, r := range s {
+		if unicode.IsLetter(r) || r == '_' {
+			continue
+		}
+		if i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func (xmlSerializer) Deserialize(r io.Reader, v interface{}) error {
+	root, err := parseXML(r)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(root.value(reflect.TypeOf(v)))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// xmlNode is an element of a request body, its value is typed after
+// the field it is decoded into since XML has no numbers or booleans
+type xmlNode struct {
+	name     string
+	text     string
+	children []*xmlNode
+}
+
+func parseXML(r io.Reader) (*xmlNode, error) {
+	d := xml.NewDecoder(r)
+	var root *xmlNode
+	var stack []*xmlNode
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{name: t.Name.Local}
+			for _, a := range t.Attr {
+				if a.Name.Local == "key" {
+					n.name = a.Value
+				}
+			}
+			switch {
+			case len(stack) > 0:
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			case root != nil:
+				return nil, errors.New("xml: more than one root element")
+			default:
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return root, nil
+}
+
+var (
+	jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// value converts n to the JSON data model of t
+func (n *xmlNode) value(t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	pt := reflect.PtrTo(t)
+	if pt.Implements(jsonUnmarshaler) || pt.Implements(textUnmarshaler) {
+		if len(n.children) > 0 {
+			return n.generic()
+		}
+		return n.text
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := jsonFields(t)
+		obj := map[string]interface{}{}
+		for _, c := range n.children {
+			if ft, ok := fieldOf(fields, c.name); ok {
+				obj[c.name] = c.value(ft)
+			}
+		}
+		return obj
+	case reflect.Map:
+		obj := map[string]interface{}{}
+		for _, c := range n.children {
+			obj[c.name] = c.value(t.Elem())
+		}
+		return obj
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return strings.TrimSpace(n.text)
+		}
+		arr := []interface{}{}
+		for _, c := range n.children {
+			arr = append(arr, c.value(t.Elem()))
+		}
+		return arr
+	case reflect.Bool:
+		text := strings.TrimSpace(n.text)
+		if b, err := strconv.ParseBool(text); err == nil {
+			return b
+		}
+		return text
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		text := strings.TrimSpace(n.text)
+		if text == "" {
+			return nil
+		}
+		if _, err := strconv.ParseFloat(text, 64); err == nil {
+			return json.Number(text)
+		}
+		return text
+	case reflect.Interface:
+		return n.generic()
+	}
+	return n.text
+}
+
+// generic converts n without a target type, elements of repeated
+// item children are arrays and every leaf is a string
+func (n *xmlNode) generic() interface{} {
+	if len(n.children) == 0 {
+		return n.text
+	}
+	items := true
+	for _, c := range n.children {
+		items = items && c.name == "item"
+	}
+	if items {
+		arr := make([]interface{}, 0, len(n.children))
+		for _, c := range n.children {
+			arr = append(arr, c.generic())
+		}
+		return arr
+	}
+	obj := map[string]interface{}{}
+	for _, c := range n.children {
+		obj[c.name] = c.generic()
+	}
+	return obj
+}
+
+// jsonFields are the types of the JSON keys of a struct, fields of
+// embedded structs are promoted unless the outer struct has the key
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	for _, et := range embedded {
+		for k, ft := range jsonFields(et) {
+			if _, ok := fields[k]; !ok {
+				fields[k] = ft
+			}
+		}
+	}
+	return fields
+}
+
+// fieldOf matches keys like encoding/json does, exact first and then
+// case insensitive
+func fieldOf(fields map[string]reflect.Type, key string) (reflect.Type, bool) {
+	if ft, ok := fields[key]; ok {
+		return ft, true
+	}
+	for k, ft := range fields {
+		if strings.EqualFold(k, key) {
+			return ft, true
+		}
+	}
+	return nil, false
+}
+
+// msgpackSerializer encodes the JSON data model, numbers are integers
+// when they have no fraction and map keys are sorted
+type msgpackSerializer struct{}
+
+func (msgpackSerializer) MediaType() string   { return echo.MIMEApplicationMsgpack }
+func (msgpackSerializer) ProblemType() string { return echo.MIMEApplicationMsgpack }
+
+func (msgpackSerializer) Serialize(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var data interface{}
+	if err = d.Decode(&data); err != nil {
+		return err
+	}
+	e := msgpack.NewEncoder(w)
+	e.SetSortMapKeys(true)
+	e.UseCompactInts(true)
+	return e.Encode(msgpackValue(data))
+}
+
+func msgpackValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = msgpackValue(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = msgpackValue(e)
+		}
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	}
+	return v
+}
+
+func (msgpackSerializer) Deserialize(r io.Reader, v interface{}) error {
+	var data interface{}
+	if err := msgpack.NewDecoder(r).Decode(&data); err != nil {
+		return err
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,223 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func Test_negotiate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		accept string
+		want   Serializer
+	}{
+		{name: "no header", accept: "", want: jsonFormat},
+		{name: "json", accept: "application/json", want: jsonFormat},
+		{name: "xml", accept: "application/xml", want: xmlFormat},
+		{name: "text xml", accept: "text/xml", want: xmlFormat},
+		{name: "msgpack", accept: "application/msgpack", want: msgpackFormat},
+		{name: "x-msgpack", accept: "application/x-msgpack", want: msgpackFormat},
+		{name: "problem suffix", accept: "application/problem+xml", want: xmlFormat},
+		{name: "wildcard", accept: "*/*", want: jsonFormat},
+		{name: "exact wins over wildcard", accept: "*/*, application/xml", want: xmlFormat},
+		{name: "quality", accept: "application/json;q=0.5, application/msgpack", want: msgpackFormat},
+		{name: "first of the same quality", accept: "application/xml, application/json", want: xmlFormat},
+		{name: "not acceptable", accept: "application/xml;q=0", want: jsonFormat},
+		{name: "unsupported", accept: "text/html", want: jsonFormat},
+		{name: "browser", accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", want: xmlFormat},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, negotiate(tt.accept))
+		})
+	}
+}
+
+func Test_respond(t *testing.T) {
+	t.Parallel()
+	v := alertResp{
+		AlertSubscription: core.AlertSubscription{
+			ID: "a1", From: "USD", To: "BRL", Condition: core.AlertAbove,
+			Threshold: 5.5, Window: core.Duration(time.Hour), URL: "https://example.com/hook",
+			Active: true, CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		Secret: "s",
+	}
+	tests := []struct {
+		name     string
+		accept   string
+		wantType string
+		decode   func(t *testing.T, b []byte) map[string]interface{}
+	}{
+		{
+			name:     "xml",
+			accept:   "application/xml",
+			wantType: echo.MIMEApplicationXMLCharsetUTF8,
+		},
+		{
+			name:     "msgpack",
+			accept:   "application/msgpack",
+			wantType: echo.MIMEApplicationMsgpack,
+			decode: func(t *testing.T, b []byte) map[string]interface{} {
+				m := map[string]interface{}{}
+				require.NoError(t, msgpack.Unmarshal(b, &m))
+				return m
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(echo.HeaderAccept, tt.accept)
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			require.NoError(t, respond(c, http.StatusCreated, v))
+			require.Equal(t, http.StatusCreated, rec.Code)
+			require.Equal(t, tt.wantType, rec.Header().Get(echo.HeaderContentType))
+			require.Equal(t, echo.HeaderAccept, rec.Header().Get(echo.HeaderVary))
+
+			// the body reads back into the same value
+			got := alertResp{}
+			require.NoError(t, negotiate(tt.accept).Deserialize(bytes.NewReader(rec.Body.Bytes()), &got))
+			require.Equal(t, v, got)
+			if tt.decode != nil {
+				m := tt.decode(t, rec.Body.Bytes())
+				require.Equal(t, "1h0m0s", m["window"])
+				require.EqualValues(t, 5.5, m["threshold"])
+			}
+		})
+	}
+}
+
+func Test_xmlSerializer(t *testing.T) {
+	t.Parallel()
+	v := map[string]interface{}{
+		"USD/BRL": 5.1,
+		"rates":   []int{1, 2},
+		"name":    "a < b",
+		"none":    nil,
+	}
+	buf := &bytes.Buffer{}
+	require.NoError(t, xmlFormat.Serialize(buf, v))
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<response><entry key="USD/BRL">5.1</entry><name>a &lt; b</name>`+
+		`<rates><item>1</item><item>2</item></rates></response>`, buf.String())
+}
+
+func Test_bind(t *testing.T) {
+	t.Parallel()
+	want := alertReq{
+		From: "USD", To: "BRL", Condition: core.AlertAbove, Threshold: 5,
+		Window: core.Duration(30 * time.Minute), URL: "https://example.com/hook",
+	}
+	mp, err := msgpack.Marshal(map[string]interface{}{
+		"from": "USD", "to": "BRL", "condition": "above", "threshold": 5,
+		"window": "30m", "url": "https://example.com/hook",
+	})
+	require.NoError(t, err)
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        alertReq
+		wantErr     error
+	}{
+		{
+			name:        "json",
+			contentType: echo.MIMEApplicationJSON,
+			body:        `{"from":"USD","to":"BRL","condition":"above","threshold":5,"window":"30m","url":"https://example.com/hook"}`,
+			want:        want,
+		},
+		{
+			name:        "xml",
+			contentType: echo.MIMEApplicationXMLCharsetUTF8,
+			body: `<alert><from>USD</from><to>BRL</to><condition>above</condition>` +
+				`<threshold> 5 </threshold><window>30m</window><url>https://example.com/hook</url><unknown>x</unknown></alert>`,
+			want: want,
+		},
+		{
+			name:        "xml pointer",
+			contentType: echo.MIMETextXML,
+			body:        `<alert><active>false</active></alert>`,
+			want:        alertReq{Active: new(bool)},
+		},
+		{
+			name:        "msgpack",
+			contentType: "application/x-msgpack",
+			body:        string(mp),
+			want:        want,
+		},
+		{
+			name: "no content type is json",
+			body: `{"from":"USD"}`,
+			want: alertReq{From: "USD"},
+		},
+		{
+			name:        "xml not a number",
+			contentType: echo.MIMEApplicationXML,
+			body:        `<alert><threshold>five</threshold></alert>`,
+			wantErr:     core.ErrInvalidBody,
+		},
+		{
+			name:        "malformed xml",
+			contentType: echo.MIMEApplicationXML,
+			body:        `<alert><from>USD</alert>`,
+			wantErr:     core.ErrInvalidBody,
+		},
+		{
+			name:        "unsupported",
+			contentType: echo.MIMEApplicationForm,
+			body:        `from=USD`,
+			wantErr:     echo.ErrUnsupportedMediaType,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set(echo.HeaderContentType, tt.contentType)
+			}
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			got := alertReq{}
+			err := bind(c, &got)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_HTTPErrorHandler_xml(t *testing.T) {
+	t.Parallel()
+	req := httptest.NewRequest(http.MethodGet, "/v1/currencies/XYZ", nil)
+	req.Header.Set(echo.HeaderAccept, echo.MIMEApplicationXML)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	HTTPErrorHandler(core.ErrCurrencyNotFound, c)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Equal(t, MIMEProblemXML, rec.Header().Get(echo.HeaderContentType))
+	require.Contains(t, rec.Body.String(), "<code>currency_not_found</code>")
+}
@@ -4,21 +4,36 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/arxdsilva/bravo/internal/graphql"
+	"github.com/arxdsilva/bravo/internal/health"
+	"github.com/arxdsilva/bravo/internal/ratelimit"
 	"github.com/arxdsilva/bravo/internal/service"
 	"github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
 )
 
 type Server struct {
-	server  *echo.Echo
-	service service.Resolver
-	config  Config
+	server   *echo.Echo
+	service  service.Resolver
+	verifier TokenVerifier
+	limiter  *ratelimit.Limiter
+	health   *health.Checker
+	graphql  graphql.Executor
+	config   Config
+	// done is closed on shutdown to end the open streams
+	done <-chan struct{}
 }
 
-func NewServer(svc service.Resolver, cfg Config) Server {
+// NewServer creates the http server, verifier is optional and
+// enables bearer token authentication, checker runs the readiness probes
+func NewServer(svc service.Resolver, verifier TokenVerifier, checker *health.Checker, cfg Config) Server {
 	return Server{
-		service: svc,
-		config:  cfg,
+		service:  svc,
+		verifier: verifier,
+		health:   checker,
+		limiter:  ratelimit.New(cfg.RateLimits, cfg.RateBursts),
+		graphql:  graphql.New(svc, cfg.GraphQL),
+		config:   cfg,
 	}
 }
 
@@ -27,7 +42,14 @@ func (s Server) Run(ctx context.Context) error {
 		<-ctx.Done()
 		s.stop()
 	}()
+	if !s.config.AuthEnabled && s.config.TrustActorHeader {
+		log.Warn("authentication is disabled and X-Actor is trusted, only run this in development")
+	}
 	s.server = echo.New()
+	// X-Forwarded-For is only trusted from proxies of private networks,
+	// so a forged header does not dodge the limit of the address
+	s.server.IPExtractor = echo.ExtractIPFromXFFHeader()
+	s.done = ctx.Done()
 	RegisterMiddlewares(s.server)
 	s.RouterRegister(s.server)
 	return s.server.Start(fmt.Sprintf(":%v", s.config.Port))
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// Server-Sent Events, https://html.spec.whatwg.org/multipage/server-sent-events.html
+const (
+	MIMEEventStream   = "text/event-stream"
+	HeaderLastEventID = "Last-Event-ID"
+)
+
+const (
+	eventRate  = "rate"
+	eventReset = "reset"
+	// streamRetry is how long clients wait before reconnecting
+	streamRetry = 3 * time.Second
+	// defaultHeartbeat is used when the config has none
+	defaultHeartbeat = 15 * time.Second
+)
+
+// StreamRates pushes rate changes as Server-Sent Events, pairs
+// selects the FROM/TO pairs and Last-Event-ID resumes a stream.
+// A reset event is sent when the changes since Last-Event-ID are no
+// longer kept, the client has to fetch the rates again. Clients that
+// do not read fast enough are disconnected. Changes are only
+// streamed by the replica that made them, see stream.Broker
+//
+// Query params: pairs, last_event_id when the header cannot be set
+//
+// HTTP responses:
+// 200 OK
+// 400 Bad Request
+func (s Server) StreamRates(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "StreamRates",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+
+	pairs, err := core.ParsePairs(c.QueryParam("pairs"))
+	if err != nil {
+		lg.WithError(err).Error("core.ParsePairs")
+		return err
+	}
+	var lastID uint64
+	qLast := c.Request().Header.Get(HeaderLastEventID)
+	if qLast == "" {
+		qLast = c.QueryParam("last_event_id")
+	}
+	if qLast != "" {
+		if lastID, err = strconv.ParseUint(qLast, 10, 64); err != nil {
+			lg.WithError(err).Error("strconv.ParseUint")
+			return core.ErrInvalidEventID
+		}
+	}
+
+	sub := s.service.SubscribeRates(pairs, lastID)
+	defer s.service.UnsubscribeRates(sub)
+
+	h := c.Response().Header()
+	h.Set(echo.HeaderContentType, MIMEEventStream)
+	h.Set(echo.HeaderCacheControl, "no-cache")
+	h.Set(echo.HeaderConnection, "keep-alive")
+	// nginx would otherwise hold the events in its buffer
+	h.Set("X-Accel-Buffering", "no")
+	c.Response().WriteHeader(http.StatusOK)
+
+	w := c.Response()
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds())
+	if sub.Missed {
+		fmt.Fprintf(w, "event: %s\ndata: {}\n\n", eventReset)
+	}
+	for _, e := range sub.Backlog {
+		if err = writeRateEvent(w, e); err != nil {
+			return nil
+		}
+	}
+	w.Flush()
+	lg.WithField("pairs", pairs).Info("stream opened")
+
+	beat := s.config.StreamHeartbeat
+	if beat <= 0 {
+		beat = defaultHeartbeat
+	}
+	heartbeat := time.NewTicker(beat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			lg.Info("stream closed by the client")
+			return nil
+		case <-s.done:
+			return nil
+		case <-heartbeat.C:
+			if _, err = fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			w.Flush()
+		case e, ok := <-sub.Events():
+			if !ok {
+				if sub.Dropped() {
+					lg.Warn("stream dropped, the client is too slow")
+				}
+				return nil
+			}
+			if err = writeRateEvent(w, e); err != nil {
+				return nil
+			}
+			w.Flush()
+		}
+	}
+}
+
+func writeRateEvent(w *echo.Response, e core.RateEvent) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, eventRate, b)
+	return err
+}
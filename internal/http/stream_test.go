@@ -0,0 +1,119 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	rsv "github.com/arxdsilva/bravo/internal/service/mock"
+	"github.com/arxdsilva/bravo/internal/stream"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_StreamRates(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	broker := stream.NewBroker(stream.Config{Buffer: 8, History: 8})
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	published := core.RateEvent{
+		Action: core.RateActionUpdate,
+		Source: "manual",
+		Rate:   core.CurrencyRate{From: "USD", To: "BRL", Rate: 5, ValidFrom: at},
+		At:     at,
+	}
+	broker.Publish(published)
+
+	subscribed := make(chan *stream.Subscription, 1)
+	mock := rsv.NewMockResolver(ctrl)
+	mock.EXPECT().SubscribeRates([]string{"USD/BRL"}, uint64(0)).
+		DoAndReturn(func(pairs []string, lastID uint64) *stream.Subscription {
+			sub := broker.Subscribe(pairs, lastID)
+			subscribed <- sub
+			return sub
+		})
+	mock.EXPECT().UnsubscribeRates(gomock.Any()).Do(broker.Unsubscribe)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/v1/rates/stream?pairs=usd/brl", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	s := Server{service: mock}
+	done := make(chan error)
+	go func() { done <- s.StreamRates(c) }()
+
+	sub := <-subscribed
+	broker.Publish(core.RateEvent{Action: core.RateActionUpdate, Source: "exchange",
+		Rate: core.CurrencyRate{From: "BTC", To: "USD", Rate: 1}, At: at})
+	broker.Publish(published)
+	require.Eventually(t, func() bool { return len(sub.Events()) == 0 }, time.Second, time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	require.Equal(t, MIMEEventStream, rec.Header().Get(echo.HeaderContentType))
+	body := rec.Body.String()
+	require.True(t, strings.HasPrefix(body, "retry: 3000\n\n"), body)
+	require.NotContains(t, body, "BTC")
+	require.Contains(t, body, "id: 3\nevent: rate\ndata: {\"id\":3,\"action\":\"update\",\"source\":\"manual\"")
+}
+
+func Test_StreamRates_resume(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		lastID    string
+		wantErr   error
+		wantReset bool
+		wantIDs   []string
+	}{
+		{name: "invalid id", lastID: "abc", wantErr: core.ErrInvalidEventID},
+		{name: "resumes after the id", lastID: "1", wantIDs: []string{"id: 2\n", "id: 3\n"}},
+		{name: "ids of another process", lastID: "9", wantReset: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			broker := stream.NewBroker(stream.Config{Buffer: 8, History: 8})
+			for i := 0; i < 3; i++ {
+				broker.Publish(core.RateEvent{Rate: core.CurrencyRate{From: "USD", To: "BRL", Rate: float64(i)}})
+			}
+			mock := rsv.NewMockResolver(ctrl)
+			if tt.wantErr == nil {
+				mock.EXPECT().SubscribeRates(gomock.Any(), gomock.Any()).DoAndReturn(broker.Subscribe)
+				mock.EXPECT().UnsubscribeRates(gomock.Any()).Do(broker.Unsubscribe)
+			}
+
+			// a closed context ends the stream right after the backlog
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			req := httptest.NewRequest(http.MethodGet, "/v1/rates/stream", nil).WithContext(ctx)
+			req.Header.Set(HeaderLastEventID, tt.lastID)
+			rec := httptest.NewRecorder()
+
+			err := Server{service: mock}.StreamRates(echo.New().NewContext(req, rec))
+			if tt.wantErr != nil {
+				require.Equal(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			body := rec.Body.String()
+			require.Equal(t, tt.wantReset, strings.Contains(body, "event: reset\n"))
+			require.Equal(t, len(tt.wantIDs), strings.Count(body, "event: rate\n"))
+			for _, id := range tt.wantIDs {
+				require.Contains(t, body, id)
+			}
+		})
+	}
+}
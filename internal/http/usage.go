@@ -0,0 +1,66 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetUsage retrieves the requests made by each client in a month,
+// requests are only counted while there is a monthly quota
+//
+// the optional `period` query param (YYYY-MM) defaults to the current month
+//
+// HTTP responses:
+// 200 OK
+// 400 Bad Request
+// 500 Internal Server Error
+func (s Server) GetUsage(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "GetUsage",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+
+	period := time.Now()
+	if p := c.QueryParam("period"); p != "" {
+		period, err = time.Parse(core.UsagePeriodLayout, p)
+		if err != nil {
+			lg.WithError(err).Error("time.Parse")
+			return core.ErrInvalidPeriod
+		}
+	}
+
+	usage, err := s.service.GetUsage(c.Request().Context(), period)
+	if err != nil {
+		lg.WithError(err).Error("service.GetUsage")
+		return err
+	}
+	lg.Info("success")
+	return respond(c, http.StatusOK, usage)
+}
+
+// GetProviderBudget retrieves the monthly call budget of the exchange provider
+//
+// HTTP responses:
+// 200 OK
+// 500 Internal Server Error
+func (s Server) GetProviderBudget(c echo.Context) (err error) {
+	lg := log.WithFields(log.Fields{
+		"pkg":    "http",
+		"route":  "GetProviderBudget",
+		"cid":    c.Response().Header().Get(echo.HeaderXRequestID),
+		"key_id": keyID(c),
+	})
+	budget, err := s.service.GetProviderBudget(c.Request().Context())
+	if err != nil {
+		lg.WithError(err).Error("service.GetProviderBudget")
+		return err
+	}
+	lg.Info("success")
+	return respond(c, http.StatusOK, budget)
+}
@@ -0,0 +1,239 @@
+// Package metrics holds the prometheus collectors of the service,
+// they are exposed by the http server on /metrics
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "bravo"
+
+// Cache results
+const (
+	Hit  = "hit"
+	Miss = "miss"
+)
+
+// Outcomes of jobs such as the seed
+const (
+	Success = "success"
+	Failure = "failure"
+)
+
+var (
+	// HTTPRequestDuration tracks the handled requests per route and status
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of the HTTP requests per route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// GRPCRequestDuration tracks the handled calls per method and status code
+	GRPCRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of the gRPC calls per method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	// LegacyRequests counts the calls to unversioned routes, the
+	// calling clients are logged
+	LegacyRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "legacy_requests_total",
+		Help:      "Requests made to deprecated unversioned routes.",
+	}, []string{"method", "route"})
+
+	// ProviderRequestDuration tracks the calls made to the exchange providers
+	ProviderRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "provider",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of the calls made to the exchange providers.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider", "endpoint"})
+
+	ProviderErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "provider",
+		Name:      "errors_total",
+		Help:      "Failed calls to the exchange providers.",
+	}, []string{"provider", "endpoint"})
+
+	ProviderRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "provider",
+		Name:      "retries_total",
+		Help:      "Retried calls to the exchange providers.",
+	}, []string{"provider", "endpoint"})
+
+	// ProviderBudgetUsed and ProviderBudgetRemaining follow the monthly
+	// call budget, remaining is not set when the budget is unlimited
+	ProviderBudgetUsed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "provider",
+		Name:      "budget_used_calls",
+		Help:      "Calls made to the exchange provider in the current month.",
+	}, []string{"provider"})
+
+	ProviderBudgetRemaining = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "provider",
+		Name:      "budget_remaining_calls",
+		Help:      "Calls left in the monthly budget of the exchange provider.",
+	}, []string{"provider"})
+
+	// CacheRequests counts lookups per cache and result, the hit ratio
+	// is hits over the total
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Cache lookups per cache and result.",
+	}, []string{"cache", "result"})
+
+	// DBQueryDuration tracks the queries made by the postgres repository
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of the database queries per operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table", "status"})
+
+	SeedRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "seed",
+		Name:      "runs_total",
+		Help:      "Currency seed runs per outcome.",
+	}, []string{"result"})
+
+	SeedCurrencies = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "seed",
+		Name:      "currencies",
+		Help:      "Currencies inserted by the last successful seed.",
+	})
+
+	SeedLastSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "seed",
+		Name:      "last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful seed.",
+	})
+
+	// Rate is the latest rate seen for the configured pairs
+	Rate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "rate",
+		Help:      "Latest rate of the configured currency pairs.",
+	}, []string{"from", "to", "source"})
+
+	// StreamSubscribers is the amount of open rate streams
+	StreamSubscribers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "stream",
+		Name:      "subscribers",
+		Help:      "Open rate stream connections.",
+	})
+
+	StreamEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "stream",
+		Name:      "events_total",
+		Help:      "Rate events published per source.",
+	}, []string{"source"})
+
+	// StreamDropped counts the subscribers dropped for not keeping up
+	StreamDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "stream",
+		Name:      "dropped_total",
+		Help:      "Rate stream subscribers dropped because their queue was full.",
+	})
+
+	// AlertsTriggered counts the alert subscriptions that fired
+	AlertsTriggered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "alerts",
+		Name:      "triggered_total",
+		Help:      "Alert subscriptions triggered per condition.",
+	}, []string{"condition"})
+
+	// WebhookAttempts counts the delivery attempts per outcome
+	WebhookAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "alerts",
+		Name:      "webhook_attempts_total",
+		Help:      "Webhook delivery attempts per outcome.",
+	}, []string{"outcome"})
+
+	// OutboxPublished counts the outbox events accepted per sink
+	OutboxPublished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "published_total",
+		Help:      "Outbox events published per sink.",
+	}, []string{"sink"})
+
+	OutboxErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "errors_total",
+		Help:      "Failed outbox relay runs per sink.",
+	}, []string{"sink"})
+)
+
+// Registry has every collector of the service plus the go runtime
+// and process ones
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestDuration,
+		GRPCRequestDuration,
+		LegacyRequests,
+		ProviderRequestDuration,
+		ProviderErrors,
+		ProviderRetries,
+		ProviderBudgetUsed,
+		ProviderBudgetRemaining,
+		CacheRequests,
+		DBQueryDuration,
+		SeedRuns,
+		SeedCurrencies,
+		SeedLastSuccess,
+		Rate,
+		StreamSubscribers,
+		StreamEvents,
+		StreamDropped,
+		AlertsTriggered,
+		WebhookAttempts,
+		OutboxPublished,
+		OutboxErrors,
+	)
+}
+
+// Handler serves the registry in the prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// CacheLookup records a lookup on the named cache
+func CacheLookup(cache string, hit bool) {
+	result := Miss
+	if hit {
+		result = Hit
+	}
+	CacheRequests.WithLabelValues(cache, result).Inc()
+}
@@ -4,9 +4,16 @@ import (
 	"fmt"
 
 	"github.com/arxdsilva/bravo/internal/clients/exchange"
+	"github.com/arxdsilva/bravo/internal/clients/oidc"
+	"github.com/arxdsilva/bravo/internal/clients/webhook"
+	"github.com/arxdsilva/bravo/internal/grpc"
+	"github.com/arxdsilva/bravo/internal/health"
 	"github.com/arxdsilva/bravo/internal/http"
 	"github.com/arxdsilva/bravo/internal/logger"
+	"github.com/arxdsilva/bravo/internal/outbox"
+	"github.com/arxdsilva/bravo/internal/service"
 	"github.com/arxdsilva/bravo/internal/storage/postgres"
+	"github.com/arxdsilva/bravo/internal/tracing"
 	"github.com/go-playground/validator/v10"
 	"github.com/kelseyhightower/envconfig"
 )
@@ -15,9 +22,16 @@ const prefix = "APP"
 
 type Config struct {
 	HTTP     http.Config
+	GRPC     grpc.Config
 	Log      logger.Config
 	DB       postgres.Config
 	Exchange exchange.Config
+	Webhook  webhook.Config
+	Service  service.Config
+	Outbox   outbox.Config
+	OIDC     oidc.Config
+	Tracing  tracing.Config
+	Health   health.Config
 }
 
 func FromEnv() (*Config, error) {
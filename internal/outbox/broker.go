@@ -0,0 +1,49 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/arxdsilva/bravo/internal/core"
+)
+
+// Headers set on the messages of the broker sink
+const (
+	HeaderEventID   = "event-id"
+	HeaderEventType = "event-type"
+)
+
+// Producer is what the broker sink needs from a message broker
+// client, ex: a Kafka writer or a NATS JetStream context wrapped to
+// this interface. Produce returns once the broker stored the message
+type Producer interface {
+	Produce(ctx context.Context, topic, key string, value []byte, headers map[string]string) error
+}
+
+// Broker publishes each event as a message keyed by the event key,
+// so the events of an entity keep their order within a partition
+type Broker struct {
+	producer Producer
+	topic    string
+}
+
+func NewBrokerSink(p Producer, topic string) Broker {
+	return Broker{producer: p, topic: topic}
+}
+
+func (b Broker) Publish(ctx context.Context, events core.OutboxEvents) error {
+	for _, e := range events {
+		value, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		headers := map[string]string{
+			HeaderEventID:   e.ID,
+			HeaderEventType: string(e.Type),
+		}
+		if err = b.producer.Produce(ctx, b.topic, e.Key, value, headers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package outbox
+
+import "time"
+
+// Sinks that can be selected from the environment, broker adapters
+// are plugged in code with NewBrokerSink
+const (
+	SinkNone   = "none"
+	SinkMemory = "memory"
+	SinkFile   = "file"
+	SinkHTTP   = "http"
+)
+
+type Config struct {
+	// Sink is where the relay publishes the events, with none the
+	// events are kept in the outbox until a sink is configured
+	Sink string `envconfig:"APP_OUTBOX_SINK" default:"none" validate:"oneof=none memory file http"`
+	// File is the JSON lines file the file sink appends to
+	File string `envconfig:"APP_OUTBOX_FILE" default:"outbox.jsonl"`
+	// URL receives the batches of the http sink as a JSON array
+	URL string `envconfig:"APP_OUTBOX_URL" default:""`
+	// Token is sent as a bearer token by the http sink when set
+	Token   string        `envconfig:"APP_OUTBOX_TOKEN" default:""`
+	Timeout time.Duration `envconfig:"APP_OUTBOX_TIMEOUT" default:"5s"`
+}
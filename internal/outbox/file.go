@@ -0,0 +1,47 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/arxdsilva/bravo/internal/core"
+)
+
+// File appends the events to a JSON lines file, one event per line
+type File struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFile(path string) (*File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &File{file: f}, nil
+}
+
+// Publish writes the batch at once and syncs it to disk before
+// the events are marked as published
+func (f *File) Publish(ctx context.Context, events core.OutboxEvents) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return f.file.Sync()
+}
+
+func (f *File) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,53 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/arxdsilva/bravo/internal/core"
+)
+
+// HTTP posts each batch as a JSON array, any answer outside of 2xx
+// fails the batch so it is published again
+type HTTP struct {
+	url    string
+	token  string
+	client http.Client
+}
+
+func NewHTTP(cfg Config) HTTP {
+	return HTTP{
+		url:    cfg.URL,
+		token:  cfg.Token,
+		client: http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (h HTTP) Publish(ctx context.Context, events core.OutboxEvents) error {
+	b, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("outbox receiver responded %v", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	"github.com/arxdsilva/bravo/internal/core"
+)
+
+// memoryLimit is the amount of events kept by the memory sink
+const memoryLimit = 10000
+
+// Memory keeps the latest events in memory, it is meant for tests
+// and local runs
+type Memory struct {
+	mu     sync.Mutex
+	events core.OutboxEvents
+}
+
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) Publish(ctx context.Context, events core.OutboxEvents) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, events...)
+	if len(m.events) > memoryLimit {
+		m.events = append(core.OutboxEvents{}, m.events[len(m.events)-memoryLimit:]...)
+	}
+	return nil
+}
+
+// Events lists the kept events, oldest first
+func (m *Memory) Events() core.OutboxEvents {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append(core.OutboxEvents{}, m.events...)
+}
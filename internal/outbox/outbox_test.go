@@ -0,0 +1,132 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/stretchr/testify/require"
+)
+
+var testEvents = core.OutboxEvents{
+	{ID: "e1", Type: core.EventCurrencyCreated, Key: "BRL", Data: json.RawMessage(`{"symbol":"BRL"}`)},
+	{ID: "e2", Type: core.EventRateUpdated, Key: "USD/BRL", Data: json.RawMessage(`{"rate":5.5}`)},
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		cfg      Config
+		wantSink bool
+		wantErr  bool
+	}{
+		{name: "none", cfg: Config{Sink: SinkNone}},
+		{name: "memory", cfg: Config{Sink: SinkMemory}, wantSink: true},
+		{name: "file", cfg: Config{Sink: SinkFile, File: filepath.Join(t.TempDir(), "outbox.jsonl")}, wantSink: true},
+		{name: "http without url", cfg: Config{Sink: SinkHTTP}, wantErr: true},
+		{name: "http", cfg: Config{Sink: SinkHTTP, URL: "http://localhost"}, wantSink: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sink, err := New(tt.cfg)
+			require.Equal(t, tt.wantErr, err != nil, err)
+			require.Equal(t, tt.wantSink, sink != nil)
+		})
+	}
+}
+
+func Test_Memory(t *testing.T) {
+	t.Parallel()
+	m := NewMemory()
+	require.NoError(t, m.Publish(context.Background(), testEvents))
+	require.NoError(t, m.Publish(context.Background(), testEvents[:1]))
+	require.Equal(t, append(testEvents, testEvents[0]), m.Events())
+}
+
+func Test_File(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	f, err := NewFile(path)
+	require.NoError(t, err)
+	require.NoError(t, f.Publish(context.Background(), testEvents))
+	require.NoError(t, f.Publish(context.Background(), testEvents[1:]))
+	require.NoError(t, f.Close())
+
+	r, err := os.Open(path)
+	require.NoError(t, err)
+	defer r.Close()
+	var ids []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		e := core.OutboxEvent{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		ids = append(ids, e.ID)
+	}
+	require.Equal(t, []string{"e1", "e2", "e2"}, ids)
+}
+
+func Test_HTTP(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "accepted", status: http.StatusAccepted},
+		{name: "rejected", status: http.StatusServiceUnavailable, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+				var got core.OutboxEvents
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				require.Equal(t, testEvents, got)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			err := NewHTTP(Config{URL: srv.URL, Token: "token", Timeout: time.Second}).Publish(context.Background(), testEvents)
+			require.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+type producerFunc func(ctx context.Context, topic, key string, value []byte, headers map[string]string) error
+
+func (f producerFunc) Produce(ctx context.Context, topic, key string, value []byte, headers map[string]string) error {
+	return f(ctx, topic, key, value, headers)
+}
+
+func Test_Broker(t *testing.T) {
+	t.Parallel()
+	var keys []string
+	sink := NewBrokerSink(producerFunc(func(_ context.Context, topic, key string, value []byte, headers map[string]string) error {
+		require.Equal(t, "bravo.events", topic)
+		e := core.OutboxEvent{}
+		require.NoError(t, json.Unmarshal(value, &e))
+		require.Equal(t, e.ID, headers[HeaderEventID])
+		require.Equal(t, string(e.Type), headers[HeaderEventType])
+		keys = append(keys, key)
+		if e.ID == "e2" {
+			return errors.New("broker is down")
+		}
+		return nil
+	}), "bravo.events")
+
+	require.Error(t, sink.Publish(context.Background(), testEvents))
+	require.Equal(t, []string{"BRL", "USD/BRL"}, keys)
+}
@@ -0,0 +1,31 @@
+// Package outbox has the sinks the relay publishes the domain events
+// of the outbox to. Sinks may receive an event more than once, so
+// consumers deduplicate them by the event ID
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arxdsilva/bravo/internal/core"
+)
+
+type Sink interface {
+	Publish(ctx context.Context, events core.OutboxEvents) error
+}
+
+// New creates the sink selected by cfg, it is nil for SinkNone
+func New(cfg Config) (Sink, error) {
+	switch cfg.Sink {
+	case SinkMemory:
+		return NewMemory(), nil
+	case SinkFile:
+		return NewFile(cfg.File)
+	case SinkHTTP:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("APP_OUTBOX_URL is required by the %v sink", SinkHTTP)
+		}
+		return NewHTTP(cfg), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,105 @@
+// Package ratelimit holds the token buckets shared by the http and
+// grpc servers, each client has a bucket per route group
+package ratelimit
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Route groups share the same limits
+const (
+	GroupConvert = "convert"
+	GroupRead    = "read"
+	GroupWrite   = "write"
+	GroupAdmin   = "admin"
+	// GroupIP is the bucket of a client address shared by every route,
+	// taken before the authentication so rejected credentials count
+	GroupIP = "ip"
+)
+
+// bucketIdle is how long an untouched bucket is kept in memory
+const bucketIdle = 10 * time.Minute
+
+// bucket is a token bucket refilled at rate tokens per second
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter keeps a bucket per route group and client
+type Limiter struct {
+	mu      sync.Mutex
+	rates   map[string]float64
+	bursts  map[string]int
+	buckets map[string]*bucket
+	sweep   time.Time
+}
+
+// New creates a limiter, rates are the sustained requests per second
+// and bursts the bucket size of each group
+func New(rates map[string]float64, bursts map[string]int) *Limiter {
+	return &Limiter{
+		rates:   rates,
+		bursts:  bursts,
+		buckets: map[string]*bucket{},
+		sweep:   time.Now(),
+	}
+}
+
+// Allow takes a token from the bucket of the client in the group,
+// it returns the remaining tokens and how long until the bucket
+// has a token again
+func (l *Limiter) Allow(group, client string, now time.Time) (ok bool, limit, remaining int, reset time.Duration) {
+	return l.AllowN(group, client, 1, now)
+}
+
+// AllowN is Allow for a request that costs n tokens, none are taken
+// when the bucket has less than n, so n over the burst never passes
+func (l *Limiter) AllowN(group, client string, n int, now time.Time) (ok bool, limit, remaining int, reset time.Duration) {
+	rate, burst := l.rates[group], l.bursts[group]
+	if rate <= 0 || burst <= 0 {
+		return true, 0, 0, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.evict(now)
+
+	key := group + "|" + client
+	b, found := l.buckets[key]
+	if !found {
+		b = &bucket{tokens: float64(burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	if b.tokens >= float64(n) {
+		b.tokens -= float64(n)
+		ok = true
+	}
+	missing := math.Max(0, float64(n)-b.tokens)
+	reset = time.Duration(missing / rate * float64(time.Second))
+	return ok, burst, int(b.tokens), reset
+}
+
+// evict drops idle buckets, they would be full by now anyway
+func (l *Limiter) evict(now time.Time) {
+	if now.Sub(l.sweep) < bucketIdle {
+		return
+	}
+	for k, b := range l.buckets {
+		if now.Sub(b.last) > bucketIdle {
+			delete(l.buckets, k)
+		}
+	}
+	l.sweep = now
+}
+
+// Seconds rounds up, a zero Retry-After would invite a retry storm
+func Seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Limiter_Allow(t *testing.T) {
+	t.Parallel()
+	l := New(map[string]float64{GroupRead: 1}, map[string]int{GroupRead: 2})
+	now := time.Now()
+
+	ok, limit, remaining, _ := l.Allow(GroupRead, "a", now)
+	require.True(t, ok)
+	require.Equal(t, 2, limit)
+	require.Equal(t, 1, remaining)
+
+	ok, _, remaining, _ = l.Allow(GroupRead, "a", now)
+	require.True(t, ok)
+	require.Equal(t, 0, remaining)
+
+	ok, _, _, reset := l.Allow(GroupRead, "a", now)
+	require.False(t, ok)
+	require.Equal(t, time.Second, reset)
+
+	// other clients have their own bucket
+	ok, _, _, _ = l.Allow(GroupRead, "b", now)
+	require.True(t, ok)
+
+	// a token is refilled after a second
+	ok, _, _, _ = l.Allow(GroupRead, "a", now.Add(time.Second))
+	require.True(t, ok)
+
+	// groups without limits are not limited
+	ok, limit, _, _ = l.Allow(GroupWrite, "a", now)
+	require.True(t, ok)
+	require.Equal(t, 0, limit)
+}
+
+func Test_Limiter_AllowN(t *testing.T) {
+	t.Parallel()
+	l := New(map[string]float64{GroupConvert: 1}, map[string]int{GroupConvert: 3})
+	now := time.Now()
+
+	ok, _, remaining, _ := l.AllowN(GroupConvert, "a", 2, now)
+	require.True(t, ok)
+	require.Equal(t, 1, remaining)
+	// nothing is taken when the bucket has less than n
+	ok, _, remaining, reset := l.AllowN(GroupConvert, "a", 2, now)
+	require.False(t, ok)
+	require.Equal(t, 1, remaining)
+	require.Equal(t, time.Second, reset)
+	ok, _, _, _ = l.Allow(GroupConvert, "a", now)
+	require.True(t, ok)
+	// more than the burst never passes
+	ok, _, _, _ = l.AllowN(GroupConvert, "b", 4, now)
+	require.False(t, ok)
+}
+
+func Test_Seconds(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "1", Seconds(time.Millisecond))
+	require.Equal(t, "0", Seconds(0))
+	require.Equal(t, "2", Seconds(2*time.Second))
+}
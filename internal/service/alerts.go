@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/metrics"
+	"github.com/arxdsilva/bravo/internal/stream"
+	"github.com/arxdsilva/bravo/internal/tracing"
+	log "github.com/sirupsen/logrus"
+)
+
+// secretPrefix identifies webhook secrets in secret scanners and logs
+const secretPrefix = "whsec"
+
+// CreateAlertSubscription registers an alert, the secret signing
+// its deliveries is generated and returned only here
+func (s Service) CreateAlertSubscription(ctx context.Context, a core.AlertSubscription) (sub core.AlertSubscription, err error) {
+	ctx, span := startSpan(ctx, "CreateAlertSubscription")
+	defer func() { tracing.End(span, err) }()
+	if err = s.checkAlert(ctx, &a); err != nil {
+		return
+	}
+	if a.Secret, err = generateSecret(); err != nil {
+		return
+	}
+	a.Active = true
+	return s.Repo.CreateAlertSubscription(ctx, a)
+}
+
+func (s Service) GetAlertSubscriptions(ctx context.Context) (subs core.AlertSubscriptions, err error) {
+	ctx, span := startSpan(ctx, "GetAlertSubscriptions")
+	defer func() { tracing.End(span, err) }()
+	return s.Repo.GetAlertSubscriptions(ctx)
+}
+
+func (s Service) GetAlertSubscription(ctx context.Context, id string) (sub core.AlertSubscription, err error) {
+	ctx, span := startSpan(ctx, "GetAlertSubscription")
+	defer func() { tracing.End(span, err) }()
+	sub, err = s.Repo.GetAlertSubscription(ctx, id)
+	if errors.Is(err, core.ErrNotFound) {
+		err = core.ErrAlertNotFound
+	}
+	return
+}
+
+// UpdateAlertSubscription replaces the settings of an alert, its
+// secret does not change
+func (s Service) UpdateAlertSubscription(ctx context.Context, a core.AlertSubscription) (sub core.AlertSubscription, err error) {
+	ctx, span := startSpan(ctx, "UpdateAlertSubscription")
+	defer func() { tracing.End(span, err) }()
+	if err = s.checkAlert(ctx, &a); err != nil {
+		return
+	}
+	sub, err = s.Repo.UpdateAlertSubscription(ctx, a)
+	if errors.Is(err, core.ErrNotFound) {
+		err = core.ErrAlertNotFound
+	}
+	return
+}
+
+func (s Service) RemoveAlertSubscription(ctx context.Context, id string) (err error) {
+	ctx, span := startSpan(ctx, "RemoveAlertSubscription")
+	defer func() { tracing.End(span, err) }()
+	err = s.Repo.RemoveAlertSubscription(ctx, id)
+	if errors.Is(err, core.ErrNotFound) {
+		return core.ErrAlertNotFound
+	}
+	return err
+}
+
+// GetWebhookDeliveries lists the delivery log of an alert
+func (s Service) GetWebhookDeliveries(ctx context.Context, subscriptionID string) (ds core.WebhookDeliveries, err error) {
+	ctx, span := startSpan(ctx, "GetWebhookDeliveries")
+	defer func() { tracing.End(span, err) }()
+	if _, err = s.GetAlertSubscription(ctx, subscriptionID); err != nil {
+		return
+	}
+	return s.Repo.GetWebhookDeliveries(ctx, subscriptionID)
+}
+
+func (s Service) checkAlert(ctx context.Context, a *core.AlertSubscription) error {
+	a.From = strings.ToUpper(a.From)
+	a.To = strings.ToUpper(a.To)
+	if err := a.Check(); err != nil {
+		return err
+	}
+	return s.ensureCurrencies(ctx, a.From, a.To)
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretPrefix + "_" + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// alertState keeps the rates seen per pair, the previous rate tells
+// whether a threshold was crossed and the samples whether the rate
+// moved within the window of change alerts
+type alertState struct {
+	mu      sync.Mutex
+	samples map[string][]rateSample
+}
+
+type rateSample struct {
+	rate float64
+	at   time.Time
+}
+
+func newAlertState() *alertState {
+	return &alertState{samples: map[string][]rateSample{}}
+}
+
+// observe records the rate of the pair keeping the samples of the
+// last keep, it returns the samples seen before this one
+func (a *alertState) observe(pair string, rate float64, at time.Time, keep time.Duration) []rateSample {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	seen := a.samples[pair]
+	// the latest sample is always kept, it is the previous rate
+	// of the next update
+	i := 0
+	for i < len(seen)-1 && seen[i].at.Before(at.Add(-keep)) {
+		i++
+	}
+	kept := append([]rateSample{}, seen[i:]...)
+	a.samples[pair] = append(kept, rateSample{rate: rate, at: at})
+	return seen[i:]
+}
+
+// windowBase is the sample since the start of the window that moved
+// the most from rate, 0 when there is none
+func windowBase(samples []rateSample, since time.Time, rate float64) (base float64) {
+	var moved float64
+	for _, s := range samples {
+		if s.at.Before(since) {
+			continue
+		}
+		m := (rate - s.rate) / s.rate
+		if m < 0 {
+			m = -m
+		}
+		if base == 0 || m > moved {
+			base, moved = s.rate, m
+		}
+	}
+	return base
+}
+
+// alertDelivery is a delivery waiting to be posted
+type alertDelivery struct {
+	sub      core.AlertSubscription
+	delivery core.WebhookDelivery
+}
+
+// RunAlerts evaluates the alert subscriptions on every rate update,
+// including the windows scheduled ahead when they take effect, and
+// posts the deliveries with n until ctx is done. Deliveries left
+// pending by a previous run are resumed
+func (s Service) RunAlerts(ctx context.Context, n Notifier) {
+	lg := log.WithFields(log.Fields{"pkg": "service", "fn": "RunAlerts"})
+	jobs := make(chan alertDelivery)
+	var wg sync.WaitGroup
+	for i := 0; i < s.Config.WebhookWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				s.deliver(ctx, n, j)
+			}
+		}()
+	}
+	defer func() {
+		close(jobs)
+		wg.Wait()
+	}()
+
+	sub := s.events.Subscribe(nil, 0)
+	s.resumeDeliveries(ctx, jobs)
+	for {
+		lastID := s.watchAlerts(ctx, sub, jobs)
+		s.events.Unsubscribe(sub)
+		if ctx.Err() != nil {
+			return
+		}
+		lg.Warn("alert evaluator fell behind, resuming the rate events")
+		sub = s.events.Subscribe(nil, lastID)
+		if sub.Missed {
+			lg.Error("rate events were lost, some alerts were not evaluated")
+		}
+	}
+}
+
+// watchAlerts evaluates the events of sub until it is dropped or ctx
+// is done, it returns the id of the last event evaluated
+func (s Service) watchAlerts(ctx context.Context, sub *stream.Subscription, jobs chan<- alertDelivery) (lastID uint64) {
+	evaluate := func(e core.RateEvent) bool {
+		lastID = e.ID
+		deliveries, err := s.evaluateAlerts(ctx, e)
+		if err != nil {
+			log.WithFields(log.Fields{"pkg": "service", "pair": e.Pair()}).
+				WithError(err).Error("evaluateAlerts")
+		}
+		for _, d := range deliveries {
+			select {
+			case jobs <- d:
+			case <-ctx.Done():
+				return false
+			}
+		}
+		return true
+	}
+	for _, e := range sub.Backlog {
+		if !evaluate(e) {
+			return
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-sub.Events():
+			if !ok || !evaluate(e) {
+				return
+			}
+		}
+	}
+}
+
+// evaluateAlerts fires the subscriptions of the pair of the event,
+// recording a pending delivery for each of them
+func (s Service) evaluateAlerts(ctx context.Context, e core.RateEvent) (deliveries []alertDelivery, err error) {
+	rate := e.Rate.Rate
+	// removals and windows starting later do not change the rate
+	if e.Action == core.RateActionDelete || rate <= 0 || e.Rate.ValidFrom.After(e.At) {
+		return nil, nil
+	}
+	ctx, span := startSpan(ctx, "evaluateAlerts")
+	defer func() { tracing.End(span, err) }()
+	subs, err := s.Repo.GetPairAlertSubscriptions(ctx, e.Rate.From, e.Rate.To)
+	if err != nil {
+		return
+	}
+	var keep time.Duration
+	for _, a := range subs {
+		if w := time.Duration(a.Window); w > keep {
+			keep = w
+		}
+	}
+	samples := s.alerts.observe(e.Pair(), rate, e.At, keep)
+	if len(samples) == 0 {
+		return nil, nil
+	}
+	previous := samples[len(samples)-1].rate
+
+	// every replica evaluates the rate changes it sees, the ones seen
+	// within AlertDedupe are fired by the first of them
+	dedupe := e.At.Add(-s.Config.AlertDedupe)
+	for _, a := range subs {
+		base, since := previous, dedupe
+		if a.Condition == core.AlertChange {
+			// fire once per window
+			window := e.At.Add(-time.Duration(a.Window))
+			base = windowBase(samples, window, rate)
+			if window.Before(since) {
+				since = window
+			}
+		}
+		ok, change := a.Triggered(base, rate)
+		if !ok {
+			continue
+		}
+		if ok, err = s.Repo.TriggerAlertSubscription(ctx, a.ID, e.At, since); err != nil {
+			return
+		}
+		if !ok {
+			continue
+		}
+		metrics.AlertsTriggered.WithLabelValues(string(a.Condition)).Inc()
+		payload, err := json.Marshal(core.AlertEvent{
+			Event:        core.AlertEventName,
+			Subscription: a.ID,
+			Pair:         a.Pair(),
+			Condition:    a.Condition,
+			Threshold:    a.Threshold,
+			Window:       a.Window,
+			Previous:     base,
+			Rate:         rate,
+			Change:       change,
+			Source:       e.Source,
+			At:           e.At,
+		})
+		if err != nil {
+			return deliveries, err
+		}
+		d, err := s.Repo.CreateWebhookDelivery(ctx, core.WebhookDelivery{
+			SubscriptionID: a.ID,
+			Payload:        payload,
+			Status:         core.DeliveryPending,
+		})
+		if err != nil {
+			return deliveries, err
+		}
+		deliveries = append(deliveries, alertDelivery{sub: a, delivery: d})
+	}
+	return deliveries, nil
+}
+
+// resumeDeliveries queues the deliveries left pending by a restart
+func (s Service) resumeDeliveries(ctx context.Context, jobs chan<- alertDelivery) {
+	lg := log.WithFields(log.Fields{"pkg": "service", "fn": "resumeDeliveries"})
+	pending, err := s.Repo.GetPendingWebhookDeliveries(ctx)
+	if err != nil {
+		lg.WithError(err).Error("repo.GetPendingWebhookDeliveries")
+		return
+	}
+	for _, d := range pending {
+		sub, err := s.Repo.GetAlertSubscription(ctx, d.SubscriptionID)
+		if err != nil {
+			lg.WithError(err).WithField("delivery", d.ID).Error("repo.GetAlertSubscription")
+			continue
+		}
+		select {
+		case jobs <- alertDelivery{sub: sub, delivery: d}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// deliver posts a delivery retrying with an exponential backoff,
+// every attempt is recorded in the delivery log
+func (s Service) deliver(ctx context.Context, n Notifier, j alertDelivery) {
+	lg := log.WithFields(log.Fields{
+		"pkg":          "service",
+		"fn":           "deliver",
+		"subscription": j.sub.ID,
+		"delivery":     j.delivery.ID,
+	})
+	d := j.delivery
+	backoff := s.Config.WebhookBackoff
+	for d.Attempts < s.Config.WebhookAttempts {
+		if d.Attempts > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		// replicas resuming the pending deliveries may hold the same
+		// one, only the replica that claims the attempt posts it
+		now := time.Now()
+		claimed, err := s.Repo.ClaimWebhookDelivery(ctx, d.ID, d.Attempts, now, now.Add(s.Config.WebhookLease))
+		if err != nil {
+			lg.WithError(err).Error("repo.ClaimWebhookDelivery")
+			return
+		}
+		if !claimed {
+			return
+		}
+		code, err := n.Deliver(ctx, j.sub, d)
+		if ctx.Err() != nil {
+			// shutting down, the delivery is resumed on the next start
+			return
+		}
+		d.Attempts++
+		d.ResponseCode = code
+		d.Error = ""
+		switch {
+		case err == nil:
+			now := time.Now()
+			d.Status = core.DeliveryDelivered
+			d.DeliveredAt = &now
+			metrics.WebhookAttempts.WithLabelValues(metrics.Success).Inc()
+		case d.Attempts >= s.Config.WebhookAttempts:
+			d.Status = core.DeliveryFailed
+			fallthrough
+		default:
+			d.Error = err.Error()
+			metrics.WebhookAttempts.WithLabelValues(metrics.Failure).Inc()
+			lg.WithError(err).WithField("attempt", d.Attempts).Warn("notifier.Deliver")
+		}
+		if uerr := s.Repo.UpdateWebhookDelivery(ctx, d); uerr != nil {
+			lg.WithError(uerr).Error("repo.UpdateWebhookDelivery")
+		}
+		if d.Status != core.DeliveryPending {
+			return
+		}
+	}
+}
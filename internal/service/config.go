@@ -0,0 +1,67 @@
+package service
+
+import (
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/stream"
+)
+
+type Config struct {
+	// MaxDeviation is the percentage a manual rate may differ from
+	// the market rate before it is rejected
+	MaxDeviation float64 `envconfig:"APP_RATE_MAX_DEVIATION" default:"10" validate:"gt=0"`
+	// PairMaxDeviation overrides MaxDeviation per pair, ex: USD/BRL:5,BTC/USD:20
+	PairMaxDeviation map[string]float64 `envconfig:"APP_RATE_PAIR_MAX_DEVIATION"`
+	// MetricPairs are the pairs whose latest rate is exported as a
+	// metric, ex: USD/BRL,BTC/USD
+	MetricPairs []string `envconfig:"APP_METRICS_RATE_PAIRS"`
+	// ImportMaxRows is the most rows a rate import can have
+	ImportMaxRows int `envconfig:"APP_RATE_IMPORT_MAX_ROWS" default:"1000" validate:"gt=0"`
+	// IdempotencyTTL is how long the response of a request sent with
+	// an Idempotency-Key is replayed to its retries
+	IdempotencyTTL time.Duration `envconfig:"APP_IDEMPOTENCY_TTL" default:"24h"`
+	// IdempotencyLease is how long a request in flight holds its key,
+	// keys left behind by a crashed replica are freed after it. It has
+	// to be longer than the slowest request
+	IdempotencyLease time.Duration `envconfig:"APP_IDEMPOTENCY_LEASE" default:"5m" validate:"gt=0"`
+	// WebhookAttempts is how many times an alert delivery is tried
+	WebhookAttempts int `envconfig:"APP_WEBHOOK_ATTEMPTS" default:"5" validate:"gt=0"`
+	// WebhookBackoff is the wait before the first retry of a
+	// delivery, doubled on each attempt
+	WebhookBackoff time.Duration `envconfig:"APP_WEBHOOK_BACKOFF" default:"1s"`
+	// WebhookLease is how long a replica holds a delivery attempt,
+	// it has to be longer than APP_WEBHOOK_TIMEOUT
+	WebhookLease time.Duration `envconfig:"APP_WEBHOOK_LEASE" default:"1m" validate:"gt=0"`
+	// AlertDedupe is how long a threshold alert is not fired again,
+	// replicas seeing the same rate change fire it once
+	AlertDedupe time.Duration `envconfig:"APP_ALERT_DEDUPE" default:"1m"`
+	// WebhookWorkers is the amount of deliveries made concurrently
+	WebhookWorkers int `envconfig:"APP_WEBHOOK_WORKERS" default:"4" validate:"gt=0"`
+	// OutboxBatch is the most events the relay publishes at once
+	OutboxBatch int `envconfig:"APP_OUTBOX_BATCH" default:"100" validate:"gt=0"`
+	// OutboxInterval is how often the relay looks for new events
+	OutboxInterval time.Duration `envconfig:"APP_OUTBOX_INTERVAL" default:"1s" validate:"gt=0"`
+	// OutboxRetention is how long published events are kept
+	OutboxRetention time.Duration `envconfig:"APP_OUTBOX_RETENTION" default:"168h"`
+	// ScheduleInterval is how often the rate windows scheduled ahead
+	// are looked up, they are announced up to this late
+	ScheduleInterval time.Duration `envconfig:"APP_RATE_SCHEDULE_INTERVAL" default:"1s" validate:"gt=0"`
+	// Stream configures the broker of live rate events
+	Stream stream.Config
+}
+
+func (c Config) maxDeviation(from, to string) float64 {
+	if max, ok := c.PairMaxDeviation[from+"/"+to]; ok {
+		return max
+	}
+	return c.MaxDeviation
+}
+
+func (c Config) tracked(from, to string) bool {
+	for _, p := range c.MetricPairs {
+		if p == from+"/"+to {
+			return true
+		}
+	}
+	return false
+}
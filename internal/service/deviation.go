@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// marketCacheName labels the market cache in the metrics
+const marketCacheName = "market"
+
+// marketCache keeps the last rate seen from the exchange provider
+// per pair, used when the provider cannot be reached
+type marketCache struct {
+	mu    sync.RWMutex
+	rates map[string]marketRate
+}
+
+type marketRate struct {
+	rate float64
+	at   time.Time
+}
+
+func newMarketCache() *marketCache {
+	return &marketCache{rates: map[string]marketRate{}}
+}
+
+// set stores the rate and reports whether it differs from the last one
+func (m *marketCache) set(from, to string, rate float64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	last, ok := m.rates[from+"/"+to]
+	m.rates[from+"/"+to] = marketRate{rate: rate, at: time.Now()}
+	return !ok || last.rate != rate
+}
+
+func (m *marketCache) get(from, to string) (marketRate, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	r, ok := m.rates[from+"/"+to]
+	return r, ok
+}
+
+// marketRate retrieves the current provider rate of a pair,
+// falling back to the last cached one
+func (s Service) marketRate(ctx context.Context, from, to string) (float64, error) {
+	resp, err := s.Exchange.Exchange(ctx, from, to, 1)
+	if err == nil && resp.ConvertedAmount > 0 {
+		s.marketSeen(from, to, resp.ConversionSource, resp.ConvertedAmount)
+		return resp.ConvertedAmount, nil
+	}
+	cached, ok := s.market.get(from, to)
+	metrics.CacheLookup(marketCacheName, ok)
+	if !ok {
+		return 0, core.ErrMarketRateUnavailable
+	}
+	log.WithFields(log.Fields{
+		"pkg":       "service",
+		"from":      from,
+		"to":        to,
+		"cached_at": cached.at,
+	}).Warn("using cached market rate")
+	return cached.rate, nil
+}
+
+// marketSeen keeps a rate answered by the provider, changes are
+// announced to the rate stream
+func (s Service) marketSeen(from, to, source string, rate float64) {
+	s.observeRate(from, to, source, rate)
+	if !s.market.set(from, to, rate) {
+		return
+	}
+	s.publishRate(core.RateActionUpdate, source, core.CurrencyRate{
+		From: from, To: to, Rate: rate, ValidFrom: time.Now(),
+	})
+}
+
+// checkDeviation rejects manual rates that are too far from the
+// market, unless the change was explicitly forced
+func (s Service) checkDeviation(ctx context.Context, rate core.CurrencyRate, override core.Override) error {
+	if override.Force {
+		return nil
+	}
+	market, err := s.marketRate(ctx, rate.From, rate.To)
+	if err != nil {
+		return err
+	}
+	return s.deviation(rate, market)
+}
+
+// marketFor fetches the market rate the deviation check of a change
+// compares with, it is zero when the change is not checked
+func (s Service) marketFor(ctx context.Context, action core.RateAction, rate core.CurrencyRate, override core.Override) (float64, error) {
+	if action == core.RateActionDelete || override.Force {
+		return 0, nil
+	}
+	return s.marketRate(ctx, rate.From, rate.To)
+}
+
+// deviation compares rate with the market rate of its pair
+func (s Service) deviation(rate core.CurrencyRate, market float64) error {
+	max := s.Config.maxDeviation(rate.From, rate.To)
+	deviation := math.Abs(rate.Rate-market) / market * 100
+	if deviation > max {
+		return fmt.Errorf("%w: %v is %.2f%% away from the market rate %v, max is %.2f%%",
+			core.ErrRateDeviation, rate.Rate, deviation, market, max)
+	}
+	return nil
+}
+
+// observeRate exports the latest rate of the pairs listed in the config
+func (s Service) observeRate(from, to, source string, rate float64) {
+	if !s.Config.tracked(from, to) {
+		return
+	}
+	metrics.Rate.WithLabelValues(from, to, source).Set(rate)
+}
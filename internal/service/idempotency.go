@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/tracing"
+)
+
+// BeginIdempotentRequest claims the key of the request for the
+// IdempotencyLease. A zero Status on the result means the caller
+// holds the key and has to run the request, otherwise the stored
+// response has to be replayed
+func (s Service) BeginIdempotentRequest(ctx context.Context, r core.IdempotentRequest) (held core.IdempotentRequest, err error) {
+	ctx, span := startSpan(ctx, "BeginIdempotentRequest")
+	defer func() { tracing.End(span, err) }()
+	if err = r.Check(); err != nil {
+		return
+	}
+	r.Status = 0
+	r.ExpiresAt = time.Now().Add(s.Config.IdempotencyLease)
+	held, claimed, err := s.Repo.ClaimIdempotencyKey(ctx, r)
+	if err != nil || claimed {
+		return
+	}
+	if held.Fingerprint != r.Fingerprint {
+		return held, core.ErrIdempotencyKeyReused
+	}
+	if !held.Completed() {
+		return held, core.ErrIdempotencyKeyInFlight
+	}
+	return held, nil
+}
+
+// CompleteIdempotentRequest stores the response of a claimed request,
+// it is replayed for the IdempotencyTTL
+func (s Service) CompleteIdempotentRequest(ctx context.Context, r core.IdempotentRequest) (err error) {
+	ctx, span := startSpan(ctx, "CompleteIdempotentRequest")
+	defer func() { tracing.End(span, err) }()
+	r.ExpiresAt = time.Now().Add(s.Config.IdempotencyTTL)
+	return s.Repo.CompleteIdempotencyKey(ctx, r)
+}
+
+// ReleaseIdempotentRequest frees a claimed key whose request failed
+// so a retry runs it again
+func (s Service) ReleaseIdempotentRequest(ctx context.Context, r core.IdempotentRequest) (err error) {
+	ctx, span := startSpan(ctx, "ReleaseIdempotentRequest")
+	defer func() { tracing.End(span, err) }()
+	return s.Repo.ReleaseIdempotencyKey(ctx, r.Client, r.Key)
+}
+
+// PurgeIdempotentRequests removes the expired keys
+func (s Service) PurgeIdempotentRequests(ctx context.Context) (n int, err error) {
+	ctx, span := startSpan(ctx, "PurgeIdempotentRequests")
+	defer func() { tracing.End(span, err) }()
+	return s.Repo.PurgeIdempotencyKeys(ctx, time.Now())
+}
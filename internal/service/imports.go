@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/tracing"
+)
+
+// ImportRates proposes a new rate window per row in a single
+// transaction. Rows are checked like ProposeRate and against the
+// previous rows of the same pair. Strict imports propose nothing when
+// a row is invalid, otherwise only the valid rows are proposed. Dry
+// runs only report the checks
+func (s Service) ImportRates(ctx context.Context, rows []core.RateImportRow, opts core.ImportOptions, actor string) (r core.ImportReport, err error) {
+	ctx, span := startSpan(ctx, "ImportRates")
+	defer func() { tracing.End(span, err) }()
+	if actor == "" {
+		return r, core.ErrActorRequired
+	}
+	if len(rows) == 0 {
+		return r, core.ErrEmptyImport
+	}
+	if len(rows) > s.Config.ImportMaxRows {
+		return r, core.ErrImportTooLarge
+	}
+	// the provider is slow compared to the checks, asking it inside the
+	// transaction would hold its connection and locks for every pair
+	markets := s.marketRates(ctx, rows)
+	err = s.Repo.RunInTx(ctx, func(ctx context.Context) (err error) {
+		r = core.ImportReport{DryRun: opts.DryRun, Strict: opts.Strict, Rows: make([]core.ImportRowReport, 0, len(rows))}
+		accepted := make([]core.RateImportRow, 0, len(rows))
+		now := time.Now()
+		for _, row := range rows {
+			report := core.ImportRowReport{Line: row.Line, From: row.Rate.From, To: row.Rate.To, Status: core.ImportValid}
+			row.Rate, err = s.checkImportRow(ctx, row, now, accepted, markets)
+			var ce *core.Error
+			switch {
+			case err == nil:
+				accepted = append(accepted, row)
+				r.Valid++
+			case errors.As(err, &ce):
+				report.Status, report.Code, report.Error = core.ImportInvalid, ce.Code, err.Error()
+				r.Invalid++
+			default:
+				return err
+			}
+			r.Rows = append(r.Rows, report)
+		}
+		if opts.DryRun || (opts.Strict && r.Invalid > 0) {
+			return nil
+		}
+
+		for i, report := range r.Rows {
+			if report.Status != core.ImportValid {
+				continue
+			}
+			row := accepted[0]
+			accepted = accepted[1:]
+			p, err := s.Repo.CreateRateProposal(ctx, core.RateProposal{
+				Action:     core.RateActionCreate,
+				Rate:       row.Rate,
+				Override:   row.Override,
+				Status:     core.ProposalPending,
+				ProposedBy: actor,
+			})
+			if err != nil {
+				return err
+			}
+			if err = s.Repo.CreateRateAudit(ctx, p.Audit(core.AuditProposed, actor, "")); err != nil {
+				return err
+			}
+			r.Rows[i].Status, r.Rows[i].ProposalID = core.ImportProposed, p.ID
+			r.Proposed++
+		}
+		return nil
+	})
+	if err != nil {
+		return core.ImportReport{}, err
+	}
+	return r, nil
+}
+
+// market is the market rate of a pair, resolved once per pair before
+// the rows of an import are checked
+type market struct {
+	rate float64
+	err  error
+}
+
+// marketRates resolves the market rate of every pair with a row that
+// is checked against it
+func (s Service) marketRates(ctx context.Context, rows []core.RateImportRow) map[string]market {
+	markets := map[string]market{}
+	for _, row := range rows {
+		if row.Err != nil || row.Override.Force {
+			continue
+		}
+		pair := core.Pair(row.Rate.From, row.Rate.To)
+		if _, ok := markets[pair]; ok {
+			continue
+		}
+		var m market
+		m.rate, m.err = s.marketRate(ctx, row.Rate.From, row.Rate.To)
+		markets[pair] = m
+	}
+	return markets
+}
+
+// checkImportRow checks rows without valid_from as starting at now,
+// they are proposed without it like in ProposeRate
+func (s Service) checkImportRow(ctx context.Context, row core.RateImportRow, now time.Time, accepted []core.RateImportRow, markets map[string]market) (core.CurrencyRate, error) {
+	if row.Err != nil {
+		return row.Rate, row.Err
+	}
+	rate, err := s.resolveRate(ctx, core.RateActionCreate, row.Rate)
+	if err != nil {
+		return rate, err
+	}
+	check := startsAt(core.RateActionCreate, rate, now)
+	if err = s.checkWindow(ctx, core.RateActionCreate, check, row.Override, now); err != nil {
+		return rate, err
+	}
+	for _, a := range accepted {
+		if check.Overlaps(startsAt(core.RateActionCreate, a.Rate, now)) {
+			return rate, core.ErrRateOverlap
+		}
+	}
+	if row.Override.Force {
+		return rate, nil
+	}
+	m, ok := markets[core.Pair(rate.From, rate.To)]
+	if !ok {
+		return rate, core.ErrMarketRateUnavailable
+	}
+	if m.err != nil {
+		return rate, m.err
+	}
+	return rate, s.deviation(rate, m.rate)
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/tracing"
+)
+
+// keyPrefix identifies bravo keys in secret scanners and logs
+const keyPrefix = "bravo"
+
+// CreateAPIKey creates a key with the given scopes, the secret is
+// returned only once, only its hash is stored
+func (s Service) CreateAPIKey(ctx context.Context, name string, scopes []core.Scope) (key core.APIKey, secret string, err error) {
+	ctx, span := startSpan(ctx, "CreateAPIKey")
+	defer func() { tracing.End(span, err) }()
+	key = core.APIKey{Name: name, Scopes: scopes}
+	if err = key.Check(); err != nil {
+		return
+	}
+	key.Prefix, secret, err = generateKey()
+	if err != nil {
+		return
+	}
+	key, err = s.Repo.CreateAPIKey(ctx, key, hashKey(secret))
+	return
+}
+
+func (s Service) GetAPIKeys(ctx context.Context) (keys core.APIKeys, err error) {
+	ctx, span := startSpan(ctx, "GetAPIKeys")
+	defer func() { tracing.End(span, err) }()
+	return s.Repo.GetAPIKeys(ctx)
+}
+
+// RotateAPIKey replaces the secret of a key keeping its id and scopes
+func (s Service) RotateAPIKey(ctx context.Context, id string) (key core.APIKey, secret string, err error) {
+	ctx, span := startSpan(ctx, "RotateAPIKey")
+	defer func() { tracing.End(span, err) }()
+	if !core.ValidKeyID(id) {
+		return core.APIKey{}, "", core.ErrKeyNotFound
+	}
+	prefix, secret, err := generateKey()
+	if err != nil {
+		return
+	}
+	key, err = s.Repo.RotateAPIKey(ctx, id, prefix, hashKey(secret))
+	if errors.Is(err, core.ErrNotFound) {
+		err = core.ErrKeyNotFound
+	}
+	return
+}
+
+func (s Service) RevokeAPIKey(ctx context.Context, id string) (err error) {
+	ctx, span := startSpan(ctx, "RevokeAPIKey")
+	defer func() { tracing.End(span, err) }()
+	if !core.ValidKeyID(id) {
+		return core.ErrKeyNotFound
+	}
+	err = s.Repo.RevokeAPIKey(ctx, id)
+	if errors.Is(err, core.ErrNotFound) {
+		return core.ErrKeyNotFound
+	}
+	return err
+}
+
+// AuthenticateKey resolves the principal of an active key secret
+func (s Service) AuthenticateKey(ctx context.Context, secret string) (p core.Principal, err error) {
+	ctx, span := startSpan(ctx, "AuthenticateKey")
+	defer func() { tracing.End(span, err) }()
+	if secret == "" {
+		return core.Principal{}, core.ErrUnauthenticated
+	}
+	key, err := s.Repo.GetAPIKeyByHash(ctx, hashKey(secret))
+	if errors.Is(err, core.ErrNotFound) {
+		return core.Principal{}, core.ErrUnauthenticated
+	}
+	if err != nil {
+		return core.Principal{}, err
+	}
+	return core.Principal{
+		ID:     key.ID,
+		Kind:   core.PrincipalAPIKey,
+		Scopes: key.Scopes,
+	}, nil
+}
+
+func generateKey() (prefix, secret string, err error) {
+	p := make([]byte, 4)
+	if _, err = rand.Read(p); err != nil {
+		return
+	}
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return
+	}
+	prefix = hex.EncodeToString(p)
+	secret = fmt.Sprintf("%s_%s_%s", keyPrefix, prefix, base64.RawURLEncoding.EncodeToString(b))
+	return
+}
+
+// hashKey uses sha256 since secrets are random 256 bit values,
+// a slow hash would only add latency to every request
+func hashKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -7,8 +7,10 @@ package mock_service
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	core "github.com/arxdsilva/bravo/internal/core"
+	stream "github.com/arxdsilva/bravo/internal/stream"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -49,6 +51,65 @@ func (mr *MockResolverMockRecorder) AddCurrency(ctx, symbol, description interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCurrency", reflect.TypeOf((*MockResolver)(nil).AddCurrency), ctx, symbol, description)
 }
 
+// ApproveRateProposal mocks base method.
+func (m *MockResolver) ApproveRateProposal(ctx context.Context, id, actor string) (core.RateProposal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveRateProposal", ctx, id, actor)
+	ret0, _ := ret[0].(core.RateProposal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveRateProposal indicates an expected call of ApproveRateProposal.
+func (mr *MockResolverMockRecorder) ApproveRateProposal(ctx, id, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveRateProposal", reflect.TypeOf((*MockResolver)(nil).ApproveRateProposal), ctx, id, actor)
+}
+
+// AuthenticateKey mocks base method.
+func (m *MockResolver) AuthenticateKey(ctx context.Context, secret string) (core.Principal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateKey", ctx, secret)
+	ret0, _ := ret[0].(core.Principal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateKey indicates an expected call of AuthenticateKey.
+func (mr *MockResolverMockRecorder) AuthenticateKey(ctx, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateKey", reflect.TypeOf((*MockResolver)(nil).AuthenticateKey), ctx, secret)
+}
+
+// BeginIdempotentRequest mocks base method.
+func (m *MockResolver) BeginIdempotentRequest(ctx context.Context, r core.IdempotentRequest) (core.IdempotentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginIdempotentRequest", ctx, r)
+	ret0, _ := ret[0].(core.IdempotentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginIdempotentRequest indicates an expected call of BeginIdempotentRequest.
+func (mr *MockResolverMockRecorder) BeginIdempotentRequest(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginIdempotentRequest", reflect.TypeOf((*MockResolver)(nil).BeginIdempotentRequest), ctx, r)
+}
+
+// CompleteIdempotentRequest mocks base method.
+func (m *MockResolver) CompleteIdempotentRequest(ctx context.Context, r core.IdempotentRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotentRequest", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotentRequest indicates an expected call of CompleteIdempotentRequest.
+func (mr *MockResolverMockRecorder) CompleteIdempotentRequest(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotentRequest", reflect.TypeOf((*MockResolver)(nil).CompleteIdempotentRequest), ctx, r)
+}
+
 // Convert mocks base method.
 func (m *MockResolver) Convert(ctx context.Context, conv core.ConversionSVC) (float64, string, error) {
 	m.ctrl.T.Helper()
@@ -65,33 +126,138 @@ func (mr *MockResolverMockRecorder) Convert(ctx, conv interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Convert", reflect.TypeOf((*MockResolver)(nil).Convert), ctx, conv)
 }
 
-// CreateRate mocks base method.
-func (m *MockResolver) CreateRate(ctx context.Context, from, to string, rate float64) error {
+// ConvertMany mocks base method.
+func (m *MockResolver) ConvertMany(ctx context.Context, convs []core.ConversionSVC) ([]core.ConversionResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConvertMany", ctx, convs)
+	ret0, _ := ret[0].([]core.ConversionResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConvertMany indicates an expected call of ConvertMany.
+func (mr *MockResolverMockRecorder) ConvertMany(ctx, convs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConvertMany", reflect.TypeOf((*MockResolver)(nil).ConvertMany), ctx, convs)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockResolver) CreateAPIKey(ctx context.Context, name string, scopes []core.Scope) (core.APIKey, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, name, scopes)
+	ret0, _ := ret[0].(core.APIKey)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockResolverMockRecorder) CreateAPIKey(ctx, name, scopes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockResolver)(nil).CreateAPIKey), ctx, name, scopes)
+}
+
+// CreateAlertSubscription mocks base method.
+func (m *MockResolver) CreateAlertSubscription(ctx context.Context, a core.AlertSubscription) (core.AlertSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAlertSubscription", ctx, a)
+	ret0, _ := ret[0].(core.AlertSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAlertSubscription indicates an expected call of CreateAlertSubscription.
+func (mr *MockResolverMockRecorder) CreateAlertSubscription(ctx, a interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAlertSubscription", reflect.TypeOf((*MockResolver)(nil).CreateAlertSubscription), ctx, a)
+}
+
+// ExportConversions mocks base method.
+func (m *MockResolver) ExportConversions(ctx context.Context, since, until time.Time, fn func(core.Conversion) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportConversions", ctx, since, until, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportConversions indicates an expected call of ExportConversions.
+func (mr *MockResolverMockRecorder) ExportConversions(ctx, since, until, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportConversions", reflect.TypeOf((*MockResolver)(nil).ExportConversions), ctx, since, until, fn)
+}
+
+// ExportRates mocks base method.
+func (m *MockResolver) ExportRates(ctx context.Context, at time.Time, fn func(core.CurrencyRate) error) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateRate", ctx, from, to, rate)
+	ret := m.ctrl.Call(m, "ExportRates", ctx, at, fn)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// CreateRate indicates an expected call of CreateRate.
-func (mr *MockResolverMockRecorder) CreateRate(ctx, from, to, rate interface{}) *gomock.Call {
+// ExportRates indicates an expected call of ExportRates.
+func (mr *MockResolverMockRecorder) ExportRates(ctx, at, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportRates", reflect.TypeOf((*MockResolver)(nil).ExportRates), ctx, at, fn)
+}
+
+// GetAPIKeys mocks base method.
+func (m *MockResolver) GetAPIKeys(ctx context.Context) (core.APIKeys, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeys", ctx)
+	ret0, _ := ret[0].(core.APIKeys)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeys indicates an expected call of GetAPIKeys.
+func (mr *MockResolverMockRecorder) GetAPIKeys(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeys", reflect.TypeOf((*MockResolver)(nil).GetAPIKeys), ctx)
+}
+
+// GetAlertSubscription mocks base method.
+func (m *MockResolver) GetAlertSubscription(ctx context.Context, id string) (core.AlertSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlertSubscription", ctx, id)
+	ret0, _ := ret[0].(core.AlertSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlertSubscription indicates an expected call of GetAlertSubscription.
+func (mr *MockResolverMockRecorder) GetAlertSubscription(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlertSubscription", reflect.TypeOf((*MockResolver)(nil).GetAlertSubscription), ctx, id)
+}
+
+// GetAlertSubscriptions mocks base method.
+func (m *MockResolver) GetAlertSubscriptions(ctx context.Context) (core.AlertSubscriptions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlertSubscriptions", ctx)
+	ret0, _ := ret[0].(core.AlertSubscriptions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlertSubscriptions indicates an expected call of GetAlertSubscriptions.
+func (mr *MockResolverMockRecorder) GetAlertSubscriptions(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRate", reflect.TypeOf((*MockResolver)(nil).CreateRate), ctx, from, to, rate)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlertSubscriptions", reflect.TypeOf((*MockResolver)(nil).GetAlertSubscriptions), ctx)
 }
 
 // GetCurrencies mocks base method.
-func (m *MockResolver) GetCurrencies(ctx context.Context) (core.Currencies, error) {
+func (m *MockResolver) GetCurrencies(ctx context.Context, f core.CurrencyFilter) (core.CurrencyPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCurrencies", ctx)
-	ret0, _ := ret[0].(core.Currencies)
+	ret := m.ctrl.Call(m, "GetCurrencies", ctx, f)
+	ret0, _ := ret[0].(core.CurrencyPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCurrencies indicates an expected call of GetCurrencies.
-func (mr *MockResolverMockRecorder) GetCurrencies(ctx interface{}) *gomock.Call {
+func (mr *MockResolverMockRecorder) GetCurrencies(ctx, f interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrencies", reflect.TypeOf((*MockResolver)(nil).GetCurrencies), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrencies", reflect.TypeOf((*MockResolver)(nil).GetCurrencies), ctx, f)
 }
 
 // GetCurrency mocks base method.
@@ -109,19 +275,182 @@ func (mr *MockResolverMockRecorder) GetCurrency(ctx, symbol interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrency", reflect.TypeOf((*MockResolver)(nil).GetCurrency), ctx, symbol)
 }
 
+// GetProviderBudget mocks base method.
+func (m *MockResolver) GetProviderBudget(ctx context.Context) (core.ProviderBudget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProviderBudget", ctx)
+	ret0, _ := ret[0].(core.ProviderBudget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProviderBudget indicates an expected call of GetProviderBudget.
+func (mr *MockResolverMockRecorder) GetProviderBudget(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProviderBudget", reflect.TypeOf((*MockResolver)(nil).GetProviderBudget), ctx)
+}
+
+// GetRateProposal mocks base method.
+func (m *MockResolver) GetRateProposal(ctx context.Context, id string) (core.RateProposal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRateProposal", ctx, id)
+	ret0, _ := ret[0].(core.RateProposal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRateProposal indicates an expected call of GetRateProposal.
+func (mr *MockResolverMockRecorder) GetRateProposal(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRateProposal", reflect.TypeOf((*MockResolver)(nil).GetRateProposal), ctx, id)
+}
+
+// GetRateProposals mocks base method.
+func (m *MockResolver) GetRateProposals(ctx context.Context, status core.ProposalStatus) (core.RateProposals, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRateProposals", ctx, status)
+	ret0, _ := ret[0].(core.RateProposals)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRateProposals indicates an expected call of GetRateProposals.
+func (mr *MockResolverMockRecorder) GetRateProposals(ctx, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRateProposals", reflect.TypeOf((*MockResolver)(nil).GetRateProposals), ctx, status)
+}
+
 // GetRates mocks base method.
-func (m *MockResolver) GetRates(ctx context.Context) (interface{}, error) {
+func (m *MockResolver) GetRates(ctx context.Context, at time.Time) (core.CurrencyRates, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRates", ctx)
-	ret0, _ := ret[0].(interface{})
+	ret := m.ctrl.Call(m, "GetRates", ctx, at)
+	ret0, _ := ret[0].(core.CurrencyRates)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetRates indicates an expected call of GetRates.
-func (mr *MockResolverMockRecorder) GetRates(ctx interface{}) *gomock.Call {
+func (mr *MockResolverMockRecorder) GetRates(ctx, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRates", reflect.TypeOf((*MockResolver)(nil).GetRates), ctx, at)
+}
+
+// GetUsage mocks base method.
+func (m *MockResolver) GetUsage(ctx context.Context, period time.Time) (core.Usages, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsage", ctx, period)
+	ret0, _ := ret[0].(core.Usages)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsage indicates an expected call of GetUsage.
+func (mr *MockResolverMockRecorder) GetUsage(ctx, period interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsage", reflect.TypeOf((*MockResolver)(nil).GetUsage), ctx, period)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockResolver) GetWebhookDeliveries(ctx context.Context, subscriptionID string) (core.WebhookDeliveries, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", ctx, subscriptionID)
+	ret0, _ := ret[0].(core.WebhookDeliveries)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockResolverMockRecorder) GetWebhookDeliveries(ctx, subscriptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockResolver)(nil).GetWebhookDeliveries), ctx, subscriptionID)
+}
+
+// ImportRates mocks base method.
+func (m *MockResolver) ImportRates(ctx context.Context, rows []core.RateImportRow, opts core.ImportOptions, actor string) (core.ImportReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportRates", ctx, rows, opts, actor)
+	ret0, _ := ret[0].(core.ImportReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportRates indicates an expected call of ImportRates.
+func (mr *MockResolverMockRecorder) ImportRates(ctx, rows, opts, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportRates", reflect.TypeOf((*MockResolver)(nil).ImportRates), ctx, rows, opts, actor)
+}
+
+// IncrementUsage mocks base method.
+func (m *MockResolver) IncrementUsage(ctx context.Context, client string, n int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementUsage", ctx, client, n)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementUsage indicates an expected call of IncrementUsage.
+func (mr *MockResolverMockRecorder) IncrementUsage(ctx, client, n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementUsage", reflect.TypeOf((*MockResolver)(nil).IncrementUsage), ctx, client, n)
+}
+
+// ProposeRate mocks base method.
+func (m *MockResolver) ProposeRate(ctx context.Context, action core.RateAction, rate core.CurrencyRate, override core.Override, version, actor string) (core.RateProposal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProposeRate", ctx, action, rate, override, version, actor)
+	ret0, _ := ret[0].(core.RateProposal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProposeRate indicates an expected call of ProposeRate.
+func (mr *MockResolverMockRecorder) ProposeRate(ctx, action, rate, override, version, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProposeRate", reflect.TypeOf((*MockResolver)(nil).ProposeRate), ctx, action, rate, override, version, actor)
+}
+
+// RejectRateProposal mocks base method.
+func (m *MockResolver) RejectRateProposal(ctx context.Context, id, actor, reason string) (core.RateProposal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectRateProposal", ctx, id, actor, reason)
+	ret0, _ := ret[0].(core.RateProposal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectRateProposal indicates an expected call of RejectRateProposal.
+func (mr *MockResolverMockRecorder) RejectRateProposal(ctx, id, actor, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectRateProposal", reflect.TypeOf((*MockResolver)(nil).RejectRateProposal), ctx, id, actor, reason)
+}
+
+// ReleaseIdempotentRequest mocks base method.
+func (m *MockResolver) ReleaseIdempotentRequest(ctx context.Context, r core.IdempotentRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseIdempotentRequest", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseIdempotentRequest indicates an expected call of ReleaseIdempotentRequest.
+func (mr *MockResolverMockRecorder) ReleaseIdempotentRequest(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseIdempotentRequest", reflect.TypeOf((*MockResolver)(nil).ReleaseIdempotentRequest), ctx, r)
+}
+
+// RemoveAlertSubscription mocks base method.
+func (m *MockResolver) RemoveAlertSubscription(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAlertSubscription", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveAlertSubscription indicates an expected call of RemoveAlertSubscription.
+func (mr *MockResolverMockRecorder) RemoveAlertSubscription(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRates", reflect.TypeOf((*MockResolver)(nil).GetRates), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAlertSubscription", reflect.TypeOf((*MockResolver)(nil).RemoveAlertSubscription), ctx, id)
 }
 
 // RemoveCurrency mocks base method.
@@ -138,46 +467,89 @@ func (mr *MockResolverMockRecorder) RemoveCurrency(ctx, symbol interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCurrency", reflect.TypeOf((*MockResolver)(nil).RemoveCurrency), ctx, symbol)
 }
 
-// RemoveRate mocks base method.
-func (m *MockResolver) RemoveRate(ctx context.Context, from, to string) error {
+// RevokeAPIKey mocks base method.
+func (m *MockResolver) RevokeAPIKey(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveRate", ctx, from, to)
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// RemoveRate indicates an expected call of RemoveRate.
-func (mr *MockResolverMockRecorder) RemoveRate(ctx, from, to interface{}) *gomock.Call {
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockResolverMockRecorder) RevokeAPIKey(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRate", reflect.TypeOf((*MockResolver)(nil).RemoveRate), ctx, from, to)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockResolver)(nil).RevokeAPIKey), ctx, id)
 }
 
-// UpdateCurrency mocks base method.
-func (m *MockResolver) UpdateCurrency(ctx context.Context, symbol, description string) error {
+// RotateAPIKey mocks base method.
+func (m *MockResolver) RotateAPIKey(ctx context.Context, id string) (core.APIKey, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateCurrency", ctx, symbol, description)
-	ret0, _ := ret[0].(error)
+	ret := m.ctrl.Call(m, "RotateAPIKey", ctx, id)
+	ret0, _ := ret[0].(core.APIKey)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RotateAPIKey indicates an expected call of RotateAPIKey.
+func (mr *MockResolverMockRecorder) RotateAPIKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateAPIKey", reflect.TypeOf((*MockResolver)(nil).RotateAPIKey), ctx, id)
+}
+
+// SubscribeRates mocks base method.
+func (m *MockResolver) SubscribeRates(pairs []string, lastEventID uint64) *stream.Subscription {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeRates", pairs, lastEventID)
+	ret0, _ := ret[0].(*stream.Subscription)
 	return ret0
 }
 
-// UpdateCurrency indicates an expected call of UpdateCurrency.
-func (mr *MockResolverMockRecorder) UpdateCurrency(ctx, symbol, description interface{}) *gomock.Call {
+// SubscribeRates indicates an expected call of SubscribeRates.
+func (mr *MockResolverMockRecorder) SubscribeRates(pairs, lastEventID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeRates", reflect.TypeOf((*MockResolver)(nil).SubscribeRates), pairs, lastEventID)
+}
+
+// UnsubscribeRates mocks base method.
+func (m *MockResolver) UnsubscribeRates(sub *stream.Subscription) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UnsubscribeRates", sub)
+}
+
+// UnsubscribeRates indicates an expected call of UnsubscribeRates.
+func (mr *MockResolverMockRecorder) UnsubscribeRates(sub interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsubscribeRates", reflect.TypeOf((*MockResolver)(nil).UnsubscribeRates), sub)
+}
+
+// UpdateAlertSubscription mocks base method.
+func (m *MockResolver) UpdateAlertSubscription(ctx context.Context, a core.AlertSubscription) (core.AlertSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAlertSubscription", ctx, a)
+	ret0, _ := ret[0].(core.AlertSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAlertSubscription indicates an expected call of UpdateAlertSubscription.
+func (mr *MockResolverMockRecorder) UpdateAlertSubscription(ctx, a interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCurrency", reflect.TypeOf((*MockResolver)(nil).UpdateCurrency), ctx, symbol, description)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAlertSubscription", reflect.TypeOf((*MockResolver)(nil).UpdateAlertSubscription), ctx, a)
 }
 
-// UpdateRate mocks base method.
-func (m *MockResolver) UpdateRate(ctx context.Context, from, to string, rate float64) error {
+// UpdateCurrency mocks base method.
+func (m *MockResolver) UpdateCurrency(ctx context.Context, symbol, description string, version time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateRate", ctx, from, to, rate)
+	ret := m.ctrl.Call(m, "UpdateCurrency", ctx, symbol, description, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateRate indicates an expected call of UpdateRate.
-func (mr *MockResolverMockRecorder) UpdateRate(ctx, from, to, rate interface{}) *gomock.Call {
+// UpdateCurrency indicates an expected call of UpdateCurrency.
+func (mr *MockResolverMockRecorder) UpdateCurrency(ctx, symbol, description, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRate", reflect.TypeOf((*MockResolver)(nil).UpdateRate), ctx, from, to, rate)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCurrency", reflect.TypeOf((*MockResolver)(nil).UpdateCurrency), ctx, symbol, description, version)
 }
 
 // MockExchanger is a mock of Exchanger interface.
@@ -203,6 +575,21 @@ func (m *MockExchanger) EXPECT() *MockExchangerMockRecorder {
 	return m.recorder
 }
 
+// Budget mocks base method.
+func (m *MockExchanger) Budget(ctx context.Context) (core.ProviderBudget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Budget", ctx)
+	ret0, _ := ret[0].(core.ProviderBudget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Budget indicates an expected call of Budget.
+func (mr *MockExchangerMockRecorder) Budget(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Budget", reflect.TypeOf((*MockExchanger)(nil).Budget), ctx)
+}
+
 // Exchange mocks base method.
 func (m *MockExchanger) Exchange(ctx context.Context, from, to string, amount float64) (core.ConversionResp, error) {
 	m.ctrl.T.Helper()
@@ -219,10 +606,10 @@ func (mr *MockExchangerMockRecorder) Exchange(ctx, from, to, amount interface{})
 }
 
 // GetCurrencies mocks base method.
-func (m *MockExchanger) GetCurrencies(ctx context.Context) (map[string]string, error) {
+func (m *MockExchanger) GetCurrencies(ctx context.Context) (core.Currencies, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetCurrencies", ctx)
-	ret0, _ := ret[0].(map[string]string)
+	ret0, _ := ret[0].(core.Currencies)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -232,3 +619,78 @@ func (mr *MockExchangerMockRecorder) GetCurrencies(ctx interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrencies", reflect.TypeOf((*MockExchanger)(nil).GetCurrencies), ctx)
 }
+
+// MockSink is a mock of Sink interface.
+type MockSink struct {
+	ctrl     *gomock.Controller
+	recorder *MockSinkMockRecorder
+}
+
+// MockSinkMockRecorder is the mock recorder for MockSink.
+type MockSinkMockRecorder struct {
+	mock *MockSink
+}
+
+// NewMockSink creates a new mock instance.
+func NewMockSink(ctrl *gomock.Controller) *MockSink {
+	mock := &MockSink{ctrl: ctrl}
+	mock.recorder = &MockSinkMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSink) EXPECT() *MockSinkMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockSink) Publish(ctx context.Context, events core.OutboxEvents) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockSinkMockRecorder) Publish(ctx, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockSink)(nil).Publish), ctx, events)
+}
+
+// MockNotifier is a mock of Notifier interface.
+type MockNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotifierMockRecorder
+}
+
+// MockNotifierMockRecorder is the mock recorder for MockNotifier.
+type MockNotifierMockRecorder struct {
+	mock *MockNotifier
+}
+
+// NewMockNotifier creates a new mock instance.
+func NewMockNotifier(ctrl *gomock.Controller) *MockNotifier {
+	mock := &MockNotifier{ctrl: ctrl}
+	mock.recorder = &MockNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
+	return m.recorder
+}
+
+// Deliver mocks base method.
+func (m *MockNotifier) Deliver(ctx context.Context, sub core.AlertSubscription, d core.WebhookDelivery) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deliver", ctx, sub, d)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deliver indicates an expected call of Deliver.
+func (mr *MockNotifierMockRecorder) Deliver(ctx, sub, d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deliver", reflect.TypeOf((*MockNotifier)(nil).Deliver), ctx, sub, d)
+}
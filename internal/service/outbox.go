@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/metrics"
+	"github.com/arxdsilva/bravo/internal/tracing"
+	log "github.com/sirupsen/logrus"
+)
+
+// outboxPurgeInterval is how often the published events are removed
+const outboxPurgeInterval = time.Hour
+
+// record adds a domain event to the outbox, ctx has to carry the
+// transaction of the change it announces
+func (s Service) record(ctx context.Context, typ core.EventType, key string, data interface{}) error {
+	e, err := core.NewOutboxEvent(typ, key, data)
+	if err != nil {
+		return err
+	}
+	return s.Repo.CreateOutboxEvent(ctx, e)
+}
+
+// recordConversions stores a batch of conversions in the history and
+// adds their conversion.completed events, each with one insert.
+// Converting is a read for the client, so failing to record it is
+// logged instead of failing the conversion
+func (s Service) recordConversions(ctx context.Context, convs ...core.ConversionEvent) {
+	lg := log.WithFields(log.Fields{"pkg": "service", "fn": "recordConversions", "conversions": len(convs)})
+	es := make(core.OutboxEvents, 0, len(convs))
+	for _, c := range convs {
+		e, err := core.NewOutboxEvent(core.EventConversionCompleted, core.Pair(c.From, c.To), c)
+		if err != nil {
+			lg.WithError(err).Error("core.NewOutboxEvent")
+			return
+		}
+		es = append(es, e)
+	}
+	err := s.Repo.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.Repo.CreateConversions(ctx, convs); err != nil {
+			return err
+		}
+		return s.Repo.CreateOutboxEvents(ctx, es)
+	})
+	if err != nil {
+		lg.WithError(err).Error("Repo.RunInTx")
+	}
+}
+
+func conversionEvent(conv core.ConversionSVC, at time.Time, amount float64, source string) core.ConversionEvent {
+	return core.ConversionEvent{ConversionResp: core.TransformSVCToResp(conv, amount, source), At: at}
+}
+
+// RelayOutbox publishes the outbox events to sink until ctx is done,
+// events are only marked as published after the sink accepted them
+// so they are delivered at least once
+func (s Service) RelayOutbox(ctx context.Context, sink Sink, name string) {
+	lg := log.WithFields(log.Fields{"pkg": "service", "fn": "RelayOutbox", "sink": name})
+	tick := time.NewTicker(s.Config.OutboxInterval)
+	defer tick.Stop()
+	purge := time.NewTicker(outboxPurgeInterval)
+	defer purge.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-purge.C:
+			n, err := s.Repo.PurgeOutboxEvents(ctx, time.Now().Add(-s.Config.OutboxRetention))
+			if err != nil {
+				lg.WithError(err).Error("repo.PurgeOutboxEvents")
+				continue
+			}
+			lg.WithField("purged", n).Info("outbox events purged")
+		case <-tick.C:
+			// drain the backlog before waiting for the next tick
+			for {
+				n, err := s.relayOutbox(ctx, sink)
+				if err != nil {
+					metrics.OutboxErrors.WithLabelValues(name).Inc()
+					lg.WithError(err).Error("relayOutbox")
+					break
+				}
+				metrics.OutboxPublished.WithLabelValues(name).Add(float64(n))
+				if n < s.Config.OutboxBatch || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// relayOutbox publishes a batch of events, the events stay locked
+// while the sink is called so concurrent relays skip them
+func (s Service) relayOutbox(ctx context.Context, sink Sink) (n int, err error) {
+	ctx, span := startSpan(ctx, "relayOutbox")
+	defer func() { tracing.End(span, err) }()
+	err = s.Repo.RunInTx(ctx, func(ctx context.Context) error {
+		events, err := s.Repo.ClaimOutboxEvents(ctx, s.Config.OutboxBatch)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		if err = sink.Publish(ctx, events); err != nil {
+			return err
+		}
+		ids := make([]string, 0, len(events))
+		for _, e := range events {
+			ids = append(ids, e.ID)
+		}
+		n = len(events)
+		return s.Repo.MarkOutboxEventsPublished(ctx, ids, time.Now())
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
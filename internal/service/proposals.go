@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/tracing"
+)
+
+// ProposeRate registers a manual rate change that only takes
+// effect after a different identity approves it. version is the
+// schedule the change is made over, empty to approve it over any
+func (s Service) ProposeRate(ctx context.Context, action core.RateAction, rate core.CurrencyRate, override core.Override, version, actor string) (p core.RateProposal, err error) {
+	ctx, span := startSpan(ctx, "ProposeRate")
+	defer func() { tracing.End(span, err) }()
+	if actor == "" {
+		return p, core.ErrActorRequired
+	}
+	switch action {
+	case core.RateActionCreate, core.RateActionUpdate, core.RateActionDelete:
+	default:
+		return p, core.ErrInvalidRateAction
+	}
+	rate, err = s.resolveRate(ctx, action, rate)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	if err = s.checkRate(ctx, action, startsAt(action, rate, now), override, now); err != nil {
+		return
+	}
+	err = s.Repo.RunInTx(ctx, func(ctx context.Context) (err error) {
+		p, err = s.Repo.CreateRateProposal(ctx, core.RateProposal{
+			Action:       action,
+			Rate:         rate,
+			Override:     override,
+			Status:       core.ProposalPending,
+			ProposedBy:   actor,
+			RatesVersion: version,
+		})
+		if err != nil {
+			return
+		}
+		return s.Repo.CreateRateAudit(ctx, p.Audit(core.AuditProposed, actor, ""))
+	})
+	return
+}
+
+// GetRateProposals lists proposals, filtered by status when not empty
+func (s Service) GetRateProposals(ctx context.Context, status core.ProposalStatus) (ps core.RateProposals, err error) {
+	ctx, span := startSpan(ctx, "GetRateProposals")
+	defer func() { tracing.End(span, err) }()
+	return s.Repo.GetRateProposals(ctx, status)
+}
+
+func (s Service) GetRateProposal(ctx context.Context, id string) (p core.RateProposal, err error) {
+	ctx, span := startSpan(ctx, "GetRateProposal")
+	defer func() { tracing.End(span, err) }()
+	p, err = s.Repo.GetRateProposal(ctx, id)
+	if errors.Is(err, core.ErrNotFound) {
+		return p, core.ErrProposalNotFound
+	}
+	return
+}
+
+// ApproveRateProposal applies the proposed change to the rates
+// table, the proposal decision and its audit are stored atomically.
+// New windows without valid_from start now, the ones whose valid_from
+// has passed in the meantime can no longer close the current window.
+// The market rate of the deviation check is fetched before the
+// proposal is locked, so a slow provider does not hold the lock
+func (s Service) ApproveRateProposal(ctx context.Context, id, actor string) (p core.RateProposal, err error) {
+	ctx, span := startSpan(ctx, "ApproveRateProposal")
+	defer func() { tracing.End(span, err) }()
+	p, err = s.GetRateProposal(ctx, id)
+	if err != nil {
+		return
+	}
+	if err = p.CanBeDecidedBy(actor); err != nil {
+		return
+	}
+	market, err := s.marketFor(ctx, p.Action, p.Rate, p.Override)
+	if err != nil {
+		return
+	}
+	err = s.Repo.RunInTx(ctx, func(ctx context.Context) (err error) {
+		p, err = s.GetRateProposal(ctx, id)
+		if err != nil {
+			return
+		}
+		// another approver may have decided it in the meantime
+		if err = p.CanBeDecidedBy(actor); err != nil {
+			return
+		}
+		if err = s.checkRatesVersion(ctx, p.RatesVersion); err != nil {
+			return
+		}
+		now := time.Now()
+		p.Rate = startsAt(p.Action, p.Rate, now)
+		if err = s.applyRate(ctx, p.Action, p.Rate, p.Override, market, now); err != nil {
+			return
+		}
+		return s.decide(ctx, &p, core.ProposalApproved, actor, "")
+	})
+	if err == nil {
+		s.publishRate(p.Action, manualSource, p.Rate)
+	}
+	return
+}
+
+// RejectRateProposal discards the proposal without touching the rates
+func (s Service) RejectRateProposal(ctx context.Context, id, actor, reason string) (p core.RateProposal, err error) {
+	ctx, span := startSpan(ctx, "RejectRateProposal")
+	defer func() { tracing.End(span, err) }()
+	err = s.Repo.RunInTx(ctx, func(ctx context.Context) (err error) {
+		p, err = s.GetRateProposal(ctx, id)
+		if err != nil {
+			return
+		}
+		if err = p.CanBeDecidedBy(actor); err != nil {
+			return
+		}
+		return s.decide(ctx, &p, core.ProposalRejected, actor, reason)
+	})
+	return
+}
+
+func (s Service) decide(ctx context.Context, p *core.RateProposal, status core.ProposalStatus, actor, reason string) error {
+	now := time.Now()
+	p.Status = status
+	p.DecidedBy = actor
+	p.Reason = reason
+	p.DecidedAt = &now
+	if err := s.Repo.DecideRateProposal(ctx, *p); err != nil {
+		return err
+	}
+	event := core.AuditApproved
+	if status == core.ProposalRejected {
+		event = core.AuditRejected
+	}
+	return s.Repo.CreateRateAudit(ctx, p.Audit(event, actor, reason))
+}
+
+// checkRatesVersion ensures the schedule is still the one the change
+// was proposed over, the rates are locked until ctx's transaction ends
+// so they cannot change before the approval is written
+func (s Service) checkRatesVersion(ctx context.Context, version string) error {
+	if version == "" {
+		return nil
+	}
+	if err := s.Repo.LockRates(ctx); err != nil {
+		return err
+	}
+	rates, err := s.Repo.GetRates(ctx, time.Time{})
+	if err != nil {
+		return err
+	}
+	current, err := rates.Version()
+	if err != nil {
+		return err
+	}
+	if current != version {
+		return core.ErrPreconditionFailed
+	}
+	return nil
+}
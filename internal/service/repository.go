@@ -1,8 +1,63 @@
 package service
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+)
 
 type Repository interface {
-	CreateCurrency(ctx context.Context, symbol, description, source string) error
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+	CreateCurrency(ctx context.Context, c core.Currency) (bool, error)
+	RestoreCurrency(ctx context.Context, c core.Currency) (bool, error)
+	RemoveCurrency(ctx context.Context, symbol string) error
+	GetCurrencies(ctx context.Context, f core.CurrencyFilter) (core.CurrencyPage, error)
 	CountCurrencies(ctx context.Context) (int, error)
+	GetCurrency(ctx context.Context, symbol string) (core.Currency, error)
+	UpdateCurrency(ctx context.Context, symbol, description string, version time.Time) error
+	GetRates(ctx context.Context, at time.Time) (core.CurrencyRates, error)
+	EachRate(ctx context.Context, at time.Time, fn func(core.CurrencyRate) error) error
+	GetRateAt(ctx context.Context, from, to string, at time.Time) (core.CurrencyRate, error)
+	GetOverlappingRates(ctx context.Context, rate core.CurrencyRate) (core.CurrencyRates, error)
+	GetRatesStartingAfter(ctx context.Context, t time.Time) (core.CurrencyRates, error)
+	LockRates(ctx context.Context) error
+	CreateRate(ctx context.Context, rate core.CurrencyRate, source string) error
+	UpdateRate(ctx context.Context, rate core.CurrencyRate) error
+	RemoveRate(ctx context.Context, from, to string, validFrom time.Time) error
+	CreateRateProposal(ctx context.Context, p core.RateProposal) (core.RateProposal, error)
+	GetRateProposals(ctx context.Context, status core.ProposalStatus) (core.RateProposals, error)
+	GetRateProposal(ctx context.Context, id string) (core.RateProposal, error)
+	DecideRateProposal(ctx context.Context, p core.RateProposal) error
+	CreateRateAudit(ctx context.Context, a core.RateAudit) error
+	CreateAPIKey(ctx context.Context, key core.APIKey, hash string) (core.APIKey, error)
+	GetAPIKeys(ctx context.Context) (core.APIKeys, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (core.APIKey, error)
+	RotateAPIKey(ctx context.Context, id, prefix, hash string) (core.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	IncrementUsage(ctx context.Context, client string, period time.Time, n int64) (int64, error)
+	GetUsage(ctx context.Context, period time.Time) (core.Usages, error)
+	ClaimIdempotencyKey(ctx context.Context, r core.IdempotentRequest) (core.IdempotentRequest, bool, error)
+	CompleteIdempotencyKey(ctx context.Context, r core.IdempotentRequest) error
+	ReleaseIdempotencyKey(ctx context.Context, client, key string) error
+	PurgeIdempotencyKeys(ctx context.Context, t time.Time) (int, error)
+	CreateAlertSubscription(ctx context.Context, a core.AlertSubscription) (core.AlertSubscription, error)
+	GetAlertSubscriptions(ctx context.Context) (core.AlertSubscriptions, error)
+	GetPairAlertSubscriptions(ctx context.Context, from, to string) (core.AlertSubscriptions, error)
+	GetAlertSubscription(ctx context.Context, id string) (core.AlertSubscription, error)
+	UpdateAlertSubscription(ctx context.Context, a core.AlertSubscription) (core.AlertSubscription, error)
+	RemoveAlertSubscription(ctx context.Context, id string) error
+	TriggerAlertSubscription(ctx context.Context, id string, at, since time.Time) (bool, error)
+	CreateWebhookDelivery(ctx context.Context, d core.WebhookDelivery) (core.WebhookDelivery, error)
+	ClaimWebhookDelivery(ctx context.Context, id string, attempts int, now, until time.Time) (bool, error)
+	UpdateWebhookDelivery(ctx context.Context, d core.WebhookDelivery) error
+	GetWebhookDeliveries(ctx context.Context, subscriptionID string) (core.WebhookDeliveries, error)
+	GetPendingWebhookDeliveries(ctx context.Context) (core.WebhookDeliveries, error)
+	CreateOutboxEvent(ctx context.Context, e core.OutboxEvent) error
+	CreateOutboxEvents(ctx context.Context, es core.OutboxEvents) error
+	ClaimOutboxEvents(ctx context.Context, limit int) (core.OutboxEvents, error)
+	MarkOutboxEventsPublished(ctx context.Context, ids []string, at time.Time) error
+	PurgeOutboxEvents(ctx context.Context, t time.Time) (int, error)
+	CreateConversions(ctx context.Context, cs []core.ConversionEvent) error
+	EachConversion(ctx context.Context, since, until time.Time, fn func(core.Conversion) error) error
 }
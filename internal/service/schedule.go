@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/tracing"
+	log "github.com/sirupsen/logrus"
+)
+
+// rateSchedule keeps the windows known to start later, they are
+// announced again when they take effect. Windows starting as they
+// are approved are never in it, their approval already announced them
+type rateSchedule struct {
+	mu      sync.Mutex
+	pending map[string]time.Time
+}
+
+func newRateSchedule() *rateSchedule {
+	return &rateSchedule{pending: map[string]time.Time{}}
+}
+
+func scheduleKey(rate core.CurrencyRate) string {
+	return core.Pair(rate.From, rate.To) + "@" + rate.ValidFrom.UTC().Format(time.RFC3339Nano)
+}
+
+func (r *rateSchedule) add(rate core.CurrencyRate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending[scheduleKey(rate)] = rate.ValidFrom
+}
+
+// take reports whether the window was scheduled, removing it
+func (r *rateSchedule) take(rate core.CurrencyRate) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := scheduleKey(rate)
+	_, ok := r.pending[key]
+	delete(r.pending, key)
+	return ok
+}
+
+// prune forgets the windows that started until t without being
+// taken, they were removed or replaced before taking effect
+func (r *rateSchedule) prune(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, from := range r.pending {
+		if !from.After(t) {
+			delete(r.pending, key)
+		}
+	}
+}
+
+// RunScheduledRates announces the rate windows scheduled ahead to the
+// rate stream when they take effect, until ctx is done. Like the
+// stream, each instance announces them to its own subscribers
+func (s Service) RunScheduledRates(ctx context.Context) {
+	lg := log.WithFields(log.Fields{"pkg": "service", "fn": "RunScheduledRates"})
+	tick := time.NewTicker(s.Config.ScheduleInterval)
+	defer tick.Stop()
+	since := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			now := time.Now()
+			if err := s.announceRates(ctx, since, now); err != nil {
+				lg.WithError(err).Error("announceRates")
+				continue
+			}
+			since = now
+		}
+	}
+}
+
+// announceRates publishes the scheduled windows that started after
+// since and until now, and schedules the ones starting later. Windows
+// approved by another instance are scheduled once they are seen here
+func (s Service) announceRates(ctx context.Context, since, now time.Time) (err error) {
+	ctx, span := startSpan(ctx, "announceRates")
+	defer func() { tracing.End(span, err) }()
+	rates, err := s.Repo.GetRatesStartingAfter(ctx, since)
+	if err != nil {
+		return err
+	}
+	for _, r := range rates {
+		if r.ValidFrom.After(now) {
+			s.schedule.add(r)
+			continue
+		}
+		if s.schedule.take(r) {
+			s.publishRate(core.RateActionUpdate, manualSource, r)
+		}
+	}
+	s.schedule.prune(now)
+	return nil
+}
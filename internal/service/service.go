@@ -2,143 +2,473 @@ package service
 
 import (
 	"context"
+	"errors"
+	"sync/atomic"
+	"time"
 
 	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/metrics"
+	"github.com/arxdsilva/bravo/internal/stream"
+	"github.com/arxdsilva/bravo/internal/tracing"
 	log "github.com/sirupsen/logrus"
 )
 
 type Resolver interface {
 	Convert(ctx context.Context, conv core.ConversionSVC) (amount float64, source string, err error)
-	GetCurrencies(ctx context.Context) (core.Currencies, error)
+	ConvertMany(ctx context.Context, convs []core.ConversionSVC) ([]core.ConversionResult, error)
+	GetCurrencies(ctx context.Context, f core.CurrencyFilter) (core.CurrencyPage, error)
 	AddCurrency(ctx context.Context, symbol, description string) error
-	UpdateCurrency(ctx context.Context, symbol, description string) error
+	UpdateCurrency(ctx context.Context, symbol, description string, version time.Time) error
 	GetCurrency(ctx context.Context, symbol string) (core.Currency, error)
 	RemoveCurrency(ctx context.Context, symbol string) error
-	GetRates(ctx context.Context) (interface{}, error)
-	CreateRate(ctx context.Context, from, to string, rate float64) error
-	UpdateRate(ctx context.Context, from, to string, rate float64) error
-	RemoveRate(ctx context.Context, from, to string) error
+	GetRates(ctx context.Context, at time.Time) (core.CurrencyRates, error)
+	ExportRates(ctx context.Context, at time.Time, fn func(core.CurrencyRate) error) error
+	ExportConversions(ctx context.Context, since, until time.Time, fn func(core.Conversion) error) error
+	ProposeRate(ctx context.Context, action core.RateAction, rate core.CurrencyRate, override core.Override, version, actor string) (core.RateProposal, error)
+	ImportRates(ctx context.Context, rows []core.RateImportRow, opts core.ImportOptions, actor string) (core.ImportReport, error)
+	GetRateProposals(ctx context.Context, status core.ProposalStatus) (core.RateProposals, error)
+	GetRateProposal(ctx context.Context, id string) (core.RateProposal, error)
+	ApproveRateProposal(ctx context.Context, id, actor string) (core.RateProposal, error)
+	RejectRateProposal(ctx context.Context, id, actor, reason string) (core.RateProposal, error)
+	CreateAPIKey(ctx context.Context, name string, scopes []core.Scope) (core.APIKey, string, error)
+	GetAPIKeys(ctx context.Context) (core.APIKeys, error)
+	RotateAPIKey(ctx context.Context, id string) (core.APIKey, string, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	AuthenticateKey(ctx context.Context, secret string) (core.Principal, error)
+	IncrementUsage(ctx context.Context, client string, n int64) (int64, error)
+	GetUsage(ctx context.Context, period time.Time) (core.Usages, error)
+	GetProviderBudget(ctx context.Context) (core.ProviderBudget, error)
+	BeginIdempotentRequest(ctx context.Context, r core.IdempotentRequest) (core.IdempotentRequest, error)
+	CompleteIdempotentRequest(ctx context.Context, r core.IdempotentRequest) error
+	ReleaseIdempotentRequest(ctx context.Context, r core.IdempotentRequest) error
+	SubscribeRates(pairs []string, lastEventID uint64) *stream.Subscription
+	UnsubscribeRates(sub *stream.Subscription)
+	CreateAlertSubscription(ctx context.Context, a core.AlertSubscription) (core.AlertSubscription, error)
+	GetAlertSubscriptions(ctx context.Context) (core.AlertSubscriptions, error)
+	GetAlertSubscription(ctx context.Context, id string) (core.AlertSubscription, error)
+	UpdateAlertSubscription(ctx context.Context, a core.AlertSubscription) (core.AlertSubscription, error)
+	RemoveAlertSubscription(ctx context.Context, id string) error
+	GetWebhookDeliveries(ctx context.Context, subscriptionID string) (core.WebhookDeliveries, error)
 }
 
+// manualSource identifies rates that were registered through the API
+const manualSource = "manual"
+
 type Exchanger interface {
-	GetCurrencies(ctx context.Context) (map[string]string, error)
+	GetCurrencies(ctx context.Context) (core.Currencies, error)
 	Exchange(ctx context.Context, from, to string, amount float64) (core.ConversionResp, error)
+	Budget(ctx context.Context) (core.ProviderBudget, error)
+}
+
+// Sink receives the events of the outbox, an event may be published
+// more than once so consumers deduplicate them by ID
+type Sink interface {
+	Publish(ctx context.Context, events core.OutboxEvents) error
+}
+
+// Notifier posts a webhook delivery once, code is the status
+// answered by the receiver
+type Notifier interface {
+	Deliver(ctx context.Context, sub core.AlertSubscription, d core.WebhookDelivery) (code int, err error)
 }
 
 type Service struct {
 	Repo     Repository
 	Exchange Exchanger
+	Config   Config
+	market   *marketCache
+	seeded   *atomic.Bool
+	events   *stream.Broker
+	alerts   *alertState
+	schedule *rateSchedule
 }
 
-func NewService(repo Repository, exchange Exchanger) Service {
+func NewService(repo Repository, exchange Exchanger, cfg Config) Service {
 	return Service{
 		Repo:     repo,
 		Exchange: exchange,
+		Config:   cfg,
+		market:   newMarketCache(),
+		seeded:   &atomic.Bool{},
+		events:   stream.NewBroker(cfg.Stream),
+		alerts:   newAlertState(),
+		schedule: newRateSchedule(),
 	}
 }
 
+// Seeded reports whether the currencies were seeded since startup
+func (s Service) Seeded() bool {
+	return s.seeded != nil && s.seeded.Load()
+}
+
+// Convert uses the manual rate effective at conv.At, when there is
+// none it falls back to the latest rate of the exchange provider
 func (s Service) Convert(ctx context.Context, conv core.ConversionSVC) (amount float64, source string, err error) {
-	// always get latest rate and update on repo
+	ctx, span := startSpan(ctx, "Convert")
+	defer func() { tracing.End(span, err) }()
+	at := conv.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	rate, err := s.rateAt(ctx, conv.From, conv.To, at)
+	if err == nil {
+		s.observeRate(conv.From, conv.To, manualSource, rate)
+		amount = conv.Amount * rate
+		s.recordConversions(ctx, conversionEvent(conv, at, amount, manualSource))
+		return amount, manualSource, nil
+	}
+	if !errors.Is(err, core.ErrNotFound) {
+		return
+	}
 	resp, err := s.Exchange.Exchange(ctx, conv.From, conv.To, conv.Amount)
 	if err != nil {
 		return
 	}
-	// todo: store this search
-	return resp.ConvertedAmount, resp.ConversionSource, err
+	if conv.Amount != 0 && resp.ConvertedAmount > 0 {
+		s.marketSeen(conv.From, conv.To, resp.ConversionSource, resp.ConvertedAmount/conv.Amount)
+	}
+	s.recordConversions(ctx, conversionEvent(conv, at, resp.ConvertedAmount, resp.ConversionSource))
+	return resp.ConvertedAmount, resp.ConversionSource, nil
 }
 
-// todo: on init try to seed the repo
-func (s Service) GetCurrencies(ctx context.Context) (cs core.Currencies, err error) {
-	// get from repo
+// ConvertMany converts a batch with one lookup of the manual rates
+// per instant and one provider quote per pair without a manual rate,
+// results are in the order of convs. Provider failures only fail
+// the items of the pair
+func (s Service) ConvertMany(ctx context.Context, convs []core.ConversionSVC) (results []core.ConversionResult, err error) {
+	ctx, span := startSpan(ctx, "ConvertMany")
+	defer func() { tracing.End(span, err) }()
+	type quote struct {
+		rate   float64
+		source string
+		err    error
+	}
+	now := time.Now()
+	schedules := map[time.Time]core.CurrencyRates{}
+	quotes := map[string]quote{}
+	results = make([]core.ConversionResult, 0, len(convs))
+	var completed []core.ConversionEvent
+	for _, conv := range convs {
+		at := conv.At
+		if at.IsZero() {
+			at = now
+		}
+		rates, ok := schedules[at]
+		if !ok {
+			if rates, err = s.Repo.GetRates(ctx, at); err != nil {
+				return nil, err
+			}
+			schedules[at] = rates
+		}
 
-	// if none in repo, fall back to external
-	currencies, err := s.Exchange.GetCurrencies(ctx)
-	if err != nil {
-		return
+		rate, found := rates.RateOf(conv.From, conv.To)
+		q := quote{rate: rate, source: manualSource}
+		if found {
+			s.observeRate(conv.From, conv.To, manualSource, rate)
+		} else {
+			pair := core.Pair(conv.From, conv.To)
+			if q, ok = quotes[pair]; !ok {
+				// the quote of a unit is scaled to every amount of the pair
+				resp, qerr := s.Exchange.Exchange(ctx, conv.From, conv.To, 1)
+				q = quote{rate: resp.ConvertedAmount, source: resp.ConversionSource, err: qerr}
+				if qerr == nil && q.rate > 0 {
+					s.marketSeen(conv.From, conv.To, q.source, q.rate)
+				}
+				quotes[pair] = q
+			}
+		}
+		if q.err != nil {
+			results = append(results, core.ConversionResult{Err: q.err})
+			continue
+		}
+
+		amount := conv.Amount * q.rate
+		completed = append(completed, conversionEvent(conv, at, amount, q.source))
+		results = append(results, core.ConversionResult{ConversionResp: core.TransformSVCToResp(conv, amount, q.source)})
 	}
-	for k, v := range currencies {
-		cs = append(cs, core.Currency{
-			Symbol:      k,
-			Description: v,
-			Source:      "exchange",
-		})
+	if len(completed) > 0 {
+		s.recordConversions(ctx, completed...)
 	}
-	return
+	return results, nil
 }
 
-func (s Service) AddCurrency(ctx context.Context, symbol, description string) (err error) {
-	// check whether the currency already exists
-	// if exists return ok without inserting
-
-	// add currency to repo
-	return
+// GetCurrencies lists a page of the seeded currencies
+func (s Service) GetCurrencies(ctx context.Context, f core.CurrencyFilter) (p core.CurrencyPage, err error) {
+	ctx, span := startSpan(ctx, "GetCurrencies")
+	defer func() { tracing.End(span, err) }()
+	if err = f.Check(); err != nil {
+		return
+	}
+	return s.Repo.GetCurrencies(ctx, f)
 }
 
-func (s Service) UpdateCurrency(ctx context.Context, symbol, description string) (err error) {
-	// check whether the currency exists
-	// if not exists return error
+// AddCurrency registers a manual currency, a removed one is restored
+// and an existing one is kept as is
+func (s Service) AddCurrency(ctx context.Context, symbol, description string) (err error) {
+	ctx, span := startSpan(ctx, "AddCurrency")
+	defer func() { tracing.End(span, err) }()
+	c := core.Currency{Symbol: symbol, Description: description, Source: manualSource, Kind: core.CurrencyFiat}
+	return s.Repo.RunInTx(ctx, func(ctx context.Context) (err error) {
+		created, err := s.Repo.CreateCurrency(ctx, c)
+		if err != nil {
+			return
+		}
+		if !created {
+			if created, err = s.Repo.RestoreCurrency(ctx, c); err != nil || !created {
+				return
+			}
+		}
+		return s.record(ctx, core.EventCurrencyCreated, c.Symbol, c)
+	})
+}
 
-	// update currency on repo
-	return
+// UpdateCurrency changes the currency description, version is the
+// updated_at the caller read and a zero version skips the check
+func (s Service) UpdateCurrency(ctx context.Context, symbol, description string, version time.Time) (err error) {
+	ctx, span := startSpan(ctx, "UpdateCurrency")
+	defer func() { tracing.End(span, err) }()
+	return s.Repo.RunInTx(ctx, func(ctx context.Context) (err error) {
+		if err = s.Repo.UpdateCurrency(ctx, symbol, description, version); err != nil {
+			return
+		}
+		c, err := s.Repo.GetCurrency(ctx, symbol)
+		if err != nil {
+			return
+		}
+		return s.record(ctx, core.EventCurrencyUpdated, c.Symbol, c)
+	})
 }
 
 func (s Service) GetCurrency(ctx context.Context, symbol string) (cr core.Currency, err error) {
+	ctx, span := startSpan(ctx, "GetCurrency")
+	defer func() { tracing.End(span, err) }()
 	// could use a cache system to reduce DB toll
-
-	// get currency from repo
-	return
+	return s.Repo.GetCurrency(ctx, symbol)
 }
 
+// RemoveCurrency soft deletes the currency, ErrNotFound is returned
+// when it does not exist or was already removed
 func (s Service) RemoveCurrency(ctx context.Context, symbol string) (err error) {
-	// get currency from repo
-
-	// remove from repo
-	return
+	ctx, span := startSpan(ctx, "RemoveCurrency")
+	defer func() { tracing.End(span, err) }()
+	return s.Repo.RunInTx(ctx, func(ctx context.Context) (err error) {
+		c, err := s.Repo.GetCurrency(ctx, symbol)
+		if err != nil {
+			return
+		}
+		if err = s.Repo.RemoveCurrency(ctx, symbol); err != nil {
+			return
+		}
+		c.Deleted = true
+		return s.record(ctx, core.EventCurrencyDeleted, c.Symbol, c)
+	})
 }
 
-func (s Service) GetRates(ctx context.Context) (rts interface{}, err error) {
-	// get currency rates from repo
-	return
+// GetRates lists the rate windows effective at the given instant,
+// or the whole schedule when at is zero
+func (s Service) GetRates(ctx context.Context, at time.Time) (rts core.CurrencyRates, err error) {
+	ctx, span := startSpan(ctx, "GetRates")
+	defer func() { tracing.End(span, err) }()
+	return s.Repo.GetRates(ctx, at)
 }
 
-func (s Service) CreateRate(ctx context.Context, from, to string, rate float64) (err error) {
-	// ensure currencies exist and are stored
+// ExportRates calls fn with each rate window GetRates would list,
+// the windows are read as fn consumes them
+func (s Service) ExportRates(ctx context.Context, at time.Time, fn func(core.CurrencyRate) error) (err error) {
+	ctx, span := startSpan(ctx, "ExportRates")
+	defer func() { tracing.End(span, err) }()
+	return s.Repo.EachRate(ctx, at, fn)
+}
 
-	// create rate
+// ExportConversions calls fn with the conversions made in
+// [since, until) oldest first, a zero bound leaves that side open
+func (s Service) ExportConversions(ctx context.Context, since, until time.Time, fn func(core.Conversion) error) (err error) {
+	ctx, span := startSpan(ctx, "ExportConversions")
+	defer func() { tracing.End(span, err) }()
+	if !since.IsZero() && !until.IsZero() && !until.After(since) {
+		return core.ErrInvalidHistoryRange
+	}
+	return s.Repo.EachConversion(ctx, since, until, fn)
+}
 
-	// create reverse rate
-	return
+// checkRate validates a rate change against the stored windows,
+// windows starting in the future are allowed but they cannot
+// overlap another window of the pair. New rates are also compared
+// with the market rate unless the change is forced
+func (s Service) checkRate(ctx context.Context, action core.RateAction, rate core.CurrencyRate, override core.Override, now time.Time) (err error) {
+	if err = s.checkWindow(ctx, action, rate, override, now); err != nil || action == core.RateActionDelete {
+		return
+	}
+	return s.checkDeviation(ctx, rate, override)
 }
 
-func (s Service) UpdateRate(ctx context.Context, from, to string, rate float64) (err error) {
-	// ensure currencies exist and are stored
+// checkWindow is checkRate without the market deviation guard, now
+// is the instant the windows without valid_from were started at
+func (s Service) checkWindow(ctx context.Context, action core.RateAction, rate core.CurrencyRate, override core.Override, now time.Time) (err error) {
+	if err = rate.Check(); err != nil {
+		return
+	}
+	if err = s.ensureCurrencies(ctx, rate.From, rate.To); err != nil {
+		return
+	}
+	if action == core.RateActionDelete {
+		return
+	}
+	overlapping, err := s.Repo.GetOverlappingRates(ctx, rate)
+	if err != nil {
+		return
+	}
+	for _, o := range overlapping {
+		if action == core.RateActionUpdate && o.ValidFrom.Equal(rate.ValidFrom) {
+			continue
+		}
+		// CreateRate closes the open window when the new one starts
+		if action == core.RateActionCreate && rate.Supersedes(o) {
+			if rate.ValidFrom.Before(now) {
+				return core.ErrRateRewritesPast
+			}
+			continue
+		}
+		if rate.Overlaps(o) {
+			return core.ErrRateOverlap
+		}
+	}
+	return override.Check()
+}
 
-	// update rate
+// resolveRate fills the window a rate change refers to: updates and
+// removals target the window that is currently effective when no
+// valid_from is given, new windows without it are left to start when
+// they are approved, see startsAt
+func (s Service) resolveRate(ctx context.Context, action core.RateAction, rate core.CurrencyRate) (core.CurrencyRate, error) {
+	if !rate.ValidFrom.IsZero() || action == core.RateActionCreate {
+		return rate, nil
+	}
+	current, err := s.Repo.GetRateAt(ctx, rate.From, rate.To, time.Now())
+	if errors.Is(err, core.ErrNotFound) {
+		return rate, core.ErrRateNotFound
+	}
+	if err != nil {
+		return rate, err
+	}
+	rate.ValidFrom = current.ValidFrom
+	if action == core.RateActionDelete {
+		rate.Rate = current.Rate
+		rate.ValidTo = current.ValidTo
+	}
+	return rate, nil
+}
 
-	// update reverse rate
-	return
+// startsAt sets the start of a new window without valid_from to t,
+// proposals keep it empty so the window starts when it is approved
+// instead of when it was proposed
+func startsAt(action core.RateAction, rate core.CurrencyRate, t time.Time) core.CurrencyRate {
+	if action == core.RateActionCreate && rate.ValidFrom.IsZero() {
+		rate.ValidFrom = t
+	}
+	return rate
 }
 
-func (s Service) RemoveRate(ctx context.Context, from, to string) (err error) {
-	// ensure currencies exist and are stored
+// applyRate writes an approved rate change into the rates table,
+// it has to run in a transaction since the change is also recorded
+// in the outbox. market is the rate the deviation check compares
+// with, zero skips the check
+func (s Service) applyRate(ctx context.Context, action core.RateAction, rate core.CurrencyRate, override core.Override, market float64, now time.Time) (err error) {
+	if err = s.checkWindow(ctx, action, rate, override, now); err != nil {
+		return
+	}
+	if market > 0 {
+		if err = s.deviation(rate, market); err != nil {
+			return
+		}
+	}
+	switch action {
+	case core.RateActionCreate:
+		err = s.Repo.CreateRate(ctx, rate, manualSource)
+	case core.RateActionUpdate:
+		err = s.Repo.UpdateRate(ctx, rate)
+	case core.RateActionDelete:
+		err = s.Repo.RemoveRate(ctx, rate.From, rate.To, rate.ValidFrom)
+	default:
+		return core.ErrInvalidRateAction
+	}
+	if errors.Is(err, core.ErrNotFound) {
+		return core.ErrRateNotFound
+	}
+	if err != nil {
+		return
+	}
+	return s.record(ctx, core.RateEventTypes[action], core.Pair(rate.From, rate.To), rate)
+}
 
-	// remove rate
+// rateAt resolves the manual rate effective at the given instant,
+// using the inverse of the reverse pair when only that one is stored
+func (s Service) rateAt(ctx context.Context, from, to string, at time.Time) (float64, error) {
+	rate, err := s.Repo.GetRateAt(ctx, from, to, at)
+	if err == nil {
+		return rate.Rate, nil
+	}
+	if !errors.Is(err, core.ErrNotFound) {
+		return 0, err
+	}
+	rate, err = s.Repo.GetRateAt(ctx, to, from, at)
+	if err != nil {
+		return 0, err
+	}
+	return 1 / rate.Rate, nil
+}
 
-	// remove reverse rate
-	return
+func (s Service) ensureCurrencies(ctx context.Context, symbols ...string) error {
+	for _, symbol := range symbols {
+		_, err := s.Repo.GetCurrency(ctx, symbol)
+		if errors.Is(err, core.ErrNotFound) {
+			return core.ErrUnknownCurrency
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (s Service) Seed(ctx context.Context) error {
+func (s Service) Seed(ctx context.Context) (err error) {
+	ctx, span := startSpan(ctx, "Seed")
+	defer func() { tracing.End(span, err) }()
+	defer func() {
+		if err != nil {
+			metrics.SeedRuns.WithLabelValues(metrics.Failure).Inc()
+			return
+		}
+		metrics.SeedRuns.WithLabelValues(metrics.Success).Inc()
+		metrics.SeedLastSuccess.SetToCurrentTime()
+		if s.seeded != nil {
+			s.seeded.Store(true)
+		}
+	}()
 	currencies, err := s.Exchange.GetCurrencies(ctx)
 	if err != nil {
 		return err
 	}
-	for symbol, desc := range currencies {
-		if err = s.Repo.CreateCurrency(ctx, symbol, desc, "exchange"); err != nil {
-			log.Error("error: ", err.Error())
-			return err
+	err = s.Repo.RunInTx(ctx, func(ctx context.Context) error {
+		for _, c := range currencies {
+			created, err := s.Repo.CreateCurrency(ctx, c)
+			if err != nil {
+				log.Error("error: ", err.Error())
+				return err
+			}
+			if !created {
+				continue
+			}
+			if err = s.record(ctx, core.EventCurrencyCreated, c.Symbol, c); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	metrics.SeedCurrencies.Set(float64(len(currencies)))
 	log.Info("seed ended")
 	return nil
 }
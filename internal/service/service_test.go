@@ -0,0 +1,1168 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	svcmock "github.com/arxdsilva/bravo/internal/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = Config{MaxDeviation: 10}
+
+func runInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func Test_ProposeRate(t *testing.T) {
+	t.Parallel()
+	validFrom := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	validTo := validFrom.Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name        string
+		rate        core.CurrencyRate
+		actor       string
+		override    core.Override
+		overlapping core.CurrencyRates
+		market      float64
+		wantPropose bool
+		wantErr     error
+	}{
+		{
+			name:    "actor is required",
+			rate:    core.CurrencyRate{From: "USD", To: "BRL", Rate: 5.2, ValidFrom: validFrom},
+			wantErr: core.ErrActorRequired,
+		},
+		{
+			name:  "overlapping window is rejected",
+			rate:  core.CurrencyRate{From: "USD", To: "BRL", Rate: 5.2, ValidFrom: validFrom},
+			actor: "maker",
+			overlapping: core.CurrencyRates{
+				{From: "USD", To: "BRL", Rate: 5.1, ValidFrom: validFrom.Add(-time.Hour), ValidTo: &validTo},
+			},
+			wantErr: core.ErrRateOverlap,
+		},
+		{
+			name:  "open window is superseded",
+			rate:  core.CurrencyRate{From: "USD", To: "BRL", Rate: 5.2, ValidFrom: validFrom},
+			actor: "maker",
+			overlapping: core.CurrencyRates{
+				{From: "USD", To: "BRL", Rate: 5.1, ValidFrom: validFrom.Add(-time.Hour)},
+			},
+			market:      5,
+			wantPropose: true,
+		},
+		{
+			name:  "open window cannot be closed in the past",
+			rate:  core.CurrencyRate{From: "USD", To: "BRL", Rate: 5.2, ValidFrom: past},
+			actor: "maker",
+			overlapping: core.CurrencyRates{
+				{From: "USD", To: "BRL", Rate: 5.1, ValidFrom: past.Add(-time.Hour)},
+			},
+			wantErr: core.ErrRateRewritesPast,
+		},
+		{
+			name:  "open window starting at the same instant is rejected",
+			rate:  core.CurrencyRate{From: "USD", To: "BRL", Rate: 5.2, ValidFrom: validFrom},
+			actor: "maker",
+			overlapping: core.CurrencyRates{
+				{From: "USD", To: "BRL", Rate: 5.1, ValidFrom: validFrom},
+			},
+			wantErr: core.ErrRateOverlap,
+		},
+		{
+			name:        "future window is proposed",
+			rate:        core.CurrencyRate{From: "USD", To: "BRL", Rate: 5.2, ValidFrom: validFrom},
+			actor:       "maker",
+			overlapping: core.CurrencyRates{},
+			market:      5,
+			wantPropose: true,
+		},
+		{
+			name:        "window without valid_from is proposed without it",
+			rate:        core.CurrencyRate{From: "USD", To: "BRL", Rate: 5.2},
+			actor:       "maker",
+			overlapping: core.CurrencyRates{},
+			market:      5,
+			wantPropose: true,
+		},
+		{
+			name:        "rate far from market is rejected",
+			rate:        core.CurrencyRate{From: "USD", To: "BRL", Rate: 52, ValidFrom: validFrom},
+			actor:       "maker",
+			overlapping: core.CurrencyRates{},
+			market:      5.2,
+			wantErr:     core.ErrRateDeviation,
+		},
+		{
+			name:        "forced rate far from market is proposed",
+			rate:        core.CurrencyRate{From: "USD", To: "BRL", Rate: 52, ValidFrom: validFrom},
+			actor:       "maker",
+			override:    core.Override{Force: true, Reason: "devaluation"},
+			overlapping: core.CurrencyRates{},
+			wantPropose: true,
+		},
+		{
+			name:        "forced rate needs a reason",
+			rate:        core.CurrencyRate{From: "USD", To: "BRL", Rate: 52, ValidFrom: validFrom},
+			actor:       "maker",
+			override:    core.Override{Force: true},
+			overlapping: core.CurrencyRates{},
+			wantErr:     core.ErrForceReasonRequired,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := svcmock.NewMockRepository(ctrl)
+			exchange := svcmock.NewMockExchanger(ctrl)
+			if tt.actor != "" {
+				repo.EXPECT().GetCurrency(gomock.Any(), gomock.Any()).Return(core.Currency{}, nil).Times(2)
+				// windows without valid_from are checked as starting now
+				checked := gomock.Eq(tt.rate)
+				if tt.rate.ValidFrom.IsZero() {
+					checked = gomock.Any()
+				}
+				repo.EXPECT().GetOverlappingRates(gomock.Any(), checked).Return(tt.overlapping, nil)
+			}
+			if tt.market != 0 {
+				exchange.EXPECT().Exchange(gomock.Any(), "USD", "BRL", float64(1)).
+					Return(core.ConversionResp{ConvertedAmount: tt.market}, nil)
+			}
+			if tt.wantPropose {
+				repo.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				repo.EXPECT().CreateRateProposal(gomock.Any(), core.RateProposal{
+					Action:     core.RateActionCreate,
+					Rate:       tt.rate,
+					Override:   tt.override,
+					Status:     core.ProposalPending,
+					ProposedBy: tt.actor,
+				}).Return(core.RateProposal{ID: "id", ProposedBy: tt.actor}, nil)
+				repo.EXPECT().CreateRateAudit(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			_, err := NewService(repo, exchange, testConfig).ProposeRate(
+				context.Background(), core.RateActionCreate, tt.rate, tt.override, "", tt.actor)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func Test_ApproveRateProposal(t *testing.T) {
+	t.Parallel()
+	rate := core.CurrencyRate{From: "USD", To: "BRL", Rate: 5.2, ValidFrom: time.Now()}
+	proposal := core.RateProposal{
+		ID:         "id",
+		Action:     core.RateActionCreate,
+		Rate:       rate,
+		Status:     core.ProposalPending,
+		ProposedBy: "maker",
+	}
+	schedule := core.CurrencyRates{{From: "USD", To: "BRL", Rate: 5, ValidFrom: rate.ValidFrom.Add(-time.Hour)}}
+	version, err := schedule.Version()
+	require.NoError(t, err)
+	versioned := proposal
+	versioned.RatesVersion = version
+	tests := []struct {
+		name        string
+		actor       string
+		proposal    core.RateProposal
+		overlapping core.CurrencyRates
+		schedule    core.CurrencyRates
+		marketErr   error
+		wantApply   bool
+		wantErr     error
+	}{
+		{
+			name:     "maker cannot approve",
+			actor:    "maker",
+			proposal: proposal,
+			wantErr:  core.ErrSelfApproval,
+		},
+		{
+			name:  "decided proposal",
+			actor: "checker",
+			proposal: core.RateProposal{
+				ID: "id", Status: core.ProposalRejected, ProposedBy: "maker",
+			},
+			wantErr: core.ErrProposalDecided,
+		},
+		{
+			name:      "checker approves",
+			actor:     "checker",
+			proposal:  proposal,
+			wantApply: true,
+		},
+		{
+			name:  "window without valid_from starts when approved",
+			actor: "checker",
+			proposal: core.RateProposal{
+				ID: "id", Action: core.RateActionCreate, Status: core.ProposalPending, ProposedBy: "maker",
+				Rate: core.CurrencyRate{From: "USD", To: "BRL", Rate: 5.2},
+			},
+			wantApply: true,
+		},
+		{
+			name:        "valid_from passed before the approval",
+			actor:       "checker",
+			proposal:    proposal,
+			overlapping: core.CurrencyRates{{From: "USD", To: "BRL", Rate: 5, ValidFrom: rate.ValidFrom.Add(-time.Hour)}},
+			wantErr:     core.ErrRateRewritesPast,
+		},
+		{
+			name:      "schedule is the one proposed over",
+			actor:     "checker",
+			proposal:  versioned,
+			schedule:  schedule,
+			wantApply: true,
+		},
+		{
+			name:     "schedule changed since the proposal",
+			actor:    "checker",
+			proposal: versioned,
+			schedule: core.CurrencyRates{{From: "USD", To: "BRL", Rate: 5.1, ValidFrom: rate.ValidFrom.Add(-time.Hour)}},
+			wantErr:  core.ErrPreconditionFailed,
+		},
+		{
+			name:      "market unavailable fails before locking",
+			actor:     "checker",
+			proposal:  proposal,
+			marketErr: core.ErrProviderUnavailable,
+			wantErr:   core.ErrMarketRateUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			approved := time.Now()
+			// the window applied, proposals without valid_from start
+			// after approved
+			requireRate := func(r core.CurrencyRate) {
+				if !tt.proposal.Rate.ValidFrom.IsZero() {
+					require.Equal(t, rate, r)
+					return
+				}
+				require.False(t, r.ValidFrom.Before(approved))
+				r.ValidFrom = rate.ValidFrom
+				require.Equal(t, rate, r)
+			}
+			repo := svcmock.NewMockRepository(ctrl)
+			exchange := svcmock.NewMockExchanger(ctrl)
+			repo.EXPECT().GetRateProposal(gomock.Any(), "id").Return(tt.proposal, nil)
+			inTx := tt.wantApply || tt.overlapping != nil || tt.schedule != nil
+			if inTx || tt.marketErr != nil {
+				exchange.EXPECT().Exchange(gomock.Any(), "USD", "BRL", float64(1)).
+					Return(core.ConversionResp{ConvertedAmount: 5.1}, tt.marketErr)
+			}
+			if inTx {
+				// the provider is called before the proposal is locked
+				repo.EXPECT().RunInTx(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+						repo.EXPECT().GetRateProposal(gomock.Any(), "id").Return(tt.proposal, nil)
+						return fn(ctx)
+					})
+			}
+			if tt.schedule != nil {
+				// the schedule is compared while the rates are locked
+				lock := repo.EXPECT().LockRates(gomock.Any()).Return(nil)
+				repo.EXPECT().GetRates(gomock.Any(), time.Time{}).Return(tt.schedule, nil).After(lock)
+			}
+			if tt.wantApply || tt.overlapping != nil {
+				repo.EXPECT().GetCurrency(gomock.Any(), gomock.Any()).Return(core.Currency{}, nil).Times(2)
+				repo.EXPECT().GetOverlappingRates(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, r core.CurrencyRate) (core.CurrencyRates, error) {
+						requireRate(r)
+						return tt.overlapping, nil
+					})
+			}
+			if tt.wantApply {
+				repo.EXPECT().CreateRate(gomock.Any(), gomock.Any(), manualSource).
+					DoAndReturn(func(_ context.Context, r core.CurrencyRate, _ string) error {
+						requireRate(r)
+						return nil
+					})
+				repo.EXPECT().CreateOutboxEvent(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, e core.OutboxEvent) error {
+						require.Equal(t, core.EventRateCreated, e.Type)
+						require.Equal(t, "USD/BRL", e.Key)
+						return nil
+					})
+				repo.EXPECT().DecideRateProposal(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, p core.RateProposal) error {
+						// the proposal keeps the start it was applied with
+						requireRate(p.Rate)
+						return nil
+					})
+				repo.EXPECT().CreateRateAudit(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			cfg := testConfig
+			cfg.Stream.Buffer = 2
+			svc := NewService(repo, exchange, cfg)
+			sub := svc.SubscribeRates([]string{"USD/BRL"}, 0)
+			p, err := svc.ApproveRateProposal(context.Background(), "id", tt.actor)
+			require.Equal(t, tt.wantErr, err)
+			if tt.wantApply {
+				require.Equal(t, core.ProposalApproved, p.Status)
+				require.Equal(t, tt.actor, p.DecidedBy)
+				// the market rate used by the deviation check comes first
+				market := <-sub.Events()
+				require.Equal(t, 5.1, market.Rate.Rate)
+				e := <-sub.Events()
+				require.Equal(t, core.RateActionCreate, e.Action)
+				require.Equal(t, manualSource, e.Source)
+				requireRate(e.Rate)
+				return
+			}
+			if inTx {
+				market := <-sub.Events()
+				require.Equal(t, 5.1, market.Rate.Rate)
+			}
+			// the refused change is not announced
+			require.Empty(t, sub.Events())
+		})
+	}
+}
+
+func Test_Convert(t *testing.T) {
+	t.Parallel()
+	at := time.Date(2022, 11, 2, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		direct     *core.CurrencyRate
+		reverse    *core.CurrencyRate
+		recordErr  error
+		wantAmount float64
+		wantSource string
+	}{
+		{
+			name:       "direct manual rate",
+			direct:     &core.CurrencyRate{Rate: 5},
+			wantAmount: 50,
+			wantSource: manualSource,
+		},
+		{
+			name:       "reverse manual rate",
+			reverse:    &core.CurrencyRate{Rate: 0.2},
+			wantAmount: 50,
+			wantSource: manualSource,
+		},
+		{
+			name:       "exchange fallback",
+			wantAmount: 51,
+			wantSource: "exchange",
+		},
+		{
+			name:       "history failure does not fail the conversion",
+			direct:     &core.CurrencyRate{Rate: 5},
+			recordErr:  errors.New("db"),
+			wantAmount: 50,
+			wantSource: manualSource,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := svcmock.NewMockRepository(ctrl)
+			exchange := svcmock.NewMockExchanger(ctrl)
+
+			if tt.direct != nil {
+				repo.EXPECT().GetRateAt(gomock.Any(), "USD", "BRL", at).Return(*tt.direct, nil)
+			} else {
+				repo.EXPECT().GetRateAt(gomock.Any(), "USD", "BRL", at).Return(core.CurrencyRate{}, core.ErrNotFound)
+				if tt.reverse != nil {
+					repo.EXPECT().GetRateAt(gomock.Any(), "BRL", "USD", at).Return(*tt.reverse, nil)
+				} else {
+					repo.EXPECT().GetRateAt(gomock.Any(), "BRL", "USD", at).Return(core.CurrencyRate{}, core.ErrNotFound)
+					exchange.EXPECT().Exchange(gomock.Any(), "USD", "BRL", float64(10)).
+						Return(core.ConversionResp{ConvertedAmount: 51, ConversionSource: "exchange"}, nil)
+				}
+			}
+
+			repo.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+			repo.EXPECT().CreateConversions(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, cs []core.ConversionEvent) error {
+					require.Len(t, cs, 1)
+					require.InDelta(t, tt.wantAmount, cs[0].ConvertedAmount, 1e-9)
+					require.Equal(t, tt.wantSource, cs[0].ConversionSource)
+					require.Equal(t, at, cs[0].At)
+					return tt.recordErr
+				})
+			if tt.recordErr == nil {
+				repo.EXPECT().CreateOutboxEvents(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, es core.OutboxEvents) error {
+						require.Len(t, es, 1)
+						require.Equal(t, core.EventConversionCompleted, es[0].Type)
+						conv := core.ConversionEvent{}
+						require.NoError(t, json.Unmarshal(es[0].Data, &conv))
+						require.InDelta(t, tt.wantAmount, conv.ConvertedAmount, 1e-9)
+						require.Equal(t, at, conv.At)
+						return nil
+					})
+			}
+
+			amount, source, err := NewService(repo, exchange, testConfig).Convert(context.Background(),
+				core.ConversionSVC{From: "USD", To: "BRL", Amount: 10, At: at})
+			require.NoError(t, err)
+			require.InDelta(t, tt.wantAmount, amount, 1e-9)
+			require.Equal(t, tt.wantSource, source)
+		})
+	}
+}
+
+func Test_ConvertMany(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	at := time.Date(2022, 11, 2, 12, 0, 0, 0, time.UTC)
+	repo := svcmock.NewMockRepository(ctrl)
+	exchange := svcmock.NewMockExchanger(ctrl)
+	repo.EXPECT().GetRates(gomock.Any(), at).Return(core.CurrencyRates{{From: "USD", To: "BRL", Rate: 5}}, nil)
+	exchange.EXPECT().Exchange(gomock.Any(), "BTC", "USD", float64(1)).
+		Return(core.ConversionResp{ConvertedAmount: 20000, ConversionSource: "exchange"}, nil)
+	exchange.EXPECT().Exchange(gomock.Any(), "ETH", "USD", float64(1)).
+		Return(core.ConversionResp{}, core.ErrProviderUnavailable)
+	repo.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+	repo.EXPECT().CreateConversions(gomock.Any(), gomock.Len(4)).Return(nil)
+	repo.EXPECT().CreateOutboxEvents(gomock.Any(), gomock.Len(4)).Return(nil)
+
+	results, err := NewService(repo, exchange, testConfig).ConvertMany(context.Background(), []core.ConversionSVC{
+		{From: "USD", To: "BRL", Amount: 10, At: at},
+		{From: "BRL", To: "USD", Amount: 10, At: at},
+		{From: "BTC", To: "USD", Amount: 2, At: at},
+		{From: "BTC", To: "USD", Amount: 3, At: at},
+		{From: "ETH", To: "USD", Amount: 1, At: at},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+	require.InDelta(t, 50, results[0].ConvertedAmount, 1e-9)
+	require.Equal(t, manualSource, results[0].ConversionSource)
+	require.InDelta(t, 2, results[1].ConvertedAmount, 1e-9)
+	require.InDelta(t, 40000, results[2].ConvertedAmount, 1e-9)
+	require.InDelta(t, 60000, results[3].ConvertedAmount, 1e-9)
+	require.Equal(t, "exchange", results[3].ConversionSource)
+	require.ErrorIs(t, results[4].Err, core.ErrProviderUnavailable)
+}
+
+func Test_ExportConversions(t *testing.T) {
+	t.Parallel()
+	since := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		since   time.Time
+		until   time.Time
+		wantErr error
+	}{
+		{name: "open range"},
+		{name: "since only", since: since},
+		{name: "range", since: since, until: since.AddDate(0, 1, 0)},
+		{name: "until before since", since: since, until: since.Add(-time.Hour), wantErr: core.ErrInvalidHistoryRange},
+		{name: "empty range", since: since, until: since, wantErr: core.ErrInvalidHistoryRange},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := svcmock.NewMockRepository(ctrl)
+			conv := core.Conversion{ID: "c1", ConversionEvent: core.ConversionEvent{
+				ConversionResp: core.ConversionResp{From: "USD", To: "BRL", OriginalAmount: 10, ConvertedAmount: 50},
+			}}
+			if tt.wantErr == nil {
+				repo.EXPECT().EachConversion(gomock.Any(), tt.since, tt.until, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _, _ time.Time, fn func(core.Conversion) error) error {
+						return fn(conv)
+					})
+			}
+
+			var got []core.Conversion
+			err := NewService(repo, nil, testConfig).ExportConversions(context.Background(), tt.since, tt.until,
+				func(c core.Conversion) error {
+					got = append(got, c)
+					return nil
+				})
+			require.Equal(t, tt.wantErr, err)
+			if tt.wantErr == nil {
+				require.Equal(t, []core.Conversion{conv}, got)
+			}
+		})
+	}
+}
+
+func Test_BeginIdempotentRequest(t *testing.T) {
+	t.Parallel()
+	req := core.IdempotentRequest{Client: "api_key:id", Key: "k1", Method: "POST", Path: "/v1/rates", Fingerprint: "f1"}
+	tests := []struct {
+		name     string
+		req      core.IdempotentRequest
+		held     core.IdempotentRequest
+		claimed  bool
+		wantHeld core.IdempotentRequest
+		wantErr  error
+	}{
+		{
+			name:    "invalid key",
+			req:     core.IdempotentRequest{Client: "api_key:id"},
+			wantErr: core.ErrInvalidIdempotencyKey,
+		},
+		{
+			name:     "claimed",
+			req:      req,
+			claimed:  true,
+			wantHeld: req,
+		},
+		{
+			name:    "reused with another body",
+			req:     req,
+			held:    core.IdempotentRequest{Key: "k1", Fingerprint: "f2", Status: 202},
+			wantErr: core.ErrIdempotencyKeyReused,
+		},
+		{
+			name:    "still in flight",
+			req:     req,
+			held:    core.IdempotentRequest{Key: "k1", Fingerprint: "f1"},
+			wantErr: core.ErrIdempotencyKeyInFlight,
+		},
+		{
+			name:     "completed",
+			req:      req,
+			held:     core.IdempotentRequest{Key: "k1", Fingerprint: "f1", Status: 202, Body: []byte("{}")},
+			wantHeld: core.IdempotentRequest{Key: "k1", Fingerprint: "f1", Status: 202, Body: []byte("{}")},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := svcmock.NewMockRepository(ctrl)
+			exchange := svcmock.NewMockExchanger(ctrl)
+			cfg := testConfig
+			cfg.IdempotencyTTL = time.Hour
+			cfg.IdempotencyLease = time.Minute
+			if tt.req.Key != "" {
+				repo.EXPECT().ClaimIdempotencyKey(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, r core.IdempotentRequest) (core.IdempotentRequest, bool, error) {
+						// in flight keys are only held for the lease
+						require.WithinDuration(t, time.Now().Add(time.Minute), r.ExpiresAt, time.Second)
+						if tt.claimed {
+							return r, true, nil
+						}
+						return tt.held, false, nil
+					})
+			}
+
+			held, err := NewService(repo, exchange, cfg).BeginIdempotentRequest(context.Background(), tt.req)
+			require.Equal(t, tt.wantErr, err)
+			if err == nil {
+				held.ExpiresAt = time.Time{}
+				require.Equal(t, tt.wantHeld, held)
+			}
+		})
+	}
+}
+
+func Test_CompleteIdempotentRequest(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := svcmock.NewMockRepository(ctrl)
+	cfg := testConfig
+	cfg.IdempotencyTTL = time.Hour
+	cfg.IdempotencyLease = time.Minute
+	repo.EXPECT().CompleteIdempotencyKey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, r core.IdempotentRequest) error {
+			// the response is replayed for the ttl
+			require.WithinDuration(t, time.Now().Add(time.Hour), r.ExpiresAt, time.Second)
+			return nil
+		})
+
+	err := NewService(repo, nil, cfg).CompleteIdempotentRequest(context.Background(), core.IdempotentRequest{Key: "k1", Status: 201})
+	require.NoError(t, err)
+}
+
+func Test_evaluateAlerts(t *testing.T) {
+	t.Parallel()
+	at := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	above := core.AlertSubscription{ID: "a1", From: "USD", To: "BRL", Condition: core.AlertAbove, Threshold: 5.5}
+	change := core.AlertSubscription{ID: "c1", From: "USD", To: "BRL", Condition: core.AlertChange, Threshold: 2, Window: core.Duration(time.Hour)}
+	tests := []struct {
+		name          string
+		action        core.RateAction
+		subs          core.AlertSubscriptions
+		seen          []rateSample
+		rate          float64
+		dedupe        time.Duration
+		triggered     bool
+		wantSince     time.Time
+		wantPrevious  float64
+		wantDelivered int
+	}{
+		{
+			name: "crossing above fires",
+			subs: core.AlertSubscriptions{above},
+			seen: []rateSample{{rate: 5.4, at: at.Add(-time.Minute)}},
+			rate: 5.6, triggered: true,
+			wantSince: at, wantPrevious: 5.4, wantDelivered: 1,
+		},
+		{
+			name: "crossing fired by another replica is skipped",
+			subs: core.AlertSubscriptions{above},
+			seen: []rateSample{{rate: 5.4, at: at.Add(-time.Minute)}},
+			rate: 5.6, dedupe: time.Minute, triggered: false,
+			wantSince: at.Add(-time.Minute),
+		},
+		{
+			name: "staying above does not fire",
+			subs: core.AlertSubscriptions{above},
+			seen: []rateSample{{rate: 5.6, at: at.Add(-time.Minute)}},
+			rate: 5.7,
+		},
+		{
+			name: "first rate of the pair does not fire",
+			subs: core.AlertSubscriptions{above},
+			rate: 5.7,
+		},
+		{
+			name: "move within the window fires",
+			subs: core.AlertSubscriptions{change},
+			seen: []rateSample{{rate: 5, at: at.Add(-30 * time.Minute)}, {rate: 5.05, at: at.Add(-10 * time.Minute)}},
+			rate: 5.15, triggered: true,
+			wantSince: at.Add(-time.Hour), wantPrevious: 5, wantDelivered: 1,
+		},
+		{
+			name: "move older than the window is ignored",
+			subs: core.AlertSubscriptions{change},
+			seen: []rateSample{{rate: 5, at: at.Add(-2 * time.Hour)}, {rate: 5.1, at: at.Add(-10 * time.Minute)}},
+			rate: 5.15,
+		},
+		{
+			name: "alert fired within the window is skipped",
+			subs: core.AlertSubscriptions{change},
+			seen: []rateSample{{rate: 5, at: at.Add(-30 * time.Minute)}},
+			rate: 5.15, triggered: false,
+			wantSince: at.Add(-time.Hour),
+		},
+		{
+			name:   "removals are ignored",
+			action: core.RateActionDelete,
+			subs:   core.AlertSubscriptions{above},
+			seen:   []rateSample{{rate: 5.4, at: at.Add(-time.Minute)}},
+			rate:   5.6,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := svcmock.NewMockRepository(ctrl)
+			cfg := testConfig
+			cfg.AlertDedupe = tt.dedupe
+			svc := NewService(repo, nil, cfg)
+			for _, s := range tt.seen {
+				svc.alerts.observe("USD/BRL", s.rate, s.at, time.Hour)
+			}
+			action := tt.action
+			if action == "" {
+				action = core.RateActionUpdate
+			}
+			if action != core.RateActionDelete {
+				repo.EXPECT().GetPairAlertSubscriptions(gomock.Any(), "USD", "BRL").Return(tt.subs, nil)
+			}
+			if !tt.wantSince.IsZero() {
+				repo.EXPECT().TriggerAlertSubscription(gomock.Any(), tt.subs[0].ID, at, tt.wantSince).Return(tt.triggered, nil)
+			}
+			if tt.wantDelivered > 0 {
+				repo.EXPECT().CreateWebhookDelivery(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, d core.WebhookDelivery) (core.WebhookDelivery, error) {
+						require.Equal(t, core.DeliveryPending, d.Status)
+						e := core.AlertEvent{}
+						require.NoError(t, json.Unmarshal(d.Payload, &e))
+						require.Equal(t, tt.subs[0].ID, e.Subscription)
+						require.Equal(t, tt.wantPrevious, e.Previous)
+						require.Equal(t, tt.rate, e.Rate)
+						d.ID = "d1"
+						return d, nil
+					})
+			}
+
+			deliveries, err := svc.evaluateAlerts(context.Background(), core.RateEvent{
+				Action: action,
+				Source: "exchange",
+				Rate:   core.CurrencyRate{From: "USD", To: "BRL", Rate: tt.rate},
+				At:     at,
+			})
+			require.NoError(t, err)
+			require.Len(t, deliveries, tt.wantDelivered)
+		})
+	}
+}
+
+func Test_deliver(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		failures     int
+		attempts     int
+		taken        bool
+		wantAttempts int
+		wantStatus   core.DeliveryStatus
+	}{
+		{name: "delivered at first", attempts: 3, wantAttempts: 1, wantStatus: core.DeliveryDelivered},
+		{name: "delivered after retries", failures: 2, attempts: 3, wantAttempts: 3, wantStatus: core.DeliveryDelivered},
+		{name: "gives up", failures: 5, attempts: 2, wantAttempts: 2, wantStatus: core.DeliveryFailed},
+		{name: "claimed by another replica", attempts: 3, taken: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := svcmock.NewMockRepository(ctrl)
+			notifier := svcmock.NewMockNotifier(ctrl)
+			cfg := testConfig
+			cfg.WebhookAttempts = tt.attempts
+			cfg.WebhookBackoff = time.Millisecond
+			cfg.WebhookLease = time.Minute
+
+			claims := 0
+			repo.EXPECT().ClaimWebhookDelivery(gomock.Any(), "d1", gomock.Any(), gomock.Any(), gomock.Any()).MinTimes(1).
+				DoAndReturn(func(_ context.Context, _ string, attempts int, now, until time.Time) (bool, error) {
+					// each attempt is claimed after the previous one was recorded
+					require.Equal(t, claims, attempts)
+					require.True(t, until.After(now))
+					claims++
+					return !tt.taken, nil
+				})
+			calls := 0
+			notifier.EXPECT().Deliver(gomock.Any(), gomock.Any(), gomock.Any()).Times(tt.wantAttempts).
+				DoAndReturn(func(context.Context, core.AlertSubscription, core.WebhookDelivery) (int, error) {
+					calls++
+					if calls <= tt.failures {
+						return 502, errors.New("receiver responded 502")
+					}
+					return 200, nil
+				})
+			var last core.WebhookDelivery
+			repo.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).Times(tt.wantAttempts).
+				DoAndReturn(func(_ context.Context, d core.WebhookDelivery) error {
+					last = d
+					return nil
+				})
+
+			NewService(repo, nil, cfg).deliver(context.Background(), notifier, alertDelivery{
+				sub:      core.AlertSubscription{ID: "a1"},
+				delivery: core.WebhookDelivery{ID: "d1", Status: core.DeliveryPending},
+			})
+			require.Equal(t, tt.wantStatus, last.Status)
+			require.Equal(t, tt.wantAttempts, last.Attempts)
+			require.Equal(t, tt.wantStatus == core.DeliveryDelivered, last.DeliveredAt != nil)
+			require.Equal(t, tt.wantStatus == core.DeliveryFailed, last.Error != "")
+		})
+	}
+}
+
+func Test_relayOutbox(t *testing.T) {
+	t.Parallel()
+	events := core.OutboxEvents{{ID: "e1", Type: core.EventRateUpdated}, {ID: "e2", Type: core.EventCurrencyCreated}}
+	tests := []struct {
+		name       string
+		claimed    core.OutboxEvents
+		publishErr error
+		wantMarked []string
+		wantN      int
+		wantErr    bool
+	}{
+		{name: "nothing to publish", claimed: core.OutboxEvents{}},
+		{name: "published events are marked", claimed: events, wantMarked: []string{"e1", "e2"}, wantN: 2},
+		{name: "sink failure keeps the events", claimed: events, publishErr: errors.New("sink is down"), wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := svcmock.NewMockRepository(ctrl)
+			sink := svcmock.NewMockSink(ctrl)
+			cfg := testConfig
+			cfg.OutboxBatch = 10
+			repo.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+			repo.EXPECT().ClaimOutboxEvents(gomock.Any(), 10).Return(tt.claimed, nil)
+			if len(tt.claimed) > 0 {
+				sink.EXPECT().Publish(gomock.Any(), tt.claimed).Return(tt.publishErr)
+			}
+			if tt.wantMarked != nil {
+				repo.EXPECT().MarkOutboxEventsPublished(gomock.Any(), tt.wantMarked, gomock.Any()).Return(nil)
+			}
+
+			n, err := NewService(repo, nil, cfg).relayOutbox(context.Background(), sink)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.wantN, n)
+		})
+	}
+}
+
+func Test_announceRates(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	started := core.CurrencyRate{From: "USD", To: "BRL", Rate: 5.1, ValidFrom: now.Add(-time.Second)}
+	later := core.CurrencyRate{From: "USD", To: "BRL", Rate: 5.2, ValidFrom: now.Add(time.Hour)}
+	tests := []struct {
+		name       string
+		scheduled  []core.CurrencyRate
+		rates      core.CurrencyRates
+		repoErr    error
+		wantEvents []core.CurrencyRate
+		wantLater  bool
+	}{
+		{
+			name:       "scheduled window is announced when it starts",
+			scheduled:  []core.CurrencyRate{{From: "USD", To: "BRL", Rate: 5.1, ValidFrom: started.ValidFrom}},
+			rates:      core.CurrencyRates{started},
+			wantEvents: []core.CurrencyRate{started},
+		},
+		{
+			name:  "window announced as it started is not announced again",
+			rates: core.CurrencyRates{started},
+		},
+		{
+			name:      "window starting later is scheduled",
+			rates:     core.CurrencyRates{later},
+			wantLater: true,
+		},
+		{
+			name:      "removed window is forgotten",
+			scheduled: []core.CurrencyRate{{From: "USD", To: "BRL", Rate: 5.1, ValidFrom: started.ValidFrom}},
+			rates:     core.CurrencyRates{},
+		},
+		{
+			name:    "repository error",
+			repoErr: errors.New("db"),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			since := now.Add(-time.Minute)
+			repo := svcmock.NewMockRepository(ctrl)
+			repo.EXPECT().GetRatesStartingAfter(gomock.Any(), since).Return(tt.rates, tt.repoErr)
+
+			cfg := testConfig
+			cfg.Stream.Buffer = 2
+			svc := NewService(repo, nil, cfg)
+			// windows approved while they started later
+			for _, r := range tt.scheduled {
+				svc.schedule.add(r)
+			}
+			sub := svc.SubscribeRates(nil, 0)
+			err := svc.announceRates(context.Background(), since, now)
+			require.Equal(t, tt.repoErr, err)
+			for _, want := range tt.wantEvents {
+				e := <-sub.Events()
+				require.Equal(t, core.RateActionUpdate, e.Action)
+				require.Equal(t, manualSource, e.Source)
+				require.Equal(t, want, e.Rate)
+			}
+			require.Empty(t, sub.Events())
+			require.Equal(t, tt.wantLater, svc.schedule.take(later))
+			require.False(t, svc.schedule.take(started))
+		})
+	}
+}
+
+func Test_ImportRates(t *testing.T) {
+	t.Parallel()
+	validFrom := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	validTo := validFrom.AddDate(0, 1, 0)
+	rows := []core.RateImportRow{
+		{Line: 2, Rate: core.CurrencyRate{From: "USD", To: "BRL", Rate: 5.1, ValidFrom: validFrom, ValidTo: &validTo}},
+		{Line: 3, Rate: core.CurrencyRate{From: "USD", To: "BRL", Rate: 5.2, ValidFrom: validFrom.AddDate(0, 0, 15)}},
+		{Line: 4, Rate: core.CurrencyRate{From: "USD", To: "XXX", Rate: 1, ValidFrom: validFrom}},
+		{Line: 5, Rate: core.CurrencyRate{From: "USD", To: "BRL"}, Err: core.ErrRateNotANumber},
+		{Line: 6, Rate: core.CurrencyRate{From: "USD", To: "BRL", Rate: 5.3, ValidFrom: validTo}},
+	}
+	statuses := func(r core.ImportReport) (s []core.ImportStatus) {
+		for _, row := range r.Rows {
+			s = append(s, row.Status)
+		}
+		return s
+	}
+	tests := []struct {
+		name         string
+		rows         []core.RateImportRow
+		opts         core.ImportOptions
+		actor        string
+		wantProposed int
+		wantStatuses []core.ImportStatus
+		wantErr      error
+	}{
+		{
+			name:         "strict import with invalid rows proposes nothing",
+			rows:         rows,
+			opts:         core.ImportOptions{Strict: true},
+			actor:        "maker",
+			wantStatuses: []core.ImportStatus{core.ImportValid, core.ImportInvalid, core.ImportInvalid, core.ImportInvalid, core.ImportValid},
+		},
+		{
+			name:         "lenient import proposes the valid rows",
+			rows:         rows,
+			actor:        "maker",
+			wantProposed: 2,
+			wantStatuses: []core.ImportStatus{core.ImportProposed, core.ImportInvalid, core.ImportInvalid, core.ImportInvalid, core.ImportProposed},
+		},
+		{
+			name:         "dry run only reports",
+			rows:         rows,
+			opts:         core.ImportOptions{DryRun: true},
+			actor:        "maker",
+			wantStatuses: []core.ImportStatus{core.ImportValid, core.ImportInvalid, core.ImportInvalid, core.ImportInvalid, core.ImportValid},
+		},
+		{
+			name:    "actor is required",
+			rows:    rows,
+			wantErr: core.ErrActorRequired,
+		},
+		{
+			name:    "too many rows",
+			rows:    make([]core.RateImportRow, 6),
+			actor:   "maker",
+			wantErr: core.ErrImportTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := svcmock.NewMockRepository(ctrl)
+			exchange := svcmock.NewMockExchanger(ctrl)
+			if tt.wantErr == nil {
+				// the market rates are asked once per pair before the transaction
+				market := exchange.EXPECT().Exchange(gomock.Any(), "USD", "BRL", float64(1)).
+					Return(core.ConversionResp{ConvertedAmount: 5}, nil)
+				unknown := exchange.EXPECT().Exchange(gomock.Any(), "USD", "XXX", float64(1)).
+					Return(core.ConversionResp{}, errors.New("unsupported pair"))
+				repo.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx).After(market).After(unknown)
+				repo.EXPECT().GetCurrency(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, symbol string) (core.Currency, error) {
+						if symbol == "XXX" {
+							return core.Currency{}, core.ErrNotFound
+						}
+						return core.Currency{Symbol: symbol}, nil
+					}).AnyTimes()
+				repo.EXPECT().GetOverlappingRates(gomock.Any(), gomock.Any()).Return(core.CurrencyRates{}, nil).AnyTimes()
+			}
+			for i := 0; i < tt.wantProposed; i++ {
+				repo.EXPECT().CreateRateProposal(gomock.Any(), gomock.Any()).Return(core.RateProposal{ID: fmt.Sprint(i)}, nil)
+				repo.EXPECT().CreateRateAudit(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			cfg := testConfig
+			cfg.ImportMaxRows = 5
+			report, err := NewService(repo, exchange, cfg).ImportRates(context.Background(), tt.rows, tt.opts, tt.actor)
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+			require.Equal(t, tt.wantStatuses, statuses(report))
+			require.Equal(t, tt.wantProposed, report.Proposed)
+			require.Equal(t, 2, report.Valid)
+			require.Equal(t, 3, report.Invalid)
+			require.Equal(t, core.ErrRateOverlap.Code, report.Rows[1].Code)
+			require.Equal(t, core.ErrUnknownCurrency.Code, report.Rows[2].Code)
+			require.Equal(t, core.ErrRateNotANumber.Code, report.Rows[3].Code)
+		})
+	}
+}
+
+func Test_AddCurrency(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		created   bool
+		restore   bool
+		restored  bool
+		createErr error
+		wantEvent bool
+		wantErr   error
+	}{
+		{name: "created", created: true, wantEvent: true},
+		{name: "restored", restore: true, restored: true, wantEvent: true},
+		{name: "already exists", restore: true},
+		{name: "db error", createErr: errors.New("db"), wantErr: errors.New("db")},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			want := core.Currency{Symbol: "ABC", Description: "abc", Source: manualSource, Kind: core.CurrencyFiat}
+			repo := svcmock.NewMockRepository(ctrl)
+			repo.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+			repo.EXPECT().CreateCurrency(gomock.Any(), want).Return(tt.created, tt.createErr)
+			if tt.restore {
+				repo.EXPECT().RestoreCurrency(gomock.Any(), want).Return(tt.restored, nil)
+			}
+			if tt.wantEvent {
+				repo.EXPECT().CreateOutboxEvent(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, e core.OutboxEvent) error {
+						require.Equal(t, core.EventCurrencyCreated, e.Type)
+						require.Equal(t, "ABC", e.Key)
+						return nil
+					})
+			}
+
+			err := NewService(repo, nil, testConfig).AddCurrency(context.Background(), "ABC", "abc")
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func Test_RemoveCurrency(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		getErr    error
+		removeErr error
+		wantErr   error
+	}{
+		{name: "removed"},
+		{name: "not found", getErr: core.ErrNotFound, wantErr: core.ErrNotFound},
+		{name: "removed concurrently", removeErr: core.ErrNotFound, wantErr: core.ErrNotFound},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := svcmock.NewMockRepository(ctrl)
+			repo.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+			repo.EXPECT().GetCurrency(gomock.Any(), "ABC").Return(core.Currency{Symbol: "ABC"}, tt.getErr)
+			if tt.getErr == nil {
+				repo.EXPECT().RemoveCurrency(gomock.Any(), "ABC").Return(tt.removeErr)
+			}
+			if tt.wantErr == nil {
+				repo.EXPECT().CreateOutboxEvent(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, e core.OutboxEvent) error {
+						require.Equal(t, core.EventCurrencyDeleted, e.Type)
+						c := core.Currency{}
+						require.NoError(t, json.Unmarshal(e.Data, &c))
+						require.True(t, c.Deleted)
+						return nil
+					})
+			}
+
+			err := NewService(repo, nil, testConfig).RemoveCurrency(context.Background(), "ABC")
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func Test_RevokeAPIKey(t *testing.T) {
+	t.Parallel()
+	id := "9b2f6c1e-6f5a-4d6e-9a51-2f0c8f1d7a3b"
+	tests := []struct {
+		name    string
+		id      string
+		repoErr error
+		wantErr error
+	}{
+		{name: "revoked", id: id},
+		{name: "not found", id: id, repoErr: core.ErrNotFound, wantErr: core.ErrKeyNotFound},
+		{name: "not an id", id: "abc", wantErr: core.ErrKeyNotFound},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := svcmock.NewMockRepository(ctrl)
+			if tt.id == id {
+				repo.EXPECT().RevokeAPIKey(gomock.Any(), id).Return(tt.repoErr)
+			}
+
+			err := NewService(repo, nil, testConfig).RevokeAPIKey(context.Background(), tt.id)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func Test_RotateAPIKey(t *testing.T) {
+	t.Parallel()
+	id := "9b2f6c1e-6f5a-4d6e-9a51-2f0c8f1d7a3b"
+	tests := []struct {
+		name    string
+		id      string
+		repoErr error
+		wantErr error
+	}{
+		{name: "rotated", id: id},
+		{name: "not found", id: id, repoErr: core.ErrNotFound, wantErr: core.ErrKeyNotFound},
+		{name: "not an id", id: "abc", wantErr: core.ErrKeyNotFound},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := svcmock.NewMockRepository(ctrl)
+			if tt.id == id {
+				repo.EXPECT().RotateAPIKey(gomock.Any(), id, gomock.Any(), gomock.Any()).
+					Return(core.APIKey{ID: id}, tt.repoErr)
+			}
+
+			key, secret, err := NewService(repo, nil, testConfig).RotateAPIKey(context.Background(), tt.id)
+			require.Equal(t, tt.wantErr, err)
+			if tt.wantErr == nil {
+				require.Equal(t, id, key.ID)
+				require.NotEmpty(t, secret)
+			}
+		})
+	}
+}
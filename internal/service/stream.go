@@ -0,0 +1,30 @@
+package service
+
+import (
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/stream"
+)
+
+// SubscribeRates streams the rate changes of the given pairs, every
+// pair when empty, resuming after lastEventID when it is set
+func (s Service) SubscribeRates(pairs []string, lastEventID uint64) *stream.Subscription {
+	return s.events.Subscribe(pairs, lastEventID)
+}
+
+func (s Service) UnsubscribeRates(sub *stream.Subscription) {
+	s.events.Unsubscribe(sub)
+}
+
+// publishRate announces a rate change to the stream subscribers,
+// windows starting later are announced again when they take effect
+func (s Service) publishRate(action core.RateAction, source string, rate core.CurrencyRate) {
+	if s.events == nil {
+		return
+	}
+	if s.schedule != nil && action != core.RateActionDelete && rate.ValidFrom.After(time.Now()) {
+		s.schedule.add(rate)
+	}
+	s.events.Publish(core.RateEvent{Action: action, Source: source, Rate: rate})
+}
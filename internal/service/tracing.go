@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+
+	"github.com/arxdsilva/bravo/internal/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startSpan starts the span of a Service method, it is ended with
+// tracing.End so errors are recorded
+func startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "Service."+method)
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/tracing"
+)
+
+// IncrementUsage counts n requests of the client in the current
+// month, returning the amount of requests made in it so far
+func (s Service) IncrementUsage(ctx context.Context, client string, n int64) (count int64, err error) {
+	ctx, span := startSpan(ctx, "IncrementUsage")
+	defer func() { tracing.End(span, err) }()
+	return s.Repo.IncrementUsage(ctx, client, core.UsagePeriod(time.Now()), n)
+}
+
+// GetUsage lists the usage of every client in the month of period
+func (s Service) GetUsage(ctx context.Context, period time.Time) (us core.Usages, err error) {
+	ctx, span := startSpan(ctx, "GetUsage")
+	defer func() { tracing.End(span, err) }()
+	return s.Repo.GetUsage(ctx, core.UsagePeriod(period))
+}
+
+// GetProviderBudget retrieves the remaining calls of the exchange provider
+func (s Service) GetProviderBudget(ctx context.Context) (b core.ProviderBudget, err error) {
+	ctx, span := startSpan(ctx, "GetProviderBudget")
+	defer func() { tracing.End(span, err) }()
+	return s.Exchange.Budget(ctx)
+}
@@ -0,0 +1,263 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/go-pg/pg/v10"
+)
+
+type AlertSubscription struct {
+	UUID            string `pg:"uuid,pk,type:uuid,default:uuid()"`
+	SymbolFrom      string
+	SymbolTo        string
+	Condition       string
+	Threshold       float64
+	Window          time.Duration `pg:",use_zero"`
+	URL             string
+	Secret          string
+	Active          bool      `pg:",use_zero"`
+	CreatedAt       time.Time `pg:"default:now()"`
+	LastTriggeredAt *time.Time
+}
+
+func (a AlertSubscription) toCore() core.AlertSubscription {
+	return core.AlertSubscription{
+		ID:              a.UUID,
+		From:            a.SymbolFrom,
+		To:              a.SymbolTo,
+		Condition:       core.AlertCondition(a.Condition),
+		Threshold:       a.Threshold,
+		Window:          core.Duration(a.Window),
+		URL:             a.URL,
+		Secret:          a.Secret,
+		Active:          a.Active,
+		CreatedAt:       a.CreatedAt,
+		LastTriggeredAt: a.LastTriggeredAt,
+	}
+}
+
+type WebhookDelivery struct {
+	UUID             string `pg:"uuid,pk,type:uuid,default:uuid()"`
+	SubscriptionUUID string `pg:",type:uuid"`
+	Payload          json.RawMessage
+	Status           string
+	Attempts         int       `pg:",use_zero"`
+	ResponseCode     int       `pg:",use_zero"`
+	Error            string    `pg:",use_zero"`
+	CreatedAt        time.Time `pg:"default:now()"`
+	DeliveredAt      *time.Time
+	ClaimedUntil     *time.Time
+}
+
+func (d WebhookDelivery) toCore() core.WebhookDelivery {
+	return core.WebhookDelivery{
+		ID:             d.UUID,
+		SubscriptionID: d.SubscriptionUUID,
+		Payload:        d.Payload,
+		Status:         core.DeliveryStatus(d.Status),
+		Attempts:       d.Attempts,
+		ResponseCode:   d.ResponseCode,
+		Error:          d.Error,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+}
+
+func (db DB) CreateAlertSubscription(ctx context.Context, a core.AlertSubscription) (core.AlertSubscription, error) {
+	m := &AlertSubscription{
+		SymbolFrom: a.From,
+		SymbolTo:   a.To,
+		Condition:  string(a.Condition),
+		Threshold:  a.Threshold,
+		Window:     time.Duration(a.Window),
+		URL:        a.URL,
+		Secret:     a.Secret,
+		Active:     a.Active,
+	}
+	if _, err := db.conn(ctx).Model(m).Context(ctx).Returning("*").Insert(); err != nil {
+		return core.AlertSubscription{}, err
+	}
+	return m.toCore(), nil
+}
+
+func (db DB) GetAlertSubscriptions(ctx context.Context) (core.AlertSubscriptions, error) {
+	var subs []AlertSubscription
+	if err := db.conn(ctx).Model(&subs).Context(ctx).Order("created_at").Select(); err != nil {
+		return nil, err
+	}
+	as := core.AlertSubscriptions{}
+	for _, a := range subs {
+		as = append(as, a.toCore())
+	}
+	return as, nil
+}
+
+// GetPairAlertSubscriptions lists the active subscriptions of a pair
+func (db DB) GetPairAlertSubscriptions(ctx context.Context, from, to string) (core.AlertSubscriptions, error) {
+	var subs []AlertSubscription
+	err := db.conn(ctx).Model(&subs).Context(ctx).
+		Where("symbol_from = ?", from).
+		Where("symbol_to = ?", to).
+		Where("active").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	as := core.AlertSubscriptions{}
+	for _, a := range subs {
+		as = append(as, a.toCore())
+	}
+	return as, nil
+}
+
+func (db DB) GetAlertSubscription(ctx context.Context, id string) (core.AlertSubscription, error) {
+	a := &AlertSubscription{}
+	err := db.conn(ctx).Model(a).Context(ctx).Where("uuid = ?", id).Select()
+	if errors.Is(err, pg.ErrNoRows) {
+		return core.AlertSubscription{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.AlertSubscription{}, err
+	}
+	return a.toCore(), nil
+}
+
+// UpdateAlertSubscription replaces the settings of a subscription,
+// its secret is kept
+func (db DB) UpdateAlertSubscription(ctx context.Context, a core.AlertSubscription) (core.AlertSubscription, error) {
+	m := &AlertSubscription{}
+	res, err := db.conn(ctx).Model(m).Context(ctx).
+		Set("symbol_from = ?", a.From).
+		Set("symbol_to = ?", a.To).
+		Set("condition = ?", a.Condition).
+		Set("threshold = ?", a.Threshold).
+		Set(`"window" = ?`, time.Duration(a.Window)).
+		Set("url = ?", a.URL).
+		Set("active = ?", a.Active).
+		Where("uuid = ?", a.ID).
+		Returning("*").
+		Update()
+	if errors.Is(err, pg.ErrNoRows) {
+		return core.AlertSubscription{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.AlertSubscription{}, err
+	}
+	if res.RowsAffected() == 0 {
+		return core.AlertSubscription{}, core.ErrNotFound
+	}
+	return m.toCore(), nil
+}
+
+// RemoveAlertSubscription deletes a subscription and its delivery log
+func (db DB) RemoveAlertSubscription(ctx context.Context, id string) error {
+	res, err := db.conn(ctx).Model(&AlertSubscription{}).Context(ctx).
+		Where("uuid = ?", id).
+		Delete()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+// TriggerAlertSubscription records that the subscription fired at
+// at, unless it already fired after since. It reports whether the
+// subscription was triggered, so concurrent evaluators fire it once
+func (db DB) TriggerAlertSubscription(ctx context.Context, id string, at, since time.Time) (bool, error) {
+	res, err := db.conn(ctx).Model(&AlertSubscription{}).Context(ctx).
+		Set("last_triggered_at = ?", at).
+		Where("uuid = ?", id).
+		Where("last_triggered_at IS NULL OR last_triggered_at <= ?", since).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+func (db DB) CreateWebhookDelivery(ctx context.Context, d core.WebhookDelivery) (core.WebhookDelivery, error) {
+	m := &WebhookDelivery{
+		SubscriptionUUID: d.SubscriptionID,
+		Payload:          d.Payload,
+		Status:           string(d.Status),
+	}
+	if _, err := db.conn(ctx).Model(m).Context(ctx).Returning("*").Insert(); err != nil {
+		return core.WebhookDelivery{}, err
+	}
+	return m.toCore(), nil
+}
+
+// ClaimWebhookDelivery holds a pending delivery until until for its
+// next attempt. It reports false when the delivery was finished, an
+// attempt was recorded since attempts or another claim did not expire
+func (db DB) ClaimWebhookDelivery(ctx context.Context, id string, attempts int, now, until time.Time) (bool, error) {
+	res, err := db.conn(ctx).Model(&WebhookDelivery{}).Context(ctx).
+		Set("claimed_until = ?", until).
+		Where("uuid = ?", id).
+		Where("status = ?", core.DeliveryPending).
+		Where("attempts = ?", attempts).
+		Where("claimed_until IS NULL OR claimed_until <= ?", now).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+// UpdateWebhookDelivery records the outcome of the last attempt and
+// releases its claim
+func (db DB) UpdateWebhookDelivery(ctx context.Context, d core.WebhookDelivery) error {
+	_, err := db.conn(ctx).Model(&WebhookDelivery{}).Context(ctx).
+		Set("status = ?", d.Status).
+		Set("attempts = ?", d.Attempts).
+		Set("response_code = ?", d.ResponseCode).
+		Set("error = ?", d.Error).
+		Set("delivered_at = ?", d.DeliveredAt).
+		Set("claimed_until = NULL").
+		Where("uuid = ?", d.ID).
+		Update()
+	return err
+}
+
+// GetWebhookDeliveries lists the delivery log of a subscription,
+// newest first
+func (db DB) GetWebhookDeliveries(ctx context.Context, subscriptionID string) (core.WebhookDeliveries, error) {
+	var deliveries []WebhookDelivery
+	err := db.conn(ctx).Model(&deliveries).Context(ctx).
+		Where("subscription_uuid = ?", subscriptionID).
+		Order("created_at DESC").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	return toCoreDeliveries(deliveries), nil
+}
+
+// GetPendingWebhookDeliveries lists the deliveries that were not
+// finished, oldest first
+func (db DB) GetPendingWebhookDeliveries(ctx context.Context) (core.WebhookDeliveries, error) {
+	var deliveries []WebhookDelivery
+	err := db.conn(ctx).Model(&deliveries).Context(ctx).
+		Where("status = ?", core.DeliveryPending).
+		Order("created_at").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	return toCoreDeliveries(deliveries), nil
+}
+
+func toCoreDeliveries(deliveries []WebhookDelivery) core.WebhookDeliveries {
+	ds := core.WebhookDeliveries{}
+	for _, d := range deliveries {
+		ds = append(ds, d.toCore())
+	}
+	return ds
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+)
+
+type Conversion struct {
+	UUID            string `pg:"uuid,pk,type:uuid,default:uuid()"`
+	SymbolFrom      string
+	SymbolTo        string
+	Amount          float64 `pg:",use_zero"`
+	ConvertedAmount float64 `pg:",use_zero"`
+	Source          string
+	At              time.Time
+	CreatedAt       time.Time `pg:"default:now()"`
+}
+
+func (c Conversion) toCore() core.Conversion {
+	return core.Conversion{
+		ID: c.UUID,
+		ConversionEvent: core.ConversionEvent{
+			ConversionResp: core.ConversionResp{
+				From:             c.SymbolFrom,
+				To:               c.SymbolTo,
+				OriginalAmount:   c.Amount,
+				ConvertedAmount:  c.ConvertedAmount,
+				ConversionSource: c.Source,
+			},
+			At: c.At,
+		},
+		CreatedAt: c.CreatedAt,
+	}
+}
+
+// CreateConversions stores a batch of completed conversions with one
+// insert
+func (db DB) CreateConversions(ctx context.Context, cs []core.ConversionEvent) error {
+	if len(cs) == 0 {
+		return nil
+	}
+	ms := make([]Conversion, 0, len(cs))
+	for _, c := range cs {
+		ms = append(ms, Conversion{
+			SymbolFrom:      c.From,
+			SymbolTo:        c.To,
+			Amount:          c.OriginalAmount,
+			ConvertedAmount: c.ConvertedAmount,
+			Source:          c.ConversionSource,
+			At:              c.At,
+		})
+	}
+	_, err := db.conn(ctx).Model(&ms).Context(ctx).Insert()
+	return err
+}
+
+// EachConversion calls fn with the conversions made in [since, until)
+// oldest first while they are read, a zero bound leaves that side open
+func (db DB) EachConversion(ctx context.Context, since, until time.Time, fn func(core.Conversion) error) error {
+	q := db.conn(ctx).Model((*Conversion)(nil)).Context(ctx).
+		Order("created_at", "uuid")
+	if !since.IsZero() {
+		q = q.Where("created_at >= ?", since)
+	}
+	if !until.IsZero() {
+		q = q.Where("created_at < ?", until)
+	}
+	return q.ForEach(func(c *Conversion) error {
+		return fn(c.toCore())
+	})
+}
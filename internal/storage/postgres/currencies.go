@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/go-pg/pg/v10/types"
+)
+
+// GetCurrencies lists a page of currencies using keyset pagination,
+// one extra row is read to know whether there is a next page
+func (db DB) GetCurrencies(ctx context.Context, f core.CurrencyFilter) (core.CurrencyPage, error) {
+	var cs []Currency
+	q := db.conn(ctx).Model(&cs).Context(ctx)
+	if !f.IncludeDeleted {
+		q.Where("deleted = false")
+	}
+	if f.Source != "" {
+		q.Where("source = ?", f.Source)
+	}
+	if f.Kind != "" {
+		q.Where("kind = ?", f.Kind)
+	}
+	if f.Q != "" {
+		like := "%" + escapeLike(f.Q) + "%"
+		q.WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			return q.Where("symbol ILIKE ?", like).WhereOr("description ILIKE ?", like), nil
+		})
+	}
+
+	field, desc := f.SortField()
+	cmp, dir := ">", "ASC"
+	if desc {
+		cmp, dir = "<", "DESC"
+	}
+	if f.Cursor != "" {
+		c, err := core.DecodeCursor(f.Cursor)
+		if err != nil {
+			return core.CurrencyPage{}, core.ErrInvalidCursor
+		}
+		q.Where("(?, symbol) "+cmp+" (?, ?)", types.Ident(field), c.Value, c.Symbol)
+	}
+	q.OrderExpr("? "+dir+", symbol "+dir, types.Ident(field)).Limit(f.Limit + 1)
+
+	if err := q.Select(); err != nil {
+		return core.CurrencyPage{}, err
+	}
+
+	page := core.CurrencyPage{Currencies: core.Currencies{}}
+	for i, c := range cs {
+		if i == f.Limit {
+			last := cs[i-1]
+			page.Next = core.Cursor{Sort: f.Sort, Value: sortValue(last, field), Symbol: last.Symbol}.Encode()
+			break
+		}
+		page.Currencies = append(page.Currencies, c.core())
+	}
+	return page, nil
+}
+
+func sortValue(c Currency, field string) string {
+	if field == "description" {
+		return c.Description
+	}
+	return c.Symbol
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike makes the wildcards of a search text literal
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// UpdateCurrency changes the description of the currency, when
+// version is set the row is only changed if it still has that
+// updated_at so concurrent writes are not lost
+func (db DB) UpdateCurrency(ctx context.Context, symbol, description string, version time.Time) error {
+	q := db.conn(ctx).Model(&Currency{}).Context(ctx).
+		Set("description = ?", description).
+		Set("updated_at = now()").
+		Where("symbol = ?", symbol).
+		Where("deleted = false")
+	if !version.IsZero() {
+		q.Where("updated_at = ?", version)
+	}
+	res, err := q.Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() > 0 {
+		return nil
+	}
+	if !version.IsZero() {
+		return core.ErrPreconditionFailed
+	}
+	return core.ErrNotFound
+}
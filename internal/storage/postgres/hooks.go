@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/metrics"
+	"github.com/arxdsilva/bravo/internal/tracing"
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryHook times and traces every query made through the repository
+type queryHook struct{}
+
+func (queryHook) BeforeQuery(ctx context.Context, evt *pg.QueryEvent) (context.Context, error) {
+	ctx, _ = tracing.Tracer().Start(ctx, "db."+operation(evt),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationKey.String(operation(evt)),
+			semconv.DBSQLTableKey.String(table(evt)),
+		))
+	return ctx, nil
+}
+
+func (queryHook) AfterQuery(ctx context.Context, evt *pg.QueryEvent) error {
+	var err error
+	status := metrics.Success
+	if evt.Err != nil && evt.Err != pg.ErrNoRows {
+		status = metrics.Failure
+		err = evt.Err
+	}
+	metrics.DBQueryDuration.
+		WithLabelValues(operation(evt), table(evt), status).
+		Observe(time.Since(evt.StartTime).Seconds())
+
+	span := trace.SpanFromContext(ctx)
+	if q, qerr := evt.UnformattedQuery(); qerr == nil {
+		span.SetAttributes(semconv.DBStatementKey.String(string(q)))
+	}
+	tracing.End(span, err)
+	return nil
+}
+
+// operation is the first keyword of the query, ex: select
+func operation(evt *pg.QueryEvent) string {
+	q, err := evt.UnformattedQuery()
+	if err != nil {
+		return "unknown"
+	}
+	q = bytes.TrimSpace(q)
+	if i := bytes.IndexAny(q, " \n\t"); i > 0 {
+		q = q[:i]
+	}
+	return strings.ToLower(string(q))
+}
+
+func table(evt *pg.QueryEvent) string {
+	m, ok := evt.Model.(orm.TableModel)
+	if !ok {
+		return ""
+	}
+	return strings.Trim(string(m.Table().SQLName), `"`)
+}
@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+)
+
+type IdempotencyKey struct {
+	Client      string `pg:",pk"`
+	Key         string `pg:",pk"`
+	Method      string
+	Path        string
+	Fingerprint string
+	Status      int `pg:",use_zero"`
+	Header      http.Header
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+func (k IdempotencyKey) core() core.IdempotentRequest {
+	return core.IdempotentRequest{
+		Client:      k.Client,
+		Key:         k.Key,
+		Method:      k.Method,
+		Path:        k.Path,
+		Fingerprint: k.Fingerprint,
+		Status:      k.Status,
+		Header:      k.Header,
+		Body:        k.Body,
+		ExpiresAt:   k.ExpiresAt,
+	}
+}
+
+// ClaimIdempotencyKey stores the request as in flight until its
+// ExpiresAt, expired keys are taken over. When the key is still held by another request
+// that one is returned with claimed false
+func (db DB) ClaimIdempotencyKey(ctx context.Context, r core.IdempotentRequest) (core.IdempotentRequest, bool, error) {
+	k := &IdempotencyKey{
+		Client:      r.Client,
+		Key:         r.Key,
+		Method:      r.Method,
+		Path:        r.Path,
+		Fingerprint: r.Fingerprint,
+		ExpiresAt:   r.ExpiresAt,
+	}
+	res, err := db.conn(ctx).Model(k).Context(ctx).
+		OnConflict("(client, key) DO UPDATE").
+		Set("method = EXCLUDED.method").
+		Set("path = EXCLUDED.path").
+		Set("fingerprint = EXCLUDED.fingerprint").
+		Set("status = 0").
+		Set("header = NULL").
+		Set("body = NULL").
+		Set("created_at = now()").
+		Set("expires_at = EXCLUDED.expires_at").
+		Where("idempotency_key.expires_at <= now()").
+		Insert()
+	if err != nil {
+		return r, false, err
+	}
+	if res.RowsAffected() > 0 {
+		return r, true, nil
+	}
+
+	held := &IdempotencyKey{}
+	err = db.conn(ctx).Model(held).Context(ctx).
+		Where("client = ?", r.Client).
+		Where("key = ?", r.Key).
+		Select()
+	if err != nil {
+		return r, false, err
+	}
+	return held.core(), false, nil
+}
+
+// CompleteIdempotencyKey stores the response replayed to retries
+// until ExpiresAt
+func (db DB) CompleteIdempotencyKey(ctx context.Context, r core.IdempotentRequest) error {
+	_, err := db.conn(ctx).Model(&IdempotencyKey{}).Context(ctx).
+		Set("status = ?", r.Status).
+		Set("header = ?", r.Header).
+		Set("body = ?", r.Body).
+		Set("expires_at = ?", r.ExpiresAt).
+		Where("client = ?", r.Client).
+		Where("key = ?", r.Key).
+		Update()
+	return err
+}
+
+// ReleaseIdempotencyKey drops an in flight key so the request can
+// be retried, completed keys are kept
+func (db DB) ReleaseIdempotencyKey(ctx context.Context, client, key string) error {
+	_, err := db.conn(ctx).Model(&IdempotencyKey{}).Context(ctx).
+		Where("client = ?", client).
+		Where("key = ?", key).
+		Where("status = 0").
+		Delete()
+	return err
+}
+
+// PurgeIdempotencyKeys removes the keys that expired before t
+func (db DB) PurgeIdempotencyKeys(ctx context.Context, t time.Time) (int, error) {
+	res, err := db.conn(ctx).Model(&IdempotencyKey{}).Context(ctx).
+		Where("expires_at <= ?", t).
+		Delete()
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
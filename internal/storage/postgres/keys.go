@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/go-pg/pg/v10"
+)
+
+type APIKey struct {
+	UUID      string `pg:"uuid,pk,type:uuid,default:uuid()"`
+	Name      string
+	Prefix    string
+	Hash      string
+	Scopes    []string  `pg:",array"`
+	CreatedAt time.Time `pg:"default:now()"`
+	RotatedAt *time.Time
+	RevokedAt *time.Time
+}
+
+func (k APIKey) toCore() core.APIKey {
+	scopes := []core.Scope{}
+	for _, s := range k.Scopes {
+		scopes = append(scopes, core.Scope(s))
+	}
+	return core.APIKey{
+		ID:        k.UUID,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		Scopes:    scopes,
+		CreatedAt: k.CreatedAt,
+		RotatedAt: k.RotatedAt,
+		RevokedAt: k.RevokedAt,
+	}
+}
+
+func (db DB) CreateAPIKey(ctx context.Context, key core.APIKey, hash string) (core.APIKey, error) {
+	m := &APIKey{
+		Name:   key.Name,
+		Prefix: key.Prefix,
+		Hash:   hash,
+	}
+	for _, s := range key.Scopes {
+		m.Scopes = append(m.Scopes, string(s))
+	}
+	if _, err := db.conn(ctx).Model(m).Context(ctx).Returning("*").Insert(); err != nil {
+		return core.APIKey{}, err
+	}
+	return m.toCore(), nil
+}
+
+func (db DB) GetAPIKeys(ctx context.Context) (core.APIKeys, error) {
+	var keys []APIKey
+	err := db.conn(ctx).Model(&keys).Context(ctx).Order("created_at").Select()
+	if err != nil {
+		return nil, err
+	}
+	ks := core.APIKeys{}
+	for _, k := range keys {
+		ks = append(ks, k.toCore())
+	}
+	return ks, nil
+}
+
+// GetAPIKeyByHash retrieves an active key from the hash of its secret
+func (db DB) GetAPIKeyByHash(ctx context.Context, hash string) (core.APIKey, error) {
+	k := &APIKey{}
+	err := db.conn(ctx).Model(k).Context(ctx).
+		Where("hash = ?", hash).
+		Where("revoked_at IS NULL").
+		Select()
+	if errors.Is(err, pg.ErrNoRows) {
+		return core.APIKey{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.APIKey{}, err
+	}
+	return k.toCore(), nil
+}
+
+func (db DB) RotateAPIKey(ctx context.Context, id, prefix, hash string) (core.APIKey, error) {
+	k := &APIKey{}
+	res, err := db.conn(ctx).Model(k).Context(ctx).
+		Set("prefix = ?", prefix).
+		Set("hash = ?", hash).
+		Set("rotated_at = now()").
+		Where("uuid = ?", id).
+		Where("revoked_at IS NULL").
+		Returning("*").
+		Update()
+	if err != nil {
+		return core.APIKey{}, err
+	}
+	if res.RowsAffected() == 0 {
+		return core.APIKey{}, core.ErrNotFound
+	}
+	return k.toCore(), nil
+}
+
+func (db DB) RevokeAPIKey(ctx context.Context, id string) error {
+	res, err := db.conn(ctx).Model(&APIKey{}).Context(ctx).
+		Set("revoked_at = now()").
+		Where("uuid = ?", id).
+		Where("revoked_at IS NULL").
+		Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/go-pg/pg/v10"
+)
+
+type OutboxEvent struct {
+	UUID        string `pg:"uuid,pk,type:uuid,default:uuid()"`
+	Seq         int64
+	Type        string
+	Key         string
+	Data        json.RawMessage
+	CreatedAt   time.Time `pg:"default:now()"`
+	PublishedAt *time.Time
+}
+
+func (e OutboxEvent) toCore() core.OutboxEvent {
+	return core.OutboxEvent{
+		ID:         e.UUID,
+		Type:       core.EventType(e.Type),
+		Key:        e.Key,
+		Data:       e.Data,
+		OccurredAt: e.CreatedAt,
+	}
+}
+
+// CreateOutboxEvent stores an event, it has to run in the transaction
+// of the change it announces
+func (db DB) CreateOutboxEvent(ctx context.Context, e core.OutboxEvent) error {
+	m := &OutboxEvent{Type: string(e.Type), Key: e.Key, Data: e.Data}
+	_, err := db.conn(ctx).Model(m).Context(ctx).ExcludeColumn("seq").Insert()
+	return err
+}
+
+// CreateOutboxEvents stores a batch of events with one insert
+func (db DB) CreateOutboxEvents(ctx context.Context, es core.OutboxEvents) error {
+	if len(es) == 0 {
+		return nil
+	}
+	ms := make([]OutboxEvent, 0, len(es))
+	for _, e := range es {
+		ms = append(ms, OutboxEvent{Type: string(e.Type), Key: e.Key, Data: e.Data})
+	}
+	_, err := db.conn(ctx).Model(&ms).Context(ctx).ExcludeColumn("seq").Insert()
+	return err
+}
+
+// ClaimOutboxEvents locks the oldest unpublished events, events
+// locked by another relay are skipped. It has to run in a transaction
+func (db DB) ClaimOutboxEvents(ctx context.Context, limit int) (core.OutboxEvents, error) {
+	var events []OutboxEvent
+	err := db.conn(ctx).Model(&events).Context(ctx).
+		Where("published_at IS NULL").
+		Order("seq").
+		Limit(limit).
+		For("UPDATE SKIP LOCKED").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	es := core.OutboxEvents{}
+	for _, e := range events {
+		es = append(es, e.toCore())
+	}
+	return es, nil
+}
+
+func (db DB) MarkOutboxEventsPublished(ctx context.Context, ids []string, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := db.conn(ctx).Model(&OutboxEvent{}).Context(ctx).
+		Set("published_at = ?", at).
+		Where("uuid IN (?)", pg.In(ids)).
+		Update()
+	return err
+}
+
+// PurgeOutboxEvents removes the events published before t
+func (db DB) PurgeOutboxEvents(ctx context.Context, t time.Time) (int, error) {
+	res, err := db.conn(ctx).Model(&OutboxEvent{}).Context(ctx).
+		Where("published_at <= ?", t).
+		Delete()
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
@@ -0,0 +1,167 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/go-pg/pg/v10"
+)
+
+type RateProposal struct {
+	UUID           string `pg:"uuid,pk,type:uuid,default:uuid()"`
+	Action         string
+	SymbolFrom     string
+	SymbolTo       string
+	Rate           float64
+	ValidFrom      time.Time
+	ValidTo        *time.Time
+	Forced         bool
+	ForceReason    string
+	Status         string
+	ProposedBy     string
+	DecidedBy      string
+	DecisionReason string
+	CreatedAt      time.Time `pg:"default:now()"`
+	DecidedAt      *time.Time
+	RatesVersion   string `pg:",use_zero"`
+}
+
+func (p RateProposal) toCore() core.RateProposal {
+	return core.RateProposal{
+		ID:     p.UUID,
+		Action: core.RateAction(p.Action),
+		Rate: core.CurrencyRate{
+			From:      p.SymbolFrom,
+			To:        p.SymbolTo,
+			Rate:      p.Rate,
+			ValidFrom: p.ValidFrom,
+			ValidTo:   p.ValidTo,
+		},
+		Override: core.Override{
+			Force:  p.Forced,
+			Reason: p.ForceReason,
+		},
+		Status:       core.ProposalStatus(p.Status),
+		ProposedBy:   p.ProposedBy,
+		DecidedBy:    p.DecidedBy,
+		Reason:       p.DecisionReason,
+		CreatedAt:    p.CreatedAt,
+		DecidedAt:    p.DecidedAt,
+		RatesVersion: p.RatesVersion,
+	}
+}
+
+type RateAudit struct {
+	tableName struct{} `pg:"rate_audit"`
+
+	UUID         string `pg:"uuid,type:uuid,default:uuid()"`
+	ProposalUUID string `pg:",type:uuid"`
+	Event        string
+	Actor        string
+	Action       string
+	SymbolFrom   string
+	SymbolTo     string
+	Rate         float64
+	ValidFrom    time.Time
+	ValidTo      *time.Time
+	Forced       bool
+	ForceReason  string
+	Reason       string
+}
+
+func (db DB) CreateRateProposal(ctx context.Context, p core.RateProposal) (core.RateProposal, error) {
+	m := &RateProposal{
+		Action:       string(p.Action),
+		SymbolFrom:   p.Rate.From,
+		SymbolTo:     p.Rate.To,
+		Rate:         p.Rate.Rate,
+		ValidFrom:    p.Rate.ValidFrom,
+		ValidTo:      p.Rate.ValidTo,
+		Forced:       p.Override.Force,
+		ForceReason:  p.Override.Reason,
+		Status:       string(p.Status),
+		ProposedBy:   p.ProposedBy,
+		RatesVersion: p.RatesVersion,
+	}
+	if _, err := db.conn(ctx).Model(m).Context(ctx).Returning("*").Insert(); err != nil {
+		return core.RateProposal{}, err
+	}
+	return m.toCore(), nil
+}
+
+// GetRateProposals lists proposals, filtered by status when not empty
+func (db DB) GetRateProposals(ctx context.Context, status core.ProposalStatus) (core.RateProposals, error) {
+	var proposals []RateProposal
+	q := db.conn(ctx).Model(&proposals).Context(ctx).Order("created_at")
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if err := q.Select(); err != nil {
+		return nil, err
+	}
+	ps := core.RateProposals{}
+	for _, p := range proposals {
+		ps = append(ps, p.toCore())
+	}
+	return ps, nil
+}
+
+// GetRateProposal retrieves a proposal, locking its row when
+// called inside a transaction so it can only be decided once
+func (db DB) GetRateProposal(ctx context.Context, id string) (core.RateProposal, error) {
+	p := &RateProposal{}
+	q := db.conn(ctx).Model(p).Context(ctx).Where("uuid = ?", id)
+	if _, ok := ctx.Value(txKey{}).(*pg.Tx); ok {
+		q = q.For("UPDATE")
+	}
+	err := q.Select()
+	if errors.Is(err, pg.ErrNoRows) {
+		return core.RateProposal{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.RateProposal{}, err
+	}
+	return p.toCore(), nil
+}
+
+// DecideRateProposal stores the decision of a pending proposal and
+// the start of its window, which approvals resolve when it was empty
+func (db DB) DecideRateProposal(ctx context.Context, p core.RateProposal) error {
+	res, err := db.conn(ctx).Model(&RateProposal{}).Context(ctx).
+		Set("valid_from = ?", p.Rate.ValidFrom).
+		Set("status = ?", p.Status).
+		Set("decided_by = ?", p.DecidedBy).
+		Set("decision_reason = ?", p.Reason).
+		Set("decided_at = ?", p.DecidedAt).
+		Where("uuid = ?", p.ID).
+		Where("status = ?", core.ProposalPending).
+		Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return core.ErrProposalDecided
+	}
+	return nil
+}
+
+func (db DB) CreateRateAudit(ctx context.Context, a core.RateAudit) error {
+	m := &RateAudit{
+		ProposalUUID: a.ProposalID,
+		Event:        string(a.Event),
+		Actor:        a.Actor,
+		Action:       string(a.Action),
+		SymbolFrom:   a.Rate.From,
+		SymbolTo:     a.Rate.To,
+		Rate:         a.Rate.Rate,
+		ValidFrom:    a.Rate.ValidFrom,
+		ValidTo:      a.Rate.ValidTo,
+		Forced:       a.Override.Force,
+		ForceReason:  a.Override.Reason,
+		Reason:       a.Reason,
+	}
+	_, err := db.conn(ctx).Model(m).Context(ctx).Insert()
+	return err
+}
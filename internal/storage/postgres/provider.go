@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+type ProviderCall struct {
+	Provider string
+	Period   time.Time
+	Calls    int64
+}
+
+// IncrementProviderCalls atomically books a call to the provider
+func (db DB) IncrementProviderCalls(ctx context.Context, provider string, period time.Time) (int64, error) {
+	pc := &ProviderCall{Provider: provider, Period: period, Calls: 1}
+	_, err := db.conn(ctx).Model(pc).Context(ctx).
+		OnConflict("(provider, period) DO UPDATE").
+		Set("calls = provider_call.calls + 1").
+		Returning("calls").
+		Insert()
+	if err != nil {
+		return 0, err
+	}
+	return pc.Calls, nil
+}
+
+func (db DB) GetProviderCalls(ctx context.Context, provider string, period time.Time) (int64, error) {
+	pc := &ProviderCall{}
+	err := db.conn(ctx).Model(pc).Context(ctx).
+		Where("provider = ?", provider).
+		Where("period = ?", period).
+		Select()
+	if errors.Is(err, pg.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return pc.Calls, nil
+}
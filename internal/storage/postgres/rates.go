@@ -0,0 +1,213 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// pgExclusionViolation is raised by currency_rates_no_overlap
+const pgExclusionViolation = "23P01"
+
+type CurrencyRate struct {
+	UUID       string `pg:"uuid,type:uuid,default:uuid()"`
+	SymbolFrom string
+	SymbolTo   string
+	Rate       float64
+	Source     string
+	Deleted    bool
+	ValidFrom  time.Time
+	ValidTo    *time.Time
+	UpdatedAt  time.Time `pg:",default:now()"`
+}
+
+func (r CurrencyRate) toCore() core.CurrencyRate {
+	return core.CurrencyRate{
+		From:      r.SymbolFrom,
+		To:        r.SymbolTo,
+		Rate:      r.Rate,
+		ValidFrom: r.ValidFrom,
+		ValidTo:   r.ValidTo,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// GetRates lists the rate windows that are effective at the given
+// instant, when at is zero the whole schedule is returned
+func (db DB) GetRates(ctx context.Context, at time.Time) (core.CurrencyRates, error) {
+	var rates []CurrencyRate
+	if err := db.ratesQuery(ctx, &rates, at).Select(); err != nil {
+		return nil, err
+	}
+	crs := core.CurrencyRates{}
+	for _, r := range rates {
+		crs = append(crs, r.toCore())
+	}
+	return crs, nil
+}
+
+// EachRate calls fn with the windows GetRates would list while they
+// are read, so they are not held in memory
+func (db DB) EachRate(ctx context.Context, at time.Time, fn func(core.CurrencyRate) error) error {
+	return db.ratesQuery(ctx, (*CurrencyRate)(nil), at).ForEach(func(r *CurrencyRate) error {
+		return fn(r.toCore())
+	})
+}
+
+func (db DB) ratesQuery(ctx context.Context, model interface{}, at time.Time) *orm.Query {
+	q := db.conn(ctx).Model(model).Context(ctx).
+		Where("deleted = false").
+		Order("symbol_from", "symbol_to", "valid_from")
+	if !at.IsZero() {
+		q = q.Where("valid_from <= ?", at).
+			Where("(valid_to IS NULL OR valid_to > ?)", at)
+	}
+	return q
+}
+
+// GetRateAt retrieves the rate window of a pair that contains at
+func (db DB) GetRateAt(ctx context.Context, from, to string, at time.Time) (core.CurrencyRate, error) {
+	rate := &CurrencyRate{}
+	err := db.conn(ctx).Model(rate).Context(ctx).
+		Where("symbol_from = ?", from).
+		Where("symbol_to = ?", to).
+		Where("deleted = false").
+		Where("valid_from <= ?", at).
+		Where("(valid_to IS NULL OR valid_to > ?)", at).
+		Order("valid_from DESC").
+		Limit(1).
+		Select()
+	if errors.Is(err, pg.ErrNoRows) {
+		return core.CurrencyRate{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.CurrencyRate{}, err
+	}
+	return rate.toCore(), nil
+}
+
+// GetOverlappingRates retrieves the rate windows of the same pair
+// that intersect the window of the given rate
+func (db DB) GetOverlappingRates(ctx context.Context, rate core.CurrencyRate) (core.CurrencyRates, error) {
+	var rates []CurrencyRate
+	err := db.conn(ctx).Model(&rates).Context(ctx).
+		Where("symbol_from = ?", rate.From).
+		Where("symbol_to = ?", rate.To).
+		Where("deleted = false").
+		Where("valid_from < coalesce(?::timestamptz, 'infinity')", rate.ValidTo).
+		Where("coalesce(valid_to, 'infinity') > ?", rate.ValidFrom).
+		Order("valid_from").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	crs := core.CurrencyRates{}
+	for _, r := range rates {
+		crs = append(crs, r.toCore())
+	}
+	return crs, nil
+}
+
+// LockRates blocks the rate changes of other transactions until the
+// transaction of ctx ends, reads are not blocked
+func (db DB) LockRates(ctx context.Context) error {
+	_, err := db.conn(ctx).ExecContext(ctx, "LOCK TABLE public.currency_rates IN EXCLUSIVE MODE")
+	return err
+}
+
+// GetRatesStartingAfter retrieves the rate windows of every pair that
+// start after t, including the ones scheduled ahead
+func (db DB) GetRatesStartingAfter(ctx context.Context, t time.Time) (core.CurrencyRates, error) {
+	var rates []CurrencyRate
+	err := db.conn(ctx).Model(&rates).Context(ctx).
+		Where("deleted = false").
+		Where("valid_from > ?", t).
+		Order("valid_from").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	crs := core.CurrencyRates{}
+	for _, r := range rates {
+		crs = append(crs, r.toCore())
+	}
+	return crs, nil
+}
+
+// CreateRate stores a rate window, the open-ended window of the pair
+// that started before it is closed at its valid_from so rates can be
+// scheduled ahead. It has to run in a transaction
+func (db DB) CreateRate(ctx context.Context, rate core.CurrencyRate, source string) error {
+	_, err := db.conn(ctx).Model(&CurrencyRate{}).Context(ctx).
+		Set("valid_to = ?", rate.ValidFrom).
+		Where("symbol_from = ?", rate.From).
+		Where("symbol_to = ?", rate.To).
+		Where("deleted = false").
+		Where("valid_to IS NULL").
+		Where("valid_from < ?", rate.ValidFrom).
+		Update()
+	if err != nil {
+		return rateErr(err)
+	}
+	r := &CurrencyRate{
+		SymbolFrom: rate.From,
+		SymbolTo:   rate.To,
+		Rate:       rate.Rate,
+		Source:     source,
+		ValidFrom:  rate.ValidFrom,
+		ValidTo:    rate.ValidTo,
+	}
+	_, err = db.conn(ctx).Model(r).Context(ctx).Insert()
+	return rateErr(err)
+}
+
+// UpdateRate changes the rate and the end of the window that
+// starts at rate.ValidFrom
+func (db DB) UpdateRate(ctx context.Context, rate core.CurrencyRate) error {
+	res, err := db.conn(ctx).Model(&CurrencyRate{}).Context(ctx).
+		Set("rate = ?", rate.Rate).
+		Set("valid_to = ?", rate.ValidTo).
+		Where("symbol_from = ?", rate.From).
+		Where("symbol_to = ?", rate.To).
+		Where("valid_from = ?", rate.ValidFrom).
+		Where("deleted = false").
+		Update()
+	if err != nil {
+		return rateErr(err)
+	}
+	if res.RowsAffected() == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+// RemoveRate soft deletes the window that starts at validFrom so
+// it is still available when auditing past conversions
+func (db DB) RemoveRate(ctx context.Context, from, to string, validFrom time.Time) error {
+	res, err := db.conn(ctx).Model(&CurrencyRate{}).Context(ctx).
+		Set("deleted = true").
+		Where("symbol_from = ?", from).
+		Where("symbol_to = ?", to).
+		Where("valid_from = ?", validFrom).
+		Where("deleted = false").
+		Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+func rateErr(err error) error {
+	var pgErr pg.Error
+	if errors.As(err, &pgErr) && pgErr.Field('C') == pgExclusionViolation {
+		return core.ErrRateOverlap
+	}
+	return err
+}
@@ -2,8 +2,12 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/arxdsilva/bravo/internal/core"
 	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
 )
 
 type Config struct {
@@ -29,26 +33,132 @@ func New(ctx context.Context, cfg Config) (DB, error) {
 	if err := db.Ping(ctx); err != nil {
 		return DB{}, err
 	}
+	db.AddQueryHook(queryHook{})
 
 	return DB{DB: db}, nil
 }
 
+func (db DB) Ping(ctx context.Context) error {
+	return db.DB.Ping(ctx)
+}
+
+// MigrationVersion is the latest migration applied to the database
+func (db DB) MigrationVersion(ctx context.Context) (int64, error) {
+	var version int64
+	_, err := db.DB.QueryOneContext(ctx, pg.Scan(&version),
+		`SELECT coalesce(max("version"), 0) FROM schema_migrations`)
+	return version, err
+}
+
+type txKey struct{}
+
+// RunInTx runs fn inside a transaction, repository calls made with
+// the ctx received by fn take part in it
+func (db DB) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*pg.Tx); ok {
+		return fn(ctx)
+	}
+	return db.DB.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn returns the transaction carried by ctx, if any
+func (db DB) conn(ctx context.Context) orm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*pg.Tx); ok {
+		return tx
+	}
+	return db.DB
+}
+
 type Currency struct {
 	Symbol      string
 	Description string
 	Source      string
+	Kind        string
+	Deleted     bool      `pg:",use_zero"`
+	UpdatedAt   time.Time `pg:",default:now()"`
+}
+
+func (c Currency) core() core.Currency {
+	return core.Currency{
+		Symbol:      c.Symbol,
+		Description: c.Description,
+		Source:      c.Source,
+		Kind:        c.Kind,
+		Deleted:     c.Deleted,
+		UpdatedAt:   c.UpdatedAt,
+	}
 }
 
 func (db DB) CountCurrencies(ctx context.Context) (int, error) {
-	result, err := db.DB.ExecContext(ctx, "SELECT COUNT(*) FROM public.currencies")
+	result, err := db.conn(ctx).ExecContext(ctx, "SELECT COUNT(*) FROM public.currencies")
 	if err != nil {
 		return 0, err
 	}
 	return result.RowsAffected(), nil
 }
 
-func (db DB) CreateCurrency(ctx context.Context, symbol, description, source string) error {
-	c := &Currency{Symbol: symbol, Description: description, Source: source}
-	_, err := db.DB.Model(c).Context(ctx).Insert()
-	return err
+// CreateCurrency inserts the currency, existing symbols are kept as
+// is and reported as not created
+func (db DB) CreateCurrency(ctx context.Context, cr core.Currency) (bool, error) {
+	if cr.Kind == "" {
+		cr.Kind = core.CurrencyFiat
+	}
+	c := &Currency{Symbol: cr.Symbol, Description: cr.Description, Source: cr.Source, Kind: cr.Kind}
+	res, err := db.conn(ctx).Model(c).Context(ctx).OnConflict("(symbol) DO NOTHING").Insert()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+// RestoreCurrency undeletes a soft deleted currency with a new
+// description, it reports false when there is no deleted currency
+func (db DB) RestoreCurrency(ctx context.Context, cr core.Currency) (bool, error) {
+	res, err := db.conn(ctx).Model(&Currency{}).Context(ctx).
+		Set("deleted = false").
+		Set("description = ?", cr.Description).
+		Set("source = ?", cr.Source).
+		Set("updated_at = now()").
+		Where("symbol = ?", cr.Symbol).
+		Where("deleted = true").
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+// RemoveCurrency soft deletes the currency so it is still listed
+// with include_deleted
+func (db DB) RemoveCurrency(ctx context.Context, symbol string) error {
+	res, err := db.conn(ctx).Model(&Currency{}).Context(ctx).
+		Set("deleted = true").
+		Set("updated_at = now()").
+		Where("symbol = ?", symbol).
+		Where("deleted = false").
+		Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+func (db DB) GetCurrency(ctx context.Context, symbol string) (core.Currency, error) {
+	c := &Currency{}
+	err := db.conn(ctx).Model(c).Context(ctx).
+		Where("symbol = ?", symbol).
+		Where("deleted = false").
+		Select()
+	if errors.Is(err, pg.ErrNoRows) {
+		return core.Currency{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.Currency{}, err
+	}
+	return c.core(), nil
 }
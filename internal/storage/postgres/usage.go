@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+)
+
+type Usage struct {
+	tableName struct{} `pg:"usage_quotas"`
+
+	Client string
+	Period time.Time
+	Count  int64
+}
+
+// IncrementUsage atomically adds n requests to the monthly counter
+func (db DB) IncrementUsage(ctx context.Context, client string, period time.Time, n int64) (int64, error) {
+	u := &Usage{Client: client, Period: period, Count: n}
+	_, err := db.conn(ctx).Model(u).Context(ctx).
+		OnConflict("(client, period) DO UPDATE").
+		Set("count = usage.count + EXCLUDED.count").
+		Returning("count").
+		Insert()
+	if err != nil {
+		return 0, err
+	}
+	return u.Count, nil
+}
+
+func (db DB) GetUsage(ctx context.Context, period time.Time) (core.Usages, error) {
+	var usages []Usage
+	err := db.conn(ctx).Model(&usages).Context(ctx).
+		Where("period = ?", period).
+		Order("count DESC").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	us := core.Usages{}
+	for _, u := range usages {
+		us = append(us, core.Usage{
+			Client: u.Client,
+			Period: u.Period.Format(core.UsagePeriodLayout),
+			Count:  u.Count,
+		})
+	}
+	return us, nil
+}
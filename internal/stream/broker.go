@@ -0,0 +1,143 @@
+package stream
+
+import (
+	"sync"
+	"time"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/arxdsilva/bravo/internal/metrics"
+)
+
+type Config struct {
+	// Buffer is the amount of events queued per subscriber, a
+	// subscriber with a full queue is dropped
+	Buffer int `envconfig:"APP_STREAM_BUFFER" default:"64" validate:"gt=0"`
+	// History is the amount of events kept to resume a stream
+	// from its Last-Event-ID
+	History int `envconfig:"APP_STREAM_HISTORY" default:"1024" validate:"gte=0"`
+}
+
+// Broker fans rate events out to the subscribers without ever
+// waiting for them, slow subscribers are dropped instead. It lives
+// in the memory of one instance, so are its event ids and history:
+// subscribers only see the events published by their own replica
+type Broker struct {
+	cfg Config
+
+	mu      sync.Mutex
+	seq     uint64
+	history []core.RateEvent
+	subs    map[*Subscription]struct{}
+}
+
+func NewBroker(cfg Config) *Broker {
+	return &Broker{cfg: cfg, subs: map[*Subscription]struct{}{}}
+}
+
+// Subscription receives the events of its pairs, every pair when
+// none were given. Backlog has the events published after the
+// Last-Event-ID of the subscriber and Missed is set when some of
+// them are no longer kept
+type Subscription struct {
+	Backlog []core.RateEvent
+	Missed  bool
+
+	pairs   map[string]bool
+	events  chan core.RateEvent
+	dropped bool
+}
+
+// Events is closed when the subscriber is dropped or unsubscribed
+func (s *Subscription) Events() <-chan core.RateEvent {
+	return s.events
+}
+
+// Dropped reports whether the subscriber could not keep up, it is
+// only meaningful after Events is closed
+func (s *Subscription) Dropped() bool {
+	return s.dropped
+}
+
+func (s *Subscription) wants(e core.RateEvent) bool {
+	return len(s.pairs) == 0 || s.pairs[e.Pair()]
+}
+
+// Publish numbers the event and delivers it to the subscribers
+func (b *Broker) Publish(e core.RateEvent) core.RateEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	e.ID = b.seq
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	if b.cfg.History > 0 {
+		if len(b.history) == b.cfg.History {
+			b.history = b.history[1:]
+		}
+		b.history = append(b.history, e)
+	}
+	metrics.StreamEvents.WithLabelValues(e.Source).Inc()
+
+	for s := range b.subs {
+		if !s.wants(e) {
+			continue
+		}
+		select {
+		case s.events <- e:
+		default:
+			s.dropped = true
+			b.remove(s)
+			metrics.StreamDropped.Inc()
+		}
+	}
+	return e
+}
+
+// Subscribe registers a subscriber of the given pairs, lastID is the
+// last event it has seen and 0 when it is a new subscriber
+func (b *Broker) Subscribe(pairs []string, lastID uint64) *Subscription {
+	s := &Subscription{
+		pairs:  map[string]bool{},
+		events: make(chan core.RateEvent, b.cfg.Buffer),
+	}
+	for _, p := range pairs {
+		s.pairs[p] = true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if lastID > 0 {
+		oldest := b.seq + 1
+		if len(b.history) > 0 {
+			oldest = b.history[0].ID
+		}
+		// ids restart with the process, a newer id is from a previous one
+		s.Missed = lastID > b.seq || lastID+1 < oldest
+		for _, e := range b.history {
+			if e.ID > lastID && s.wants(e) {
+				s.Backlog = append(s.Backlog, e)
+			}
+		}
+	}
+	b.subs[s] = struct{}{}
+	metrics.StreamSubscribers.Inc()
+	return s
+}
+
+// Unsubscribe removes the subscriber, it is safe to call after the
+// subscriber was dropped
+func (b *Broker) Unsubscribe(s *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.remove(s)
+}
+
+func (b *Broker) remove(s *Subscription) {
+	if _, ok := b.subs[s]; !ok {
+		return
+	}
+	delete(b.subs, s)
+	close(s.events)
+	metrics.StreamSubscribers.Dec()
+}
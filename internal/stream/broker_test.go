@@ -0,0 +1,93 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/arxdsilva/bravo/internal/core"
+	"github.com/stretchr/testify/require"
+)
+
+func rateEvent(from, to string, rate float64) core.RateEvent {
+	return core.RateEvent{
+		Action: core.RateActionUpdate,
+		Source: "manual",
+		Rate:   core.CurrencyRate{From: from, To: to, Rate: rate},
+	}
+}
+
+func TestBroker_pairs(t *testing.T) {
+	t.Parallel()
+	b := NewBroker(Config{Buffer: 4, History: 4})
+	all := b.Subscribe(nil, 0)
+	brl := b.Subscribe([]string{"USD/BRL"}, 0)
+
+	b.Publish(rateEvent("USD", "BRL", 5))
+	b.Publish(rateEvent("BTC", "USD", 20000))
+
+	require.Len(t, all.Events(), 2)
+	require.Len(t, brl.Events(), 1)
+	e := <-brl.Events()
+	require.Equal(t, uint64(1), e.ID)
+	require.Equal(t, "USD/BRL", e.Pair())
+	require.False(t, e.At.IsZero())
+}
+
+func TestBroker_resume(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		lastID      uint64
+		wantBacklog []uint64
+		wantMissed  bool
+	}{
+		{name: "new subscriber", lastID: 0},
+		{name: "up to date", lastID: 5},
+		{name: "behind", lastID: 3, wantBacklog: []uint64{4, 5}},
+		{name: "last kept", lastID: 2, wantBacklog: []uint64{3, 4, 5}},
+		{name: "too far behind", lastID: 1, wantBacklog: []uint64{3, 4, 5}, wantMissed: true},
+		{name: "previous process", lastID: 10, wantMissed: true},
+	}
+	b := NewBroker(Config{Buffer: 4, History: 3})
+	for i := 0; i < 5; i++ {
+		b.Publish(rateEvent("USD", "BRL", float64(i)))
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := b.Subscribe([]string{"USD/BRL"}, tt.lastID)
+			defer b.Unsubscribe(s)
+			var ids []uint64
+			for _, e := range s.Backlog {
+				ids = append(ids, e.ID)
+			}
+			require.Equal(t, tt.wantBacklog, ids)
+			require.Equal(t, tt.wantMissed, s.Missed)
+		})
+	}
+}
+
+func TestBroker_dropsSlowSubscribers(t *testing.T) {
+	t.Parallel()
+	b := NewBroker(Config{Buffer: 1})
+	slow := b.Subscribe(nil, 0)
+	fast := b.Subscribe(nil, 0)
+
+	b.Publish(rateEvent("USD", "BRL", 5))
+	<-fast.Events()
+	b.Publish(rateEvent("USD", "BRL", 5.1))
+
+	_, ok := <-slow.Events()
+	require.True(t, ok)
+	_, ok = <-slow.Events()
+	require.False(t, ok, "slow subscriber should be dropped")
+	require.True(t, slow.Dropped())
+
+	e := <-fast.Events()
+	require.Equal(t, uint64(2), e.ID)
+	b.Unsubscribe(slow)
+	b.Unsubscribe(fast)
+	_, ok = <-fast.Events()
+	require.False(t, ok)
+	require.False(t, fast.Dropped())
+}
@@ -0,0 +1,101 @@
+// Package tracing sets up the OpenTelemetry tracer provider and the
+// W3C trace context propagation used across the service
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// instrumentation names the tracer of every package of the service
+const instrumentation = "github.com/arxdsilva/bravo"
+
+type Config struct {
+	Exporter    string `envconfig:"APP_TRACE_EXPORTER" default:"none" validate:"oneof=none stdout otlp"`
+	ServiceName string `envconfig:"APP_TRACE_SERVICE_NAME" default:"bravo"`
+	// OTLPEndpoint is the host:port of the collector, it defaults to
+	// the OTEL_EXPORTER_OTLP_ENDPOINT env var when empty
+	OTLPEndpoint string `envconfig:"APP_TRACE_OTLP_ENDPOINT"`
+	OTLPInsecure bool   `envconfig:"APP_TRACE_OTLP_INSECURE" default:"false"`
+	// SampleRatio is the fraction of new traces sampled, traces started
+	// by a caller follow its decision
+	SampleRatio float64 `envconfig:"APP_TRACE_SAMPLE_RATIO" default:"1" validate:"gte=0,lte=1"`
+}
+
+// Setup registers the global tracer provider and propagator, the
+// returned func flushes pending spans and must be called on shutdown
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	var exp sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case ExporterStdout:
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{}
+		if cfg.OTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.OTLPEndpoint))
+		}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exp, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return func(context.Context) error { return nil }, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create %v exporter: %w", cfg.Exporter, err)
+	}
+
+	tp := NewProvider(exp, cfg)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// NewProvider creates a tracer provider batching spans to exp, tests
+// may use it with an in-memory exporter
+func NewProvider(exp sdktrace.SpanExporter, cfg Config) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(cfg.ServiceName),
+		)),
+	)
+}
+
+// Tracer returns the tracer of the service from the global provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// End records err in the span, if any, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS currency_rates_validity_idx;
+
+ALTER TABLE public.currency_rates
+    DROP CONSTRAINT IF EXISTS currency_rates_no_overlap,
+    DROP CONSTRAINT IF EXISTS currency_rates_valid_window,
+    DROP COLUMN IF EXISTS valid_to,
+    DROP COLUMN IF EXISTS valid_from;
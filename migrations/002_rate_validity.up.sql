@@ -0,0 +1,62 @@
+SET statement_timeout = 60000; -- 60 seconds
+SET lock_timeout = 30000; -- 30 seconds
+
+--gopg:split
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+--gopg:split
+-- tables use updated_at, the previous version referenced a missing column
+CREATE OR REPLACE FUNCTION update_datetime()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = now();
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+--gopg:split
+ALTER TABLE public.currency_rates
+    ADD COLUMN IF NOT EXISTS valid_from timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS valid_to timestamptz;
+
+-- existing rates were valid since they were created, not since this migration
+UPDATE public.currency_rates SET valid_from = created_at;
+
+-- a pair may have several live rates created at the same time, only the
+-- last updated one is kept
+WITH ordered AS (
+    SELECT uuid, lead(valid_from) OVER (
+        PARTITION BY symbol_from, symbol_to ORDER BY valid_from, updated_at, uuid
+    ) AS next_from
+    FROM public.currency_rates
+    WHERE NOT deleted
+)
+UPDATE public.currency_rates r SET deleted = true
+FROM ordered o
+WHERE r.uuid = o.uuid AND o.next_from = r.valid_from;
+
+-- older live rates of a pair are closed when the next one was created
+WITH ordered AS (
+    SELECT uuid, lead(valid_from) OVER (
+        PARTITION BY symbol_from, symbol_to ORDER BY valid_from
+    ) AS next_from
+    FROM public.currency_rates
+    WHERE NOT deleted
+)
+UPDATE public.currency_rates r SET valid_to = o.next_from
+FROM ordered o
+WHERE r.uuid = o.uuid AND o.next_from IS NOT NULL;
+
+ALTER TABLE public.currency_rates
+    ADD CONSTRAINT currency_rates_valid_window
+        CHECK (valid_to IS NULL OR valid_to > valid_from);
+
+ALTER TABLE public.currency_rates
+    ADD CONSTRAINT currency_rates_no_overlap
+        EXCLUDE USING gist (
+            symbol_from WITH =,
+            symbol_to WITH =,
+            tstzrange(valid_from, valid_to) WITH &&
+        ) WHERE (NOT deleted);
+
+CREATE INDEX IF NOT EXISTS currency_rates_validity_idx ON public.currency_rates USING btree (symbol_from,symbol_to,valid_from);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.rate_audit;
+DROP TABLE IF EXISTS public.rate_proposals;
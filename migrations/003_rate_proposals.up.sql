@@ -0,0 +1,47 @@
+SET statement_timeout = 60000; -- 60 seconds
+SET lock_timeout = 30000; -- 30 seconds
+
+--gopg:split
+CREATE TABLE IF NOT EXISTS public.rate_proposals (
+    uuid uuid NOT NULL DEFAULT uuid(),
+    "action" text NOT NULL,
+    symbol_from text NOT NULL,
+    symbol_to text NOT NULL,
+    rate numeric NOT NULL,
+    valid_from timestamptz NOT NULL,
+    valid_to timestamptz,
+    status text NOT NULL DEFAULT 'pending',
+    proposed_by text NOT NULL,
+    decided_by text NOT NULL DEFAULT '',
+    decision_reason text NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    decided_at timestamptz,
+    CONSTRAINT rate_proposals_pkey PRIMARY KEY (uuid),
+    CONSTRAINT rate_proposals_action CHECK ("action" IN ('create', 'update', 'delete')),
+    CONSTRAINT rate_proposals_status CHECK (status IN ('pending', 'approved', 'rejected')),
+    CONSTRAINT rate_proposals_checker CHECK (decided_by <> proposed_by)
+);
+
+CREATE INDEX IF NOT EXISTS rate_proposals_status_idx ON public.rate_proposals USING btree (status,created_at);
+
+--gopg:split
+CREATE TABLE IF NOT EXISTS public.rate_audit (
+    uuid uuid NOT NULL DEFAULT uuid(),
+    proposal_uuid uuid NOT NULL,
+    event text NOT NULL,
+    actor text NOT NULL,
+    "action" text NOT NULL,
+    symbol_from text NOT NULL,
+    symbol_to text NOT NULL,
+    rate numeric NOT NULL,
+    valid_from timestamptz NOT NULL,
+    valid_to timestamptz,
+    reason text NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT rate_audit_pkey PRIMARY KEY (uuid),
+    CONSTRAINT fk_rate_audit_proposal
+        FOREIGN KEY (proposal_uuid)
+            REFERENCES rate_proposals(uuid)
+);
+
+CREATE INDEX IF NOT EXISTS rate_audit_proposal_idx ON public.rate_audit USING btree (proposal_uuid);
@@ -0,0 +1,8 @@
+ALTER TABLE public.rate_audit
+    DROP COLUMN IF EXISTS force_reason,
+    DROP COLUMN IF EXISTS forced;
+
+ALTER TABLE public.rate_proposals
+    DROP CONSTRAINT IF EXISTS rate_proposals_force_reason,
+    DROP COLUMN IF EXISTS force_reason,
+    DROP COLUMN IF EXISTS forced;
@@ -0,0 +1,16 @@
+SET statement_timeout = 60000; -- 60 seconds
+SET lock_timeout = 30000; -- 30 seconds
+
+--gopg:split
+ALTER TABLE public.rate_proposals
+    ADD COLUMN IF NOT EXISTS forced boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS force_reason text NOT NULL DEFAULT '';
+
+ALTER TABLE public.rate_proposals
+    ADD CONSTRAINT rate_proposals_force_reason
+        CHECK (NOT forced OR force_reason <> '');
+
+--gopg:split
+ALTER TABLE public.rate_audit
+    ADD COLUMN IF NOT EXISTS forced boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS force_reason text NOT NULL DEFAULT '';
@@ -0,0 +1,17 @@
+SET statement_timeout = 60000; -- 60 seconds
+SET lock_timeout = 30000; -- 30 seconds
+
+--gopg:split
+CREATE TABLE IF NOT EXISTS public.api_keys (
+    uuid uuid NOT NULL DEFAULT uuid(),
+    "name" text NOT NULL,
+    prefix text NOT NULL,
+    hash text NOT NULL,
+    scopes text[] NOT NULL DEFAULT '{}',
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    rotated_at timestamptz,
+    revoked_at timestamptz,
+    CONSTRAINT api_keys_pkey PRIMARY KEY (uuid)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS api_keys_hash_idx ON public.api_keys USING btree (hash);
@@ -0,0 +1,15 @@
+SET statement_timeout = 60000; -- 60 seconds
+SET lock_timeout = 30000; -- 30 seconds
+
+--gopg:split
+CREATE TABLE IF NOT EXISTS public.usage_quotas (
+    client text NOT NULL,
+    period date NOT NULL,
+    count bigint NOT NULL DEFAULT 0,
+    updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT usage_quotas_pkey PRIMARY KEY (client, period)
+);
+
+CREATE TRIGGER update_usage_quotas
+BEFORE UPDATE ON usage_quotas
+FOR EACH ROW EXECUTE PROCEDURE update_datetime();
@@ -0,0 +1,15 @@
+SET statement_timeout = 60000; -- 60 seconds
+SET lock_timeout = 30000; -- 30 seconds
+
+--gopg:split
+CREATE TABLE IF NOT EXISTS public.provider_calls (
+    provider text NOT NULL,
+    period date NOT NULL,
+    calls bigint NOT NULL DEFAULT 0,
+    updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT provider_calls_pkey PRIMARY KEY (provider, period)
+);
+
+CREATE TRIGGER update_provider_calls
+BEFORE UPDATE ON provider_calls
+FOR EACH ROW EXECUTE PROCEDURE update_datetime();
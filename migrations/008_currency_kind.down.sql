@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS public.currencies_kind_idx;
+DROP INDEX IF EXISTS public.currencies_description_idx;
+ALTER TABLE public.currencies DROP CONSTRAINT IF EXISTS currencies_kind_check;
+ALTER TABLE public.currencies DROP COLUMN IF EXISTS kind;
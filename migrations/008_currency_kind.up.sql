@@ -0,0 +1,16 @@
+SET statement_timeout = 60000; -- 60 seconds
+SET lock_timeout = 30000; -- 30 seconds
+
+--gopg:split
+ALTER TABLE public.currencies
+    ADD COLUMN IF NOT EXISTS kind text NOT NULL DEFAULT 'fiat';
+
+--gopg:split
+ALTER TABLE public.currencies
+    ADD CONSTRAINT currencies_kind_check CHECK (kind IN ('fiat', 'crypto'));
+
+--gopg:split
+CREATE INDEX IF NOT EXISTS currencies_description_idx ON public.currencies USING btree (description, symbol);
+
+--gopg:split
+CREATE INDEX IF NOT EXISTS currencies_kind_idx ON public.currencies USING btree (kind, symbol);
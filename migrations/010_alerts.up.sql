@@ -0,0 +1,38 @@
+SET statement_timeout = 60000; -- 60 seconds
+SET lock_timeout = 30000; -- 30 seconds
+
+--gopg:split
+CREATE TABLE IF NOT EXISTS public.alert_subscriptions (
+    uuid uuid NOT NULL DEFAULT uuid(),
+    symbol_from text NOT NULL,
+    symbol_to text NOT NULL,
+    "condition" text NOT NULL,
+    threshold double precision NOT NULL,
+    "window" bigint NOT NULL DEFAULT 0,
+    url text NOT NULL,
+    secret text NOT NULL,
+    active boolean NOT NULL DEFAULT true,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_triggered_at timestamptz,
+    CONSTRAINT alert_subscriptions_pkey PRIMARY KEY (uuid),
+    CONSTRAINT alert_subscriptions_condition CHECK ("condition" IN ('above', 'below', 'change'))
+);
+
+CREATE INDEX IF NOT EXISTS alert_subscriptions_pair_idx ON public.alert_subscriptions USING btree (symbol_from, symbol_to) WHERE active;
+
+--gopg:split
+CREATE TABLE IF NOT EXISTS public.webhook_deliveries (
+    uuid uuid NOT NULL DEFAULT uuid(),
+    subscription_uuid uuid NOT NULL REFERENCES public.alert_subscriptions (uuid) ON DELETE CASCADE,
+    payload jsonb NOT NULL,
+    status text NOT NULL DEFAULT 'pending',
+    attempts integer NOT NULL DEFAULT 0,
+    response_code integer NOT NULL DEFAULT 0,
+    error text NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at timestamptz,
+    CONSTRAINT webhook_deliveries_pkey PRIMARY KEY (uuid)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_subscription_idx ON public.webhook_deliveries USING btree (subscription_uuid, created_at);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON public.webhook_deliveries USING btree (created_at) WHERE status = 'pending';
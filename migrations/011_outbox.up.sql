@@ -0,0 +1,18 @@
+SET statement_timeout = 60000; -- 60 seconds
+SET lock_timeout = 30000; -- 30 seconds
+
+--gopg:split
+CREATE TABLE IF NOT EXISTS public.outbox_events (
+    uuid uuid NOT NULL DEFAULT uuid(),
+    seq bigserial NOT NULL,
+    "type" text NOT NULL,
+    "key" text NOT NULL,
+    data jsonb NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at timestamptz,
+    CONSTRAINT outbox_events_pkey PRIMARY KEY (uuid)
+);
+
+--gopg:split
+CREATE INDEX IF NOT EXISTS outbox_events_unpublished_idx ON public.outbox_events USING btree (seq) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_events_published_at_idx ON public.outbox_events USING btree (published_at) WHERE published_at IS NOT NULL;
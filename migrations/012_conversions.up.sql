@@ -0,0 +1,18 @@
+SET statement_timeout = 60000; -- 60 seconds
+SET lock_timeout = 30000; -- 30 seconds
+
+--gopg:split
+CREATE TABLE IF NOT EXISTS public.conversions (
+    uuid uuid NOT NULL DEFAULT uuid(),
+    symbol_from text NOT NULL,
+    symbol_to text NOT NULL,
+    amount numeric NOT NULL,
+    converted_amount numeric NOT NULL,
+    source text NOT NULL,
+    "at" timestamptz NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT conversions_pkey PRIMARY KEY (uuid)
+);
+
+--gopg:split
+CREATE INDEX IF NOT EXISTS conversions_created_at_idx ON public.conversions USING btree (created_at);
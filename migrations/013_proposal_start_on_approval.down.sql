@@ -0,0 +1,5 @@
+UPDATE public.rate_audit SET valid_from = created_at WHERE valid_from IS NULL;
+ALTER TABLE public.rate_audit ALTER COLUMN valid_from SET NOT NULL;
+
+UPDATE public.rate_proposals SET valid_from = created_at WHERE valid_from IS NULL;
+ALTER TABLE public.rate_proposals ALTER COLUMN valid_from SET NOT NULL;
@@ -0,0 +1,9 @@
+SET statement_timeout = 60000; -- 60 seconds
+SET lock_timeout = 30000; -- 30 seconds
+
+-- create proposals without valid_from start when they are approved
+--gopg:split
+ALTER TABLE public.rate_proposals ALTER COLUMN valid_from DROP NOT NULL;
+
+--gopg:split
+ALTER TABLE public.rate_audit ALTER COLUMN valid_from DROP NOT NULL;
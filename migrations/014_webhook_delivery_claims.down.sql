@@ -0,0 +1 @@
+ALTER TABLE public.webhook_deliveries DROP COLUMN IF EXISTS claimed_until;
@@ -0,0 +1,6 @@
+SET statement_timeout = 60000; -- 60 seconds
+SET lock_timeout = 30000; -- 30 seconds
+
+-- the replica posting a delivery attempt holds it until claimed_until
+--gopg:split
+ALTER TABLE public.webhook_deliveries ADD COLUMN IF NOT EXISTS claimed_until timestamptz;
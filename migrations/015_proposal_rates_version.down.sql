@@ -0,0 +1 @@
+ALTER TABLE public.rate_proposals DROP COLUMN IF EXISTS rates_version;
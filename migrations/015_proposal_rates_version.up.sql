@@ -0,0 +1,6 @@
+SET statement_timeout = 60000; -- 60 seconds
+SET lock_timeout = 30000; -- 30 seconds
+
+-- the schedule matched by If-Match when the change was proposed
+--gopg:split
+ALTER TABLE public.rate_proposals ADD COLUMN IF NOT EXISTS rates_version text NOT NULL DEFAULT '';